@@ -2,4 +2,7 @@ module github.com/sungup/go-nvmecli
 
 go 1.15
 
-require github.com/stretchr/testify v1.7.0
+require (
+	github.com/stretchr/testify v1.7.0
+	gopkg.in/yaml.v3 v3.0.1
+)
@@ -1,7 +1,6 @@
 package ioctl
 
 import (
-	"fmt"
 	"os"
 	"syscall"
 )
@@ -53,11 +52,11 @@ func iocType(nr uint64) uint64 { return nr >> TypeShift & typeMask }
 func iocNr(nr uint64) uint64   { return nr >> NrShift & nrMask }
 func iocSize(nr uint64) uint64 { return nr >> SizeShift & sizeMask }
 
-// submit ioctl command
-func Submit(f *os.File, request, data uintptr) error {
-	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, f.Fd(), request, data); errno != 0 {
-		return os.NewSyscallError("ioctl", fmt.Errorf("%d", errno))
+// submit ioctl command and returns the positive return value of the ioctl system call.
+func Submit(f *os.File, request, data uintptr) (uintptr, error) {
+	if ret, _, errno := syscall.Syscall(syscall.SYS_IOCTL, f.Fd(), request, data); errno != 0 {
+		return 0, os.NewSyscallError("ioctl", errno)
+	} else {
+		return ret, nil
 	}
-
-	return nil
 }
@@ -0,0 +1,65 @@
+package nvme
+
+import (
+	"fmt"
+	"github.com/sungup/go-nvmecli/pkg/nvme/spec"
+)
+
+// Status Code Type values of the completion queue entry's status field.
+const (
+	SCTGeneric         = uint8(0x0)
+	SCTCommandSpecific = uint8(0x1)
+	SCTMediaError      = uint8(0x2)
+	SCTPathRelated     = uint8(0x3)
+	SCTVendorSpecific  = uint8(0x7)
+)
+
+// CompletionError is an error for the command completed with the non-zero status field. Linux
+// kernel returns the status field of the completion queue entry without the phase tag, so Status
+// has SC in bit[07:00], SCT in bit[10:08], CRD in bit[12:11], M in bit[13] and DNR in bit[14].
+type CompletionError struct {
+	OpCode opcode
+	Status uint16
+}
+
+// SC returns the Status Code of the completion.
+func (e *CompletionError) SC() uint8 {
+	return uint8(e.Status)
+}
+
+// SCT returns the Status Code Type of the completion.
+func (e *CompletionError) SCT() uint8 {
+	return uint8(e.Status>>8) & 0b111
+}
+
+// CRD returns the Command Retry Delay index of the completion.
+func (e *CompletionError) CRD() uint8 {
+	return uint8(e.Status>>11) & 0b11
+}
+
+// More returns true if there are more status information in the Error Information log page.
+func (e *CompletionError) More() bool {
+	return e.Status&(1<<13) != 0
+}
+
+// DNR returns true if the same command is expected to fail when it is re-submitted.
+func (e *CompletionError) DNR() bool {
+	return e.Status&(1<<14) != 0
+}
+
+// HasStatus returns true if the completion has the status code type and the status code.
+func (e *CompletionError) HasStatus(sct, sc uint8) bool {
+	return e.SCT() == sct && e.SC() == sc
+}
+
+// Lookup returns the named status of the completion.
+func (e *CompletionError) Lookup() spec.Status {
+	status, _ := spec.LookupStatus(e.SCT(), e.SC())
+	return status
+}
+
+// Error returns the string message about the command and the completion status like "Get Log Page:
+// Invalid Log Page (SCT 1h/SC 09h)".
+func (e *CompletionError) Error() string {
+	return fmt.Sprintf("%v: %v", e.OpCode, e.Lookup())
+}
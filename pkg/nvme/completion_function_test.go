@@ -0,0 +1,52 @@
+package nvme
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestCompletionError_Fields(t *testing.T) {
+	a := assert.New(t)
+
+	// DNR: 1, M: 0, CRD: 2, SCT: 1h, SC: 0Ah (Invalid Format)
+	tested := CompletionError{OpCode: AdminFormatNVM, Status: 1<<14 | 2<<11 | 1<<8 | 0x0A}
+
+	a.Equal(uint8(0x0A), tested.SC())
+	a.Equal(SCTCommandSpecific, tested.SCT())
+	a.Equal(uint8(2), tested.CRD())
+	a.False(tested.More())
+	a.True(tested.DNR())
+	a.True(tested.HasStatus(SCTCommandSpecific, 0x0A))
+	a.False(tested.HasStatus(SCTGeneric, 0x0A))
+
+	tested.Status = 1<<13 | 2<<8 | 0x81
+	a.Equal(uint8(0x81), tested.SC())
+	a.Equal(SCTMediaError, tested.SCT())
+	a.Zero(tested.CRD())
+	a.True(tested.More())
+	a.False(tested.DNR())
+}
+
+func TestCompletionError_Error(t *testing.T) {
+	a := assert.New(t)
+
+	tested := CompletionError{OpCode: AdminGetLogPage, Status: 0x0002}
+
+	a.Equal("Get Log Page: Invalid Field in Command (SCT 0h/SC 02h)", tested.Error())
+
+	tested = CompletionError{OpCode: AdminFormatNVM, Status: 1<<14 | 1<<8 | 0x0A}
+	a.Equal("Format NVM: Invalid Format (SCT 1h/SC 0Ah)", tested.Error())
+
+	tested = CompletionError{OpCode: opcode(0xC1), Status: 7<<8 | 0x01}
+	a.Equal("Vendor Specific (C1h): Vendor Specific (SCT 7h/SC 01h)", tested.Error())
+}
+
+func TestCompletionError_Lookup(t *testing.T) {
+	a := assert.New(t)
+
+	tested := CompletionError{OpCode: AdminGetLogPage, Status: 1<<8 | 0x09}
+
+	status := tested.Lookup()
+	a.Equal("Invalid Log Page", status.Name)
+	a.NotEmpty(status.Description)
+}
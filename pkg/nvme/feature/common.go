@@ -1,7 +1,10 @@
 package feature
 
 import (
+	"fmt"
 	"github.com/sungup/go-nvmecli/pkg/nvme"
+	"github.com/sungup/go-nvmecli/pkg/nvme/identify"
+	"github.com/sungup/go-nvmecli/pkg/nvme/spec"
 	"os"
 )
 
@@ -13,32 +16,42 @@ const (
 	SELSaved      = sel(0x02) << 8
 	SELSupportCap = sel(0x03) << 8
 
-	FIDArbitration                 = uint8(0x001)
-	FIDPowerManagement             = uint8(0x002)
-	FIDLBARangeType                = uint8(0x003)
-	FIDTemperatureThreshold        = uint8(0x004)
-	FIDErrorRecovery               = uint8(0x005)
-	FIDVolatileWriteCache          = uint8(0x006)
-	FIDNumberOfQueues              = uint8(0x007)
-	FIDInterruptCoalescing         = uint8(0x008)
-	FIDInterruptVectorConf         = uint8(0x009)
-	FIDWriteAtomicityNormal        = uint8(0x00a)
-	FIDAsyncEventConf              = uint8(0x00b)
-	FIDAutoPowerStateTransition    = uint8(0x00c)
-	FIDHostMemoryBuffer            = uint8(0x00d)
-	FIDTimeStamp                   = uint8(0x00e)
-	FIDKeepAliveTimer              = uint8(0x00f)
-	FIDHostCtrlThermalManagement   = uint8(0x010)
-	FIDNonOPPowerStateConf         = uint8(0x011)
-	FIDReadRecoveryLevelConf       = uint8(0x012)
-	FIDPredictableLatModeConf      = uint8(0x013)
-	FIDPredictableLatModeWin       = uint8(0x014)
-	FIDLBAStatusInfoReportInterval = uint8(0x015)
-	FIDHostBehaviorSupport         = uint8(0x016)
-	FIDSanitizeConf                = uint8(0x017)
-	FIDEnduranceGroupEventConf     = uint8(0x018)
+	FIDArbitration                 = spec.FeatureID(0x001)
+	FIDPowerManagement             = spec.FeatureID(0x002)
+	FIDLBARangeType                = spec.FeatureID(0x003)
+	FIDTemperatureThreshold        = spec.FeatureID(0x004)
+	FIDErrorRecovery               = spec.FeatureID(0x005)
+	FIDVolatileWriteCache          = spec.FeatureID(0x006)
+	FIDNumberOfQueues              = spec.FeatureID(0x007)
+	FIDInterruptCoalescing         = spec.FeatureID(0x008)
+	FIDInterruptVectorConf         = spec.FeatureID(0x009)
+	FIDWriteAtomicityNormal        = spec.FeatureID(0x00a)
+	FIDAsyncEventConf              = spec.FeatureID(0x00b)
+	FIDAutoPowerStateTransition    = spec.FeatureID(0x00c)
+	FIDHostMemoryBuffer            = spec.FeatureID(0x00d)
+	FIDTimeStamp                   = spec.FeatureID(0x00e)
+	FIDKeepAliveTimer              = spec.FeatureID(0x00f)
+	FIDHostCtrlThermalManagement   = spec.FeatureID(0x010)
+	FIDNonOPPowerStateConf         = spec.FeatureID(0x011)
+	FIDReadRecoveryLevelConf       = spec.FeatureID(0x012)
+	FIDPredictableLatModeConf      = spec.FeatureID(0x013)
+	FIDPredictableLatModeWin       = spec.FeatureID(0x014)
+	FIDLBAStatusInfoReportInterval = spec.FeatureID(0x015)
+	FIDHostBehaviorSupport         = spec.FeatureID(0x016)
+	FIDSanitizeConf                = spec.FeatureID(0x017)
+	FIDEnduranceGroupEventConf     = spec.FeatureID(0x018)
+
+	// vendor specific features defined by the OCP Datacenter NVMe SSD specification
+	FIDOCPLatencyMonitor = spec.FeatureID(0x0c5)
 
 	selFieldUMask = ^(uint32(0x007) << 8)
+
+	// svField is the Save (SV) bit of the set-feature command's CDW10.
+	svField = uint32(1) << 31
+
+	// oncsSaveSelect is the ONCS bit of the Save field in the set-feature command and the Select
+	// field in the get-feature command.
+	oncsSaveSelect = uint16(1 << 4)
 )
 
 type getFeatureCmd struct {
@@ -51,7 +64,7 @@ func (f *getFeatureCmd) SEL(sel sel) {
 }
 
 // newGetFeatureCmd generate an AdminCmd structure to retrieve the NVMe's feature pages.
-func newGetFeatureCmd(nsid uint32, fid uint8, specific uint32, sel sel, v interface{}) (*getFeatureCmd, error) {
+func newGetFeatureCmd(nsid uint32, fid spec.FeatureID, specific uint32, sel sel, v interface{}) (*getFeatureCmd, error) {
 	// TODO check common FID doesn't need specific field
 	cmd := getFeatureCmd{
 		nvme.AdminCmd{
@@ -75,11 +88,133 @@ func newGetFeatureCmd(nsid uint32, fid uint8, specific uint32, sel sel, v interf
 	return &cmd, nil
 }
 
-// GetFeatureCMD retrieve a feature data.
-func GetFeature(file *os.File, nsid uint32, fid uint8, specific uint32, sel sel, v interface{}) error {
+// GetFeature retrieves a feature data and returns the dword 0 of the completion queue entry which
+// has the feature value of the features without the data structure.
+func GetFeature(file *os.File, nsid uint32, fid spec.FeatureID, specific uint32, sel sel, v interface{}) (uint32, error) {
 	if cmd, err := newGetFeatureCmd(nsid, fid, specific, sel, v); err != nil {
+		return 0, err
+	} else if err = nvme.IOCtlAdminCmd(file, &cmd.AdminCmd); err != nil {
+		return 0, err
+	} else {
+		return cmd.Result, nil
+	}
+}
+
+type setFeatureCmd struct {
+	nvme.AdminCmd
+}
+
+// newSetFeatureCmd generate an AdminCmd structure to change the NVMe's feature. The feature specific
+// values are set on CDW11 and CDW12, and v is transferred only if the feature has a data structure.
+func newSetFeatureCmd(nsid uint32, fid spec.FeatureID, cdw11, cdw12 uint32, v interface{}) (*setFeatureCmd, error) {
+	cmd := setFeatureCmd{
+		nvme.AdminCmd{
+			PassthruCmd: nvme.PassthruCmd{
+				OpCode: nvme.AdminSetFeatures,
+				NSId:   nsid,
+				CDW10:  uint32(fid),
+				CDW11:  cdw11,
+				CDW12:  cdw12,
+			},
+			TimeoutMSec: 0,
+			Result:      0,
+		},
+	}
+
+	if v != nil {
+		if err := cmd.SetData(v); err != nil {
+			return nil, err
+		}
+	}
+
+	return &cmd, nil
+}
+
+// SV() change the Save field
+func (f *setFeatureCmd) SV(save bool) {
+	if save {
+		f.CDW10 |= svField
+	} else {
+		f.CDW10 &^= svField
+	}
+}
+
+// capability is the supported capabilities of a feature which is retrieved with SELSupportCap.
+type capability uint32
+
+// Saveable returns true if the feature value can be saved across the power cycles.
+func (c capability) Saveable() bool {
+	return c&(1<<0) != 0
+}
+
+// NamespaceSpecific returns true if the feature is namespace specific.
+func (c capability) NamespaceSpecific() bool {
+	return c&(1<<1) != 0
+}
+
+// Changeable returns true if the feature value can be changed by the set-feature command.
+func (c capability) Changeable() bool {
+	return c&(1<<2) != 0
+}
+
+// validate checks the set-feature request with the feature's capabilities.
+func (c capability) validate(fid spec.FeatureID, save bool) error {
+	if !c.Changeable() {
+		return fmt.Errorf("feature %v is not changeable", fid)
+	} else if save && !c.Saveable() {
+		return fmt.Errorf("feature %v is not saveable", fid)
+	}
+
+	return nil
+}
+
+// GetCapability retrieves the supported capabilities of the feature. The controller should support
+// the Select field of the get-feature command (ONCS bit 4) to report the capabilities.
+//goland:noinspection GoExportedFuncWithUnexportedType
+func GetCapability(file *os.File, nsid uint32, fid spec.FeatureID) (capability, error) {
+	result, err := GetFeature(file, nsid, fid, 0, SELSupportCap, nil)
+	return capability(result), err
+}
+
+// checkSetFeature checks the set-feature request with the feature's capabilities before changing
+// the feature. If the controller doesn't report the capabilities, only saving is refused because
+// the controller doesn't support the Save field either.
+func checkSetFeature(file *os.File, nsid uint32, fid spec.FeatureID, save bool) error {
+	idCtrl := identify.CtrlIdentify{}
+	if err := identify.GetCtrlIdentify(file, &idCtrl); err != nil {
+		return err
+	} else if idCtrl.ONCS&oncsSaveSelect == 0 {
+		if save {
+			return fmt.Errorf("saving feature is not supported by the controller")
+		}
+
+		return nil
+	}
+
+	if c, err := GetCapability(file, nsid, fid); err != nil {
 		return err
 	} else {
-		return nvme.IOCtlAdminCmd(file, &cmd.AdminCmd)
+		return c.validate(fid, save)
+	}
+}
+
+// SetFeature changes a feature with the feature specific values and the data structure, and
+// returns the dword 0 of the completion queue entry. If save is true, the changed value persists
+// across the power cycles. The request is checked with the feature's capabilities, so the feature
+// which is not changeable or not saveable fails before issuing the command.
+func SetFeature(file *os.File, nsid uint32, fid spec.FeatureID, cdw11, cdw12 uint32, save bool, v interface{}) (uint32, error) {
+	cmd, err := newSetFeatureCmd(nsid, fid, cdw11, cdw12, v)
+	if err != nil {
+		return 0, err
+	} else if err = checkSetFeature(file, nsid, fid, save); err != nil {
+		return 0, err
+	}
+
+	cmd.SV(save)
+
+	if err = nvme.IOCtlAdminCmd(file, &cmd.AdminCmd); err != nil {
+		return 0, err
+	} else {
+		return cmd.Result, nil
 	}
 }
@@ -2,6 +2,8 @@ package feature
 
 import (
 	"github.com/stretchr/testify/assert"
+	"github.com/sungup/go-nvmecli/pkg/nvme"
+	"github.com/sungup/go-nvmecli/pkg/nvme/spec"
 	"testing"
 )
 
@@ -9,9 +11,9 @@ func TestGetFeatureCmd_SEL(t *testing.T) {
 	a := assert.New(t)
 
 	const (
-		expectedSpecific       = 0xEFCDAB89
-		expectedFID      uint8 = 0x0CF
-		expectedSEL            = SELSupportCap
+		expectedSpecific                = 0xEFCDAB89
+		expectedFID      spec.FeatureID = 0x0CF
+		expectedSEL                     = SELSupportCap
 	)
 
 	v := make([]byte, 4)
@@ -31,3 +33,80 @@ func TestGetFeatureCmd_SEL(t *testing.T) {
 	a.Equal(origin.CDW12, tested.CDW12)
 	a.Equal(origin.CDW13, tested.CDW13)
 }
+
+func TestNewSetFeatureCmd(t *testing.T) {
+	a := assert.New(t)
+
+	const (
+		expectedCDW11                = 0xEFCDAB89
+		expectedCDW12                = 0x01234567
+		expectedFID   spec.FeatureID = FIDPredictableLatModeWin
+	)
+
+	// without data structure
+	tested, err := newSetFeatureCmd(expectedNSId, expectedFID, expectedCDW11, expectedCDW12, nil)
+	a.NoError(err)
+	a.Equal(nvme.AdminSetFeatures, tested.OpCode)
+	a.Equal(uint32(expectedNSId), tested.NSId)
+	a.Equal(uint32(expectedFID), tested.CDW10)
+	a.Equal(uint32(expectedCDW11), tested.CDW11)
+	a.Equal(uint32(expectedCDW12), tested.CDW12)
+	a.Zero(tested.Data)
+	a.Zero(tested.DataLength)
+
+	// with data structure
+	config := PLMConfig{}
+	tested, err = newSetFeatureCmd(expectedNSId, expectedFID, expectedCDW11, expectedCDW12, &config)
+	a.NoError(err)
+	a.NotZero(tested.Data)
+	a.Equal(uint32(512), tested.DataLength)
+
+	// invalid data structure
+	tested, err = newSetFeatureCmd(expectedNSId, expectedFID, expectedCDW11, expectedCDW12, config)
+	a.Error(err)
+	a.Nil(tested)
+}
+
+func TestSetFeatureCmd_SV(t *testing.T) {
+	a := assert.New(t)
+
+	const expectedFID = FIDTemperatureThreshold
+
+	tested, _ := newSetFeatureCmd(expectedNSId, expectedFID, 0, 0, nil)
+	origin := tested.CDW10
+
+	tested.SV(true)
+	a.Equal(origin|svField, tested.CDW10)
+	a.Equal(uint32(expectedFID), tested.CDW10&^svField)
+
+	tested.SV(false)
+	a.Equal(origin, tested.CDW10)
+}
+
+func TestCapability(t *testing.T) {
+	a := assert.New(t)
+
+	tested := capability(0b101)
+	a.True(tested.Saveable())
+	a.False(tested.NamespaceSpecific())
+	a.True(tested.Changeable())
+
+	tested = capability(0b010)
+	a.False(tested.Saveable())
+	a.True(tested.NamespaceSpecific())
+	a.False(tested.Changeable())
+}
+
+func TestCapability_validate(t *testing.T) {
+	a := assert.New(t)
+
+	a.NoError(capability(0b101).validate(FIDTemperatureThreshold, true))
+	a.NoError(capability(0b100).validate(FIDTemperatureThreshold, false))
+
+	// not saveable
+	a.EqualError(capability(0b100).validate(FIDTemperatureThreshold, true), "feature Temperature Threshold is not saveable")
+
+	// not changeable
+	a.Error(capability(0b001).validate(FIDTemperatureThreshold, false))
+	a.Error(capability(0b001).validate(FIDTemperatureThreshold, true))
+}
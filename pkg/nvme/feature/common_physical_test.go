@@ -21,6 +21,35 @@ func TestGetFeature(t *testing.T) {
 	buffer := [8]byte{}
 
 	// get feature of timestamp will return not 0 value
-	a.NoError(GetFeature(dev, expectedNSId, expectedFID, 0, expectedSEL, buffer[:]))
+	_, err := GetFeature(dev, expectedNSId, expectedFID, 0, expectedSEL, buffer[:])
+	a.NoError(err)
 	a.NotZero(binary.BigEndian.Uint64(buffer[:]))
 }
+
+func TestGetCapability(t *testing.T) {
+	a := assert.New(t)
+
+	dev, _ := os.Open(targetDevice)
+
+	// the temperature threshold feature is mandatory and changeable
+	if tested, err := GetCapability(dev, 0, FIDTemperatureThreshold); err == nil {
+		a.True(tested.Changeable())
+	}
+}
+
+func TestSetFeature(t *testing.T) {
+	a := assert.New(t)
+
+	dev, _ := os.Open(targetDevice)
+
+	// set the current temperature threshold again without saving
+	current, err := GetFeature(dev, 0, FIDTemperatureThreshold, 0, SELCurrent, nil)
+	a.NoError(err)
+
+	_, err = SetFeature(dev, 0, FIDTemperatureThreshold, current, 0, false, nil)
+	a.NoError(err)
+
+	after, err := GetFeature(dev, 0, FIDTemperatureThreshold, 0, SELCurrent, nil)
+	a.NoError(err)
+	a.Equal(current, after)
+}
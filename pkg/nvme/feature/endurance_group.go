@@ -0,0 +1,37 @@
+package feature
+
+import (
+	"math"
+	"os"
+)
+
+// -------------------------------------------- //
+// FID 18h: Endurance Group Event Configuration //
+// -------------------------------------------- //
+
+const (
+	EGEventSpareBelowThreshold = uint8(1 << 0)
+	EGEventReliabilityDegraded = uint8(1 << 2)
+	EGEventReadOnly            = uint8(1 << 3)
+
+	shiftEGEvent = 16
+)
+
+// GetEnduranceGroupEventConf retrieves the Endurance Group Critical Warnings event bits which are
+// enabled to report the Endurance Group Event Aggregate asynchronous event on the endurance group.
+func GetEnduranceGroupEventConf(file *os.File, endgid uint16, sel sel) (uint8, error) {
+	if result, err := GetFeature(file, 0, FIDEnduranceGroupEventConf, uint32(endgid), sel, nil); err != nil {
+		return 0, err
+	} else {
+		return uint8(result & math.MaxUint8), nil
+	}
+}
+
+// SetEnduranceGroupEventConf enables the Endurance Group Critical Warnings event bits of the
+// endurance group. The bits not in events are disabled.
+func SetEnduranceGroupEventConf(file *os.File, endgid uint16, events uint8) error {
+	cdw11 := uint32(events)<<shiftEGEvent | uint32(endgid)
+
+	_, err := SetFeature(file, 0, FIDEnduranceGroupEventConf, cdw11, 0, false, nil)
+	return err
+}
@@ -0,0 +1,25 @@
+// +build with_phys_device
+
+package feature
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"testing"
+)
+
+func TestEnduranceGroupEventConf(t *testing.T) {
+	a := assert.New(t)
+
+	dev, _ := os.Open(targetDevice)
+
+	if events, err := GetEnduranceGroupEventConf(dev, 1, SELCurrent); err == nil {
+		a.NoError(SetEnduranceGroupEventConf(dev, 1, events|EGEventReadOnly))
+
+		tested, err := GetEnduranceGroupEventConf(dev, 1, SELCurrent)
+		a.NoError(err)
+		a.Equal(events|EGEventReadOnly, tested)
+
+		a.NoError(SetEnduranceGroupEventConf(dev, 1, events))
+	}
+}
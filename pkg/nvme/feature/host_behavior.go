@@ -0,0 +1,42 @@
+package feature
+
+import "os"
+
+// ------------------------------ //
+// FID 16h: Host Behavior Support //
+// ------------------------------ //
+
+// HostBehavior is the Host Behavior Support data structure which tells the controller the host
+// behaviors supported by the host software.
+type HostBehavior struct {
+	ACRE   uint8     // [00] Advanced Command Retry Enable
+	ETDAS  uint8     // [01] Extended Telemetry Data Area 4 Supported
+	LBAFEE uint8     // [02] LBA Format Extension Enable
+	_      [509]byte // [511:03] reserved
+}
+
+// GetHostBehavior retrieves the Host Behavior Support data structure into v.
+func GetHostBehavior(file *os.File, sel sel, v interface{}) error {
+	_, err := GetFeature(file, 0, FIDHostBehaviorSupport, 0, sel, v)
+	return err
+}
+
+// SetHostBehavior changes the Host Behavior Support with the data structure in v.
+func SetHostBehavior(file *os.File, v interface{}) error {
+	_, err := SetFeature(file, 0, FIDHostBehaviorSupport, 0, 0, false, v)
+	return err
+}
+
+// EnableTelemetryDA4 sets the ETDAS of the current Host Behavior Support to let the controller
+// report the Data Area 4 in the telemetry log pages. Other host behaviors are not changed.
+func EnableTelemetryDA4(file *os.File) error {
+	behavior := HostBehavior{}
+
+	if err := GetHostBehavior(file, SELCurrent, &behavior); err != nil {
+		return err
+	}
+
+	behavior.ETDAS = 1
+
+	return SetHostBehavior(file, &behavior)
+}
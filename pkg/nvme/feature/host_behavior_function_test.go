@@ -0,0 +1,14 @@
+package feature
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"unsafe"
+)
+
+func TestHostBehaviorSize(t *testing.T) {
+	a := assert.New(t)
+
+	a.Equal(uintptr(512), unsafe.Sizeof(HostBehavior{}))
+	a.Equal(uintptr(1), unsafe.Offsetof(HostBehavior{}.ETDAS))
+}
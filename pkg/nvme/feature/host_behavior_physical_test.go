@@ -0,0 +1,35 @@
+// +build with_phys_device
+
+package feature
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"testing"
+)
+
+func TestGetHostBehavior(t *testing.T) {
+	a := assert.New(t)
+
+	dev, _ := os.Open(targetDevice)
+
+	behavior := HostBehavior{}
+	if err := GetHostBehavior(dev, SELCurrent, &behavior); err == nil {
+		a.LessOrEqual(behavior.ETDAS, uint8(1))
+	}
+}
+
+func TestEnableTelemetryDA4(t *testing.T) {
+	// TODO re-verify this test code using the NVMe 2.0 telemetry data area 4 support NVMe device
+	/*
+		a := assert.New(t)
+
+		dev, _ := os.Open(targetDevice)
+
+		a.NoError(EnableTelemetryDA4(dev))
+
+		behavior := HostBehavior{}
+		a.NoError(GetHostBehavior(dev, SELCurrent, &behavior))
+		a.Equal(uint8(1), behavior.ETDAS)
+	*/
+}
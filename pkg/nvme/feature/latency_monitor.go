@@ -0,0 +1,40 @@
+package feature
+
+import (
+	"github.com/sungup/go-nvmecli/pkg/nvme/types"
+	"os"
+)
+
+// ----------------------------------- //
+// FID C5h: OCP Latency Monitor Config //
+// ----------------------------------- //
+
+// LatencyMonitor is the Latency Monitor feature data structure of the OCP Datacenter NVMe SSD
+// specification. The thresholds set the boundaries of the 4 latency buckets: bucket 0 is under
+// threshold A, bucket 1 is A to B, bucket 2 is B to C, and bucket 3 is over C up to D.
+type LatencyMonitor struct {
+	ActiveBucketTimerThreshold uint16       // [01:00] active window in 5 minutes unit
+	ActiveThresholdA           uint8        // [02] in 5ms unit
+	ActiveThresholdB           uint8        // [03] in 5ms unit
+	ActiveThresholdC           uint8        // [04] in 5ms unit
+	ActiveThresholdD           uint8        // [05] in 5ms unit
+	ActiveLatencyConfig        uint16       // [07:06] latency measurement mode of each bucket and operation
+	ActiveLatencyMinWindow     uint8        // [08] in 100ms unit
+	DebugLogTriggerEnable      types.Uint16 // [10:09]
+	DiscardDebugLog            uint8        // [11]
+	LatencyMonitorEnable       uint8        // [12]
+	_                          [4083]byte   // [4095:13] reserved
+}
+
+// GetLatencyMonitor retrieves the OCP Latency Monitor feature data structure into v.
+func GetLatencyMonitor(file *os.File, sel sel, v interface{}) error {
+	_, err := GetFeature(file, 0, FIDOCPLatencyMonitor, 0, sel, v)
+	return err
+}
+
+// SetLatencyMonitor changes the OCP Latency Monitor configuration with the data structure in v.
+// The bucket counters of the active window are cleared by the controller.
+func SetLatencyMonitor(file *os.File, v interface{}) error {
+	_, err := SetFeature(file, 0, FIDOCPLatencyMonitor, 0, 0, false, v)
+	return err
+}
@@ -0,0 +1,16 @@
+package feature
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"unsafe"
+)
+
+func TestLatencyMonitorSize(t *testing.T) {
+	a := assert.New(t)
+
+	a.Equal(uintptr(4096), unsafe.Sizeof(LatencyMonitor{}))
+	a.Equal(uintptr(6), unsafe.Offsetof(LatencyMonitor{}.ActiveLatencyConfig))
+	a.Equal(uintptr(9), unsafe.Offsetof(LatencyMonitor{}.DebugLogTriggerEnable))
+	a.Equal(uintptr(12), unsafe.Offsetof(LatencyMonitor{}.LatencyMonitorEnable))
+}
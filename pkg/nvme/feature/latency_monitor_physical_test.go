@@ -0,0 +1,34 @@
+// +build with_phys_device
+
+package feature
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"testing"
+)
+
+func TestGetLatencyMonitor(t *testing.T) {
+	a := assert.New(t)
+
+	dev, _ := os.Open(targetDevice)
+
+	// the device which doesn't follow the OCP specification doesn't support FID C5h
+	monitor := LatencyMonitor{}
+	if err := GetLatencyMonitor(dev, SELCurrent, &monitor); err == nil {
+		a.LessOrEqual(monitor.LatencyMonitorEnable, uint8(1))
+	}
+}
+
+func TestSetLatencyMonitor(t *testing.T) {
+	// TODO re-verify this test code using the OCP latency monitor support NVMe device
+	/*
+		a := assert.New(t)
+
+		dev, _ := os.Open(targetDevice)
+
+		monitor := LatencyMonitor{}
+		a.NoError(GetLatencyMonitor(dev, SELCurrent, &monitor))
+		a.NoError(SetLatencyMonitor(dev, &monitor))
+	*/
+}
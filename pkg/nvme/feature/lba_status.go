@@ -0,0 +1,55 @@
+package feature
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"time"
+)
+
+// ----------------------------------------------- //
+// FID 15h: LBA Status Information Report Interval //
+// ----------------------------------------------- //
+
+// lbaStatusIntervalUnit is the time unit of LSIRI and LSIPI.
+const lbaStatusIntervalUnit = 100 * time.Millisecond
+
+// toIntervalUnit converts the duration to the 100ms unit value.
+func toIntervalUnit(d time.Duration) (uint32, error) {
+	if d < 0 || d/lbaStatusIntervalUnit > math.MaxUint16 {
+		return 0, fmt.Errorf("LBA status interval is out of range: %v", d)
+	}
+
+	return uint32(d / lbaStatusIntervalUnit), nil
+}
+
+// GetLBAStatusInterval retrieves the LBA Status Information Report Interval (LSIRI) and the LBA
+// Status Information Poll Interval (LSIPI).
+func GetLBAStatusInterval(file *os.File, sel sel) (report, poll time.Duration, err error) {
+	var result uint32
+
+	if result, err = GetFeature(file, 0, FIDLBAStatusInfoReportInterval, 0, sel, nil); err != nil {
+		return 0, 0, err
+	}
+
+	report = time.Duration(result&math.MaxUint16) * lbaStatusIntervalUnit
+	poll = time.Duration(result>>16) * lbaStatusIntervalUnit
+
+	return report, poll, nil
+}
+
+// SetLBAStatusInterval changes the LSIRI and LSIPI. Each interval is truncated by 100ms unit.
+func SetLBAStatusInterval(file *os.File, report, poll time.Duration) error {
+	lsiri, err := toIntervalUnit(report)
+	if err != nil {
+		return err
+	}
+
+	lsipi, err := toIntervalUnit(poll)
+	if err != nil {
+		return err
+	}
+
+	_, err = SetFeature(file, 0, FIDLBAStatusInfoReportInterval, lsipi<<16|lsiri, 0, false, nil)
+	return err
+}
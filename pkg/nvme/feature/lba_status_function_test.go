@@ -0,0 +1,34 @@
+package feature
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestToIntervalUnit(t *testing.T) {
+	a := assert.New(t)
+
+	for duration, expected := range map[time.Duration]uint32{
+		0:                          0,
+		99 * time.Millisecond:      0,
+		time.Second:                10,
+		6553500 * time.Millisecond: 0xFFFF,
+	} {
+		tested, err := toIntervalUnit(duration)
+		a.NoError(err)
+		a.Equal(expected, tested)
+	}
+
+	for _, duration := range []time.Duration{-time.Millisecond, 6553600 * time.Millisecond} {
+		_, err := toIntervalUnit(duration)
+		a.Error(err)
+	}
+}
+
+func TestSetLBAStatusInterval_OutOfRange(t *testing.T) {
+	a := assert.New(t)
+
+	a.Error(SetLBAStatusInterval(nil, -time.Second, 0))
+	a.Error(SetLBAStatusInterval(nil, 0, 2*time.Hour))
+}
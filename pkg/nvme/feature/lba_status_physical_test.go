@@ -0,0 +1,23 @@
+// +build with_phys_device
+
+package feature
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"testing"
+)
+
+func TestLBAStatusInterval(t *testing.T) {
+	a := assert.New(t)
+
+	dev, _ := os.Open(targetDevice)
+
+	if report, poll, err := GetLBAStatusInterval(dev, SELCurrent); err == nil {
+		a.NoError(SetLBAStatusInterval(dev, report, poll))
+
+		tested, _, err := GetLBAStatusInterval(dev, SELCurrent)
+		a.NoError(err)
+		a.Equal(report, tested)
+	}
+}
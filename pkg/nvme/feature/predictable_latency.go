@@ -0,0 +1,85 @@
+package feature
+
+import (
+	"fmt"
+	"os"
+)
+
+// ---------------------------------------------- //
+// FID 13h/14h: Predictable Latency Mode Settings //
+// ---------------------------------------------- //
+
+// plmWindow is the window select of the Predictable Latency Mode Window feature.
+type plmWindow uint8
+
+const (
+	PLMWindowDTWIN = plmWindow(0b001)
+	PLMWindowNDWIN = plmWindow(0b010)
+
+	plmWindowMask = uint32(0b111)
+)
+
+const (
+	PLMEventDTWINReads             = uint16(1 << 0)
+	PLMEventDTWINWrites            = uint16(1 << 1)
+	PLMEventDTWINTime              = uint16(1 << 2)
+	PLMEventAutoToNDWIN            = uint16(1 << 14)
+	PLMEventDeterministicExcursion = uint16(1 << 15)
+
+	plmEnable = uint32(1 << 0)
+)
+
+// PLMConfig is the Predictable Latency Mode Configuration data structure of an NVM Set. The
+// thresholds are compared with the DTWIN estimates in the Predictable Latency Per NVM Set log page
+// to report the predictable latency events enabled by EnableEvent.
+type PLMConfig struct {
+	EnableEvent          uint16    // [01:00]
+	_                    [6]byte   // [07:02] reserved
+	DTWINReadsThreshold  uint64    // [15:08]
+	DTWINWritesThreshold uint64    // [23:16]
+	DTWINTimeThreshold   uint64    // [31:24]
+	_                    [480]byte // [511:32] reserved
+}
+
+// GetPLMConfig retrieves the Predictable Latency Mode Config (13h) of the NVM Set into v, and
+// returns whether the predictable latency mode is enabled.
+func GetPLMConfig(file *os.File, nvmSetID uint16, sel sel, v interface{}) (bool, error) {
+	if result, err := GetFeature(file, 0, FIDPredictableLatModeConf, uint32(nvmSetID), sel, v); err != nil {
+		return false, err
+	} else {
+		return result&plmEnable != 0, nil
+	}
+}
+
+// SetPLMConfig enables or disables the predictable latency mode of the NVM Set with the
+// configuration in v. v can be nil to disable the predictable latency mode.
+func SetPLMConfig(file *os.File, nvmSetID uint16, enable bool, v interface{}) error {
+	cdw12 := uint32(0)
+	if enable {
+		cdw12 = plmEnable
+	}
+
+	_, err := SetFeature(file, 0, FIDPredictableLatModeConf, uint32(nvmSetID), cdw12, false, v)
+	return err
+}
+
+// GetPLMWindow retrieves the window of the NVM Set which is currently operated in (14h).
+//goland:noinspection GoExportedFuncWithUnexportedType
+func GetPLMWindow(file *os.File, nvmSetID uint16, sel sel) (plmWindow, error) {
+	if result, err := GetFeature(file, 0, FIDPredictableLatModeWin, uint32(nvmSetID), sel, nil); err != nil {
+		return 0, err
+	} else {
+		return plmWindow(result & plmWindowMask), nil
+	}
+}
+
+// SetPLMWindow requests the NVM Set to enter the deterministic or non-deterministic window. The
+// predictable latency mode of the NVM Set should be enabled by SetPLMConfig before.
+func SetPLMWindow(file *os.File, nvmSetID uint16, window plmWindow) error {
+	if window != PLMWindowDTWIN && window != PLMWindowNDWIN {
+		return fmt.Errorf("unexpected predictable latency mode window: %d", window)
+	}
+
+	_, err := SetFeature(file, 0, FIDPredictableLatModeWin, uint32(nvmSetID), uint32(window), false, nil)
+	return err
+}
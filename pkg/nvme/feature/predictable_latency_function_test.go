@@ -0,0 +1,21 @@
+package feature
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"unsafe"
+)
+
+func TestPLMConfigSize(t *testing.T) {
+	a := assert.New(t)
+
+	a.Equal(uintptr(512), unsafe.Sizeof(PLMConfig{}))
+}
+
+func TestSetPLMWindow_InvalidWindow(t *testing.T) {
+	a := assert.New(t)
+
+	for _, window := range []plmWindow{0b000, 0b011, 0b111} {
+		a.Error(SetPLMWindow(nil, 1, window))
+	}
+}
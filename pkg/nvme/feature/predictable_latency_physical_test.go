@@ -0,0 +1,45 @@
+// +build with_phys_device
+
+package feature
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"testing"
+)
+
+func TestPLMWindow(t *testing.T) {
+	// TODO re-verify this test code using the I/O determinism support NVMe device
+	/*
+		a := assert.New(t)
+
+		dev, _ := os.Open(targetDevice)
+
+		config := PLMConfig{}
+		a.NoError(SetPLMConfig(dev, 1, true, &config))
+
+		for _, window := range []plmWindow{PLMWindowNDWIN, PLMWindowDTWIN} {
+			a.NoError(SetPLMWindow(dev, 1, window))
+
+			tested, err := GetPLMWindow(dev, 1, SELCurrent)
+			a.NoError(err)
+			a.Equal(window, tested)
+		}
+
+		enabled, err := GetPLMConfig(dev, 1, SELCurrent, &config)
+		a.NoError(err)
+		a.True(enabled)
+	*/
+}
+
+func TestGetPLMConfig(t *testing.T) {
+	a := assert.New(t)
+
+	dev, _ := os.Open(targetDevice)
+
+	config := PLMConfig{}
+	if _, err := GetPLMConfig(dev, 1, SELCurrent, &config); err == nil {
+		a.Zero(config.EnableEvent & ^(PLMEventDTWINReads | PLMEventDTWINWrites | PLMEventDTWINTime |
+			PLMEventAutoToNDWIN | PLMEventDeterministicExcursion))
+	}
+}
@@ -0,0 +1,32 @@
+package feature
+
+import "os"
+
+// ------------------------ //
+// FID 17h: Sanitize Config //
+// ------------------------ //
+
+// nodrm is the No-Deallocate Response Mode bit of the Sanitize Config feature.
+const nodrm = uint32(1 << 0)
+
+// GetSanitizeConf retrieves the No-Deallocate Response Mode. If it is true, the sanitize command
+// with NDAS set is performed with the additional media modification instead of being aborted when
+// the controller inhibits No-Deallocate After Sanitize.
+func GetSanitizeConf(file *os.File, sel sel) (bool, error) {
+	if result, err := GetFeature(file, 0, FIDSanitizeConf, 0, sel, nil); err != nil {
+		return false, err
+	} else {
+		return result&nodrm != 0, nil
+	}
+}
+
+// SetSanitizeConf changes the No-Deallocate Response Mode.
+func SetSanitizeConf(file *os.File, noDeallocResponse bool) error {
+	cdw11 := uint32(0)
+	if noDeallocResponse {
+		cdw11 |= nodrm
+	}
+
+	_, err := SetFeature(file, 0, FIDSanitizeConf, cdw11, 0, false, nil)
+	return err
+}
@@ -0,0 +1,33 @@
+// +build with_phys_device
+
+package feature
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"testing"
+)
+
+func TestGetSanitizeConf(t *testing.T) {
+	a := assert.New(t)
+
+	dev, _ := os.Open(targetDevice)
+
+	// the controller which doesn't support sanitize doesn't support the feature
+	if _, err := GetSanitizeConf(dev, SELCurrent); err != nil {
+		a.Error(err)
+	}
+}
+
+func TestSetSanitizeConf(t *testing.T) {
+	// TODO re-verify this test code using the sanitize support NVMe device
+	/*
+		a := assert.New(t)
+
+		dev, _ := os.Open(targetDevice)
+
+		current, err := GetSanitizeConf(dev, SELCurrent)
+		a.NoError(err)
+		a.NoError(SetSanitizeConf(dev, current))
+	*/
+}
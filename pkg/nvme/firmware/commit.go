@@ -0,0 +1,205 @@
+package firmware
+
+import (
+	"errors"
+	"fmt"
+	"github.com/sungup/go-nvmecli/pkg/nvme"
+	"github.com/sungup/go-nvmecli/pkg/nvme/getlog"
+	"github.com/sungup/go-nvmecli/pkg/nvme/identify"
+	"os"
+)
+
+// action is the Commit Action (CA) of the Firmware Commit command.
+type action uint8
+
+const (
+	Replace             = action(0x0) // replace the image in the slot without activation
+	ReplaceActivate     = action(0x1) // replace the image and activate it at the next reset
+	Activate            = action(0x2) // activate the image in the slot at the next reset
+	ActivateImmediate   = action(0x3) // replace the image and activate it without reset
+	BootPartition       = action(0x6) // replace the boot partition
+	BootPartitionActive = action(0x7) // mark the boot partition as active
+)
+
+// String returns the name of the commit action.
+func (a action) String() string {
+	switch a {
+	case Replace:
+		return "Replace"
+	case ReplaceActivate:
+		return "Replace and Activate"
+	case Activate:
+		return "Activate"
+	case ActivateImmediate:
+		return "Activate Immediately"
+	case BootPartition:
+		return "Replace Boot Partition"
+	case BootPartitionActive:
+		return "Activate Boot Partition"
+	default:
+		return fmt.Sprintf("Reserved (%Xh)", uint8(a))
+	}
+}
+
+// replaces returns true if the action writes the downloaded image into the firmware slot.
+func (a action) replaces() bool {
+	return a == Replace || a == ReplaceActivate || a == ActivateImmediate
+}
+
+// activates returns true if the action activates the firmware slot.
+func (a action) activates() bool {
+	return a == ReplaceActivate || a == Activate || a == ActivateImmediate
+}
+
+const (
+	// FRMW bits of the Identify Controller data structure.
+	frmwSlot1RO    = uint8(1 << 0) // the firmware slot 1 is read only
+	frmwNoReset    = uint8(1 << 4) // activation without reset is supported
+	frmwSlotsShift = 1
+	frmwSlotsMask  = uint8(0b111)
+
+	maxFirmwareSlot = 7
+)
+
+// commitStatus is the decoded result of the Firmware Commit command. Some command specific status
+// codes mean the commit has been accepted and the firmware is activated by a reset.
+type commitStatus uint8
+
+const (
+	CommitSuccess          = commitStatus(0x00)
+	CommitConventionalRst  = commitStatus(0x0B) // activated at the next conventional reset
+	CommitSubsystemRst     = commitStatus(0x10) // activated at the next NVM subsystem reset
+	CommitControllerRst    = commitStatus(0x11) // activated at the next controller level reset
+	CommitMaxTimeViolation = commitStatus(0x12) // not activated because of MTFA, reset is needed
+)
+
+// String returns the description of the commit status.
+func (s commitStatus) String() string {
+	switch s {
+	case CommitSuccess:
+		return "Success"
+	case CommitConventionalRst:
+		return "Conventional Reset Required"
+	case CommitSubsystemRst:
+		return "NVM Subsystem Reset Required"
+	case CommitControllerRst:
+		return "Controller Level Reset Required"
+	case CommitMaxTimeViolation:
+		return "Maximum Time Violation"
+	default:
+		return fmt.Sprintf("Unknown (%02Xh)", uint8(s))
+	}
+}
+
+// ResetRequired returns true if a reset is required to activate the committed firmware.
+func (s commitStatus) ResetRequired() bool {
+	return s != CommitSuccess
+}
+
+// decodeCommitStatus converts the completion error into the commit status. The error is cleared
+// if the status means the commit has been accepted.
+func decodeCommitStatus(err error) (commitStatus, error) {
+	var cErr *nvme.CompletionError
+
+	if err == nil {
+		return CommitSuccess, nil
+	} else if !errors.As(err, &cErr) || cErr.SCT() != nvme.SCTCommandSpecific {
+		return CommitSuccess, err
+	}
+
+	switch s := commitStatus(cErr.SC()); s {
+	case CommitConventionalRst, CommitSubsystemRst, CommitControllerRst:
+		return s, nil
+	case CommitMaxTimeViolation:
+		return s, err
+	default:
+		return CommitSuccess, err
+	}
+}
+
+// newCommitCmd generates an AdminCmd structure to commit the firmware slot or the boot partition.
+func newCommitCmd(slot uint8, act action, bpid uint8) *nvme.AdminCmd {
+	return &nvme.AdminCmd{
+		PassthruCmd: nvme.PassthruCmd{
+			OpCode: nvme.AdminActivateFW,
+			CDW10:  uint32(slot)&0x07 | uint32(act&0x07)<<3 | uint32(bpid&0x01)<<31,
+		},
+		TimeoutMSec: 0,
+		Result:      0,
+	}
+}
+
+// validateCommit checks the slot and the action with the FRMW of the controller. The slot 0 lets
+// the controller choose the slot for the replace actions.
+func validateCommit(frmw uint8, slot uint8, act action, bpid uint8) error {
+	slots := frmw >> frmwSlotsShift & frmwSlotsMask
+
+	switch act {
+	case Replace, ReplaceActivate, Activate, ActivateImmediate:
+		if bpid != 0 {
+			return fmt.Errorf("boot partition identifier is not allowed for %v", act)
+		} else if slot > slots {
+			return fmt.Errorf("firmware slot %d is over the supported %d slots", slot, slots)
+		} else if act == Activate && slot == 0 {
+			return fmt.Errorf("firmware slot should be specified for %v", act)
+		} else if slot == 1 && act.replaces() && frmw&frmwSlot1RO != 0 {
+			return fmt.Errorf("firmware slot 1 is read only")
+		} else if act == ActivateImmediate && frmw&frmwNoReset == 0 {
+			return fmt.Errorf("firmware activation without reset is not supported")
+		}
+	case BootPartition, BootPartitionActive:
+		if bpid > 1 {
+			return fmt.Errorf("invalid boot partition identifier: %d", bpid)
+		}
+	default:
+		return fmt.Errorf("invalid commit action: %v", act)
+	}
+
+	return nil
+}
+
+// confirmCommit checks the Firmware Slot Information log page after the commit. The slot of the
+// deferred activation should be the next active slot, and the slot of the immediate activation
+// should be the active slot.
+func confirmCommit(info *getlog.FirmwareSlotInfo, slot uint8, act action, status commitStatus) error {
+	if slot == 0 || !act.activates() {
+		return nil
+	}
+
+	if act == ActivateImmediate && !status.ResetRequired() {
+		if active, _ := info.Active(); uint8(active) != slot {
+			return fmt.Errorf("firmware slot %d is not activated: active slot %d", slot, active)
+		}
+	} else if next, _ := info.Next(); uint8(next) != slot {
+		return fmt.Errorf("firmware slot %d is not pending for activation: next slot %d", slot, next)
+	}
+
+	return nil
+}
+
+// CommitFirmware commits the downloaded image or the image in the slot with the action. The slot
+// and the action are validated with the FRMW of the controller, so slot 1 is refused for the
+// replace actions if it is read only. The returned commitStatus tells which reset activates the
+// firmware, and the activation is confirmed through the Firmware Slot Information log page. The
+// bpid is the boot partition identifier only for the boot partition actions.
+//goland:noinspection GoExportedFuncWithUnexportedType
+func CommitFirmware(file *os.File, slot uint8, act action, bpid uint8) (commitStatus, error) {
+	idCtrl := identify.CtrlIdentify{}
+	if err := identify.GetCtrlIdentify(file, &idCtrl); err != nil {
+		return CommitSuccess, err
+	} else if err = validateCommit(uint8(idCtrl.FRMW), slot, act, bpid); err != nil {
+		return CommitSuccess, err
+	}
+
+	status, err := decodeCommitStatus(nvme.IOCtlAdminCmd(file, newCommitCmd(slot, act, bpid)))
+	if err != nil {
+		return status, err
+	}
+
+	info := getlog.FirmwareSlotInfo{}
+	if err = getlog.GetFirmwareSlotInfo(file, &info); err != nil {
+		return status, err
+	}
+
+	return status, confirmCommit(&info, slot, act, status)
+}
@@ -0,0 +1,128 @@
+package firmware
+
+import (
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/sungup/go-nvmecli/pkg/nvme"
+	"github.com/sungup/go-nvmecli/pkg/nvme/getlog"
+	"testing"
+)
+
+func TestAction_String(t *testing.T) {
+	a := assert.New(t)
+
+	a.Equal("Replace", Replace.String())
+	a.Equal("Replace and Activate", ReplaceActivate.String())
+	a.Equal("Activate", Activate.String())
+	a.Equal("Activate Immediately", ActivateImmediate.String())
+	a.Equal("Replace Boot Partition", BootPartition.String())
+	a.Equal("Activate Boot Partition", BootPartitionActive.String())
+	a.Equal("Reserved (4h)", action(4).String())
+}
+
+func TestCommitStatus(t *testing.T) {
+	a := assert.New(t)
+
+	a.Equal("Success", CommitSuccess.String())
+	a.Equal("Conventional Reset Required", CommitConventionalRst.String())
+	a.Equal("NVM Subsystem Reset Required", CommitSubsystemRst.String())
+	a.Equal("Controller Level Reset Required", CommitControllerRst.String())
+	a.Equal("Maximum Time Violation", CommitMaxTimeViolation.String())
+	a.Equal("Unknown (06h)", commitStatus(6).String())
+
+	a.False(CommitSuccess.ResetRequired())
+	a.True(CommitSubsystemRst.ResetRequired())
+	a.True(CommitMaxTimeViolation.ResetRequired())
+}
+
+func TestDecodeCommitStatus(t *testing.T) {
+	a := assert.New(t)
+
+	cmdSpecific := func(sc uint8) error {
+		return fmt.Errorf("wrapped: %w", &nvme.CompletionError{OpCode: nvme.AdminActivateFW, Status: 1<<8 | uint16(sc)})
+	}
+
+	tested, err := decodeCommitStatus(nil)
+	a.NoError(err)
+	a.Equal(CommitSuccess, tested)
+
+	for _, s := range []commitStatus{CommitConventionalRst, CommitSubsystemRst, CommitControllerRst} {
+		tested, err = decodeCommitStatus(cmdSpecific(uint8(s)))
+		a.NoError(err)
+		a.Equal(s, tested)
+	}
+
+	tested, err = decodeCommitStatus(cmdSpecific(uint8(CommitMaxTimeViolation)))
+	a.Error(err)
+	a.Equal(CommitMaxTimeViolation, tested)
+
+	// invalid firmware slot
+	_, err = decodeCommitStatus(cmdSpecific(0x06))
+	a.Error(err)
+
+	// the same status code of the generic status is not a commit status
+	_, err = decodeCommitStatus(&nvme.CompletionError{OpCode: nvme.AdminActivateFW, Status: 0x0B})
+	a.Error(err)
+
+	_, err = decodeCommitStatus(errors.New("ioctl failed"))
+	a.Error(err)
+}
+
+func TestNewCommitCmd(t *testing.T) {
+	a := assert.New(t)
+
+	tested := newCommitCmd(2, ReplaceActivate, 0)
+	a.Equal(nvme.AdminActivateFW, tested.OpCode)
+	a.Equal(uint32(1<<3|2), tested.CDW10)
+	a.Zero(tested.DataLength)
+
+	tested = newCommitCmd(0, BootPartitionActive, 1)
+	a.Equal(uint32(1<<31|7<<3), tested.CDW10)
+}
+
+func TestValidateCommit(t *testing.T) {
+	a := assert.New(t)
+
+	frmw := uint8(3<<frmwSlotsShift | frmwNoReset)
+
+	a.NoError(validateCommit(frmw, 0, Replace, 0))
+	a.NoError(validateCommit(frmw, 1, ReplaceActivate, 0))
+	a.NoError(validateCommit(frmw, 3, ActivateImmediate, 0))
+	a.NoError(validateCommit(frmw, 2, Activate, 0))
+	a.NoError(validateCommit(frmw, 0, BootPartition, 1))
+
+	// slot 1 is read only, but it can be activated
+	a.Error(validateCommit(frmw|frmwSlot1RO, 1, Replace, 0))
+	a.Error(validateCommit(frmw|frmwSlot1RO, 1, ReplaceActivate, 0))
+	a.Error(validateCommit(frmw|frmwSlot1RO, 1, ActivateImmediate, 0))
+	a.NoError(validateCommit(frmw|frmwSlot1RO, 1, Activate, 0))
+	a.NoError(validateCommit(frmw|frmwSlot1RO, 2, Replace, 0))
+
+	// invalid slots and options
+	a.Error(validateCommit(frmw, 4, Replace, 0))
+	a.Error(validateCommit(frmw, 0, Activate, 0))
+	a.Error(validateCommit(frmw, 1, Replace, 1))
+	a.Error(validateCommit(frmw&^frmwNoReset, 1, ActivateImmediate, 0))
+	a.Error(validateCommit(frmw, 0, BootPartition, 2))
+	a.Error(validateCommit(frmw, 1, action(4), 0))
+}
+
+func TestConfirmCommit(t *testing.T) {
+	a := assert.New(t)
+
+	// slot 1 is active, and slot 2 will be activated at the next reset
+	info := getlog.FirmwareSlotInfo{ActiveFwInfo: 2<<4 | 1}
+
+	a.NoError(confirmCommit(&info, 2, ReplaceActivate, CommitConventionalRst))
+	a.NoError(confirmCommit(&info, 2, Activate, CommitSuccess))
+	a.Error(confirmCommit(&info, 3, Activate, CommitSuccess))
+
+	a.NoError(confirmCommit(&info, 1, ActivateImmediate, CommitSuccess))
+	a.Error(confirmCommit(&info, 2, ActivateImmediate, CommitSuccess))
+	a.NoError(confirmCommit(&info, 2, ActivateImmediate, CommitSubsystemRst))
+
+	// nothing to confirm
+	a.NoError(confirmCommit(&info, 3, Replace, CommitSuccess))
+	a.NoError(confirmCommit(&info, 0, ReplaceActivate, CommitSuccess))
+}
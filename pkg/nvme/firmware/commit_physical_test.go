@@ -0,0 +1,43 @@
+// +build with_phys_device
+
+package firmware
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/sungup/go-nvmecli/pkg/nvme/getlog"
+	"os"
+	"testing"
+)
+
+func TestCommitFirmware(t *testing.T) {
+	// TODO re-verify this test code using the NVMe device prepared for the firmware update
+	// Activating the firmware slot changes the running firmware at the next reset, so this test
+	// should run only on the device prepared for it.
+	/*
+		a := assert.New(t)
+
+		dev, _ := os.Open(targetDevice)
+
+		info := getlog.FirmwareSlotInfo{}
+		a.NoError(getlog.GetFirmwareSlotInfo(dev, &info))
+
+		active, _ := info.Active()
+
+		status, err := CommitFirmware(dev, uint8(active), Activate, 0)
+		a.NoError(err)
+		t.Log(status)
+	*/
+}
+
+func TestConfirmCommitWithDevice(t *testing.T) {
+	a := assert.New(t)
+
+	dev, _ := os.Open(targetDevice)
+
+	info := getlog.FirmwareSlotInfo{}
+	a.NoError(getlog.GetFirmwareSlotInfo(dev, &info))
+
+	// the active slot is confirmed as activated without reset
+	active, _ := info.Active()
+	a.NoError(confirmCommit(&info, uint8(active), ActivateImmediate, CommitSuccess))
+}
@@ -0,0 +1,131 @@
+package firmware
+
+import (
+	"fmt"
+	"github.com/sungup/go-nvmecli/pkg/nvme"
+	"github.com/sungup/go-nvmecli/pkg/nvme/identify"
+	"io"
+	"os"
+)
+
+const (
+	// fwugUnit is the unit of FWUG, and it is also the granularity if FWUG is not reported.
+	fwugUnit = 4096
+
+	// fwugNoRestriction is the FWUG value which means no restriction on the granularity.
+	fwugNoRestriction = 0xFF
+
+	// maxChunkSz is the maximum size of a download command if MDTS has no limitation. The kernel
+	// limits the passthru data size by its max_hw_sectors, so a moderate size is used.
+	maxChunkSz = 128 * 1024
+
+	// minMemPageShift is the shift of CAP.MPSMIN 0h, the minimum memory page size is 2^(12+MPSMIN).
+	minMemPageShift = 12
+)
+
+// newDownloadCmd generates an AdminCmd structure to download a chunk of the firmware image at the
+// dword aligned offset.
+func newDownloadCmd(offset int64, chunk []byte) (*nvme.AdminCmd, error) {
+	cmd := nvme.AdminCmd{
+		PassthruCmd: nvme.PassthruCmd{
+			OpCode: nvme.AdminDownloadFW,
+			CDW10:  uint32(len(chunk)>>2) - 1, // NUMD is a 0's based value
+			CDW11:  uint32(offset >> 2),
+		},
+		TimeoutMSec: 0,
+		Result:      0,
+	}
+
+	if err := cmd.SetData(chunk); err != nil {
+		return nil, err
+	} else {
+		return &cmd, nil
+	}
+}
+
+// chunkSize returns the size of a download command from FWUG and MDTS. The size is the largest
+// multiple of the update granularity which is not larger than the maximum data transfer size.
+func chunkSize(fwug, mdts uint8) (int, error) {
+	granularity := fwugUnit
+	if fwug != 0 && fwug != fwugNoRestriction {
+		granularity = int(fwug) * fwugUnit
+	}
+
+	// MDTS is reported in units of the minimum memory page size and 0h means no limitation
+	mdtsLimit := 0
+	if shift := int(mdts) + minMemPageShift; mdts != 0 && shift < 31 {
+		mdtsLimit = 1 << shift
+	}
+
+	limit := maxChunkSz
+	if mdtsLimit != 0 && mdtsLimit < limit {
+		limit = mdtsLimit
+	}
+
+	// the granularity larger than the default chunk size is allowed only within MDTS
+	if granularity > limit {
+		if mdtsLimit != 0 && granularity > mdtsLimit {
+			return 0, fmt.Errorf("firmware update granularity %dB is larger than MDTS %dB", granularity, mdtsLimit)
+		}
+
+		return granularity, nil
+	}
+
+	return limit / granularity * granularity, nil
+}
+
+// download writes the firmware image into the controller by the chunk sized download commands.
+func download(file *os.File, image io.Reader, chunk int, progress func(downloaded int64)) (int64, error) {
+	buffer := make([]byte, chunk)
+	offset := int64(0)
+
+	for {
+		n, err := io.ReadFull(image, buffer)
+		if err == io.EOF {
+			break
+		} else if err != nil && err != io.ErrUnexpectedEOF {
+			return offset, err
+		} else if n%4 != 0 {
+			return offset, fmt.Errorf("firmware image size is not dword aligned: %d", offset+int64(n))
+		}
+
+		if cmd, err := newDownloadCmd(offset, buffer[:n]); err != nil {
+			return offset, err
+		} else if err = nvme.IOCtlAdminCmd(file, cmd); err != nil {
+			return offset, fmt.Errorf("firmware download failed at offset %d: %w", offset, err)
+		}
+
+		offset += int64(n)
+
+		if progress != nil {
+			progress(offset)
+		}
+
+		if n < chunk {
+			break
+		}
+	}
+
+	if offset == 0 {
+		return 0, fmt.Errorf("empty firmware image")
+	}
+
+	return offset, nil
+}
+
+// DownloadFirmware downloads the firmware image into the controller. The image is split by the
+// firmware update granularity (FWUG) and the maximum data transfer size (MDTS), and progress is
+// called with the downloaded bytes after each chunk if it is not nil. The downloaded image is
+// applied by CommitFirmware, and DownloadFirmware returns the total downloaded bytes.
+func DownloadFirmware(file *os.File, image io.Reader, progress func(downloaded int64)) (int64, error) {
+	idCtrl := identify.CtrlIdentify{}
+	if err := identify.GetCtrlIdentify(file, &idCtrl); err != nil {
+		return 0, err
+	}
+
+	if chunk, err := chunkSize(uint8(idCtrl.FWUG), uint8(idCtrl.MDTS)); err != nil {
+		return 0, err
+	} else {
+		return download(file, image, chunk, progress)
+	}
+}
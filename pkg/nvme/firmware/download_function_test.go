@@ -0,0 +1,46 @@
+package firmware
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/sungup/go-nvmecli/pkg/nvme"
+	"testing"
+)
+
+func TestNewDownloadCmd(t *testing.T) {
+	a := assert.New(t)
+
+	chunk := make([]byte, 8192)
+
+	tested, err := newDownloadCmd(16384, chunk)
+	a.NoError(err)
+	a.Equal(nvme.AdminDownloadFW, tested.OpCode)
+	a.Equal(uint32(2047), tested.CDW10)
+	a.Equal(uint32(4096), tested.CDW11)
+	a.Equal(uint32(len(chunk)), tested.DataLength)
+}
+
+func TestChunkSize(t *testing.T) {
+	a := assert.New(t)
+
+	for _, tc := range []struct {
+		fwug, mdts uint8
+		expected   int
+	}{
+		{fwug: 0, mdts: 0, expected: maxChunkSz},
+		{fwug: fwugNoRestriction, mdts: 0, expected: maxChunkSz},
+		{fwug: 0, mdts: 1, expected: 8192},
+		{fwug: 3, mdts: 0, expected: 120 * 1024},
+		{fwug: 3, mdts: 3, expected: 24 * 1024},
+		{fwug: 64, mdts: 0, expected: 256 * 1024},
+		{fwug: 64, mdts: 6, expected: 256 * 1024},
+		{fwug: 1, mdts: 30, expected: maxChunkSz},
+	} {
+		tested, err := chunkSize(tc.fwug, tc.mdts)
+		a.NoError(err)
+		a.Equal(tc.expected, tested, tc)
+	}
+
+	// the granularity is larger than MDTS
+	_, err := chunkSize(4, 1)
+	a.Error(err)
+}
@@ -0,0 +1,41 @@
+// +build with_phys_device
+
+package firmware
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/sungup/go-nvmecli/pkg/nvme/identify"
+	"os"
+	"testing"
+)
+
+func TestChunkSizeWithDevice(t *testing.T) {
+	a := assert.New(t)
+
+	dev, _ := os.Open(targetDevice)
+
+	idCtrl := identify.CtrlIdentify{}
+	a.NoError(identify.GetCtrlIdentify(dev, &idCtrl))
+
+	tested, err := chunkSize(uint8(idCtrl.FWUG), uint8(idCtrl.MDTS))
+	a.NoError(err)
+	a.Zero(tested % fwugUnit)
+}
+
+func TestDownloadFirmware(t *testing.T) {
+	// TODO re-verify this test code using the firmware image of the target NVMe device
+	// Downloading the firmware image of other devices may make the device unusable after the
+	// commit, so this test should run only with the image prepared for the device.
+	/*
+		a := assert.New(t)
+
+		dev, _ := os.Open(targetDevice)
+
+		image, _ := os.Open("firmware.bin")
+		info, _ := image.Stat()
+
+		tested, err := DownloadFirmware(dev, image, func(downloaded int64) { t.Log(downloaded, info.Size()) })
+		a.NoError(err)
+		a.Equal(info.Size(), tested)
+	*/
+}
@@ -0,0 +1,5 @@
+package firmware
+
+const (
+	expectedNSId = 1
+)
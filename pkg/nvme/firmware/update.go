@@ -0,0 +1,339 @@
+package firmware
+
+import (
+	"errors"
+	"fmt"
+	"github.com/sungup/go-nvmecli/pkg/nvme"
+	"github.com/sungup/go-nvmecli/pkg/nvme/getlog"
+	"github.com/sungup/go-nvmecli/pkg/nvme/identify"
+	"io"
+	"os"
+	"time"
+)
+
+// step is a step of the firmware update workflow.
+type step string
+
+const (
+	StepSnapshot = step("snapshot") // the current active slot and revision are recorded
+	StepPrecheck = step("precheck") // the target slot and action are allowed by FRMW
+	StepDownload = step("download") // a chunk of the image is downloaded
+	StepCommit   = step("commit")   // the image is committed into the target slot
+	StepReset    = step("reset")    // the reset required by the commit is done
+	StepRescan   = step("rescan")   // the namespaces are rescanned after the reset
+	StepVerify   = step("verify")   // the expected revision is active
+	StepRollback = step("rollback") // the previous slot is activated again
+	StepDone     = step("done")     // the update is completed
+)
+
+const (
+	// DefaultVerifyTimeout is the time to wait for the controller after the reset if
+	// Policy.Timeout is 0.
+	DefaultVerifyTimeout = time.Minute
+
+	verifyInterval = time.Second
+)
+
+// ErrManualReset is returned if the committed image is activated only by a reset which Update
+// can't perform, like a conventional reset without the NVM subsystem reset support. The image is
+// activated by the power cycle or the reset done by the operator.
+var ErrManualReset = errors.New("manual reset is required to activate the firmware")
+
+// Event is a structured progress of the firmware update. The Slot and Revision of StepSnapshot are
+// the previous active firmware, and the ones of the other steps are the target firmware. Error is
+// not empty if the step has failed.
+type Event struct {
+	Step       step   `json:"step"`
+	Slot       uint8  `json:"slot,omitempty"`
+	Revision   string `json:"revision,omitempty"`
+	Downloaded int64  `json:"downloaded,omitempty"`
+	Status     string `json:"status,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// Policy is the options of the firmware update workflow.
+type Policy struct {
+	// Slot is the target firmware slot, and 0 lets the controller choose the slot.
+	Slot uint8
+
+	// Action is the commit action, and it should be ReplaceActivate or ActivateImmediate.
+	Action action
+
+	// Revision is the expected firmware revision after the update. If it is empty, the revision
+	// in the target slot after the commit is expected.
+	Revision string
+
+	// Timeout is the time to wait for the expected revision after the reset, and
+	// DefaultVerifyTimeout is used if it is 0.
+	Timeout time.Duration
+
+	// Events is called at each step of the workflow if it is not nil.
+	Events func(Event)
+}
+
+// validate checks the policy itself without the controller capabilities.
+func (p *Policy) validate() error {
+	if p.Action != ReplaceActivate && p.Action != ActivateImmediate {
+		return fmt.Errorf("firmware update doesn't activate the image with %v", p.Action)
+	}
+
+	return nil
+}
+
+// emit calls the Events callback with the step information.
+func (p *Policy) emit(e Event, err error) {
+	if err != nil {
+		e.Error = err.Error()
+	}
+
+	if p.Events != nil {
+		p.Events(e)
+	}
+}
+
+// checkActive returns an error if the slot and the revision are not active.
+func checkActive(info *getlog.FirmwareSlotInfo, slot uint8, revision string) error {
+	if active, rev := info.Active(); uint8(active) != slot || rev != revision {
+		return fmt.Errorf("slot %d (%s) is active instead of slot %d (%s)", active, rev, slot, revision)
+	}
+
+	return nil
+}
+
+// slotRevision returns the firmware revision in the slot.
+func slotRevision(info *getlog.FirmwareSlotInfo, slot uint8) string {
+	for s := getlog.FRS1; s <= getlog.FRS7; s++ {
+		if uint8(s) == slot {
+			return info.Slot(s)
+		}
+	}
+
+	return ""
+}
+
+// checkRollback returns an error if the previous firmware can't be activated again. The image
+// committed into the previous active slot has overwritten the previous firmware.
+func checkRollback(slot, prevSlot uint8) error {
+	if slot == prevSlot {
+		return fmt.Errorf("rollback is impossible because the previous firmware in slot %d is overwritten", prevSlot)
+	}
+
+	return nil
+}
+
+// waitActive polls the Firmware Slot Information log page until the slot and the revision are
+// active. The controller may not respond right after the reset, so the errors are retried until
+// the timeout.
+func waitActive(file *os.File, slot uint8, revision string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+
+	for {
+		info := getlog.FirmwareSlotInfo{}
+
+		err := getlog.GetFirmwareSlotInfo(file, &info)
+		if err == nil {
+			if err = checkActive(&info, slot, revision); err == nil {
+				return nil
+			}
+		}
+
+		if time.Now().After(deadline) {
+			return err
+		}
+
+		time.Sleep(verifyInterval)
+	}
+}
+
+// resetNeeded returns true if the committed image is activated by a reset. The image committed by
+// ReplaceActivate or Activate is activated at the next reset even if the commit succeeds, and the
+// image of ActivateImmediate needs a reset only if the commit status requires it.
+func resetNeeded(act action, status commitStatus) bool {
+	switch act {
+	case ReplaceActivate, Activate:
+		return true
+	case ActivateImmediate:
+		return status.ResetRequired()
+	default:
+		return false
+	}
+}
+
+// subsystemReset returns true if the commit status requires the NVM subsystem reset instead of the
+// controller reset. The conventional reset is done by the NVM subsystem reset, so both need the
+// NVM subsystem reset support (CAP.NSSRS). Otherwise ErrManualReset is returned.
+func subsystemReset(status commitStatus, nssrs bool) (bool, error) {
+	switch status {
+	case CommitSubsystemRst, CommitConventionalRst:
+		if !nssrs {
+			return false, fmt.Errorf("%w: %v but NVM subsystem reset is not supported (CAP.NSSRS)", ErrManualReset, status)
+		}
+
+		return true, nil
+	default:
+		return false, nil
+	}
+}
+
+// reset performs the reset to activate the committed image and rescans the namespaces.
+func reset(file *os.File, act action, status commitStatus, p *Policy, slot uint8, revision string) error {
+	if !resetNeeded(act, status) {
+		return nil
+	}
+
+	nssrs := false
+	if status == CommitSubsystemRst || status == CommitConventionalRst {
+		var err error
+		if nssrs, err = nvme.SubsysResetSupported(file); err != nil {
+			p.emit(Event{Step: StepReset, Slot: slot, Revision: revision, Status: status.String()}, err)
+			return err
+		}
+	}
+
+	subsystem, err := subsystemReset(status, nssrs)
+	if err == nil {
+		if subsystem {
+			err = nvme.IOCtlSubsysReset(file)
+		} else {
+			err = nvme.IOCtlReset(file)
+		}
+	}
+
+	p.emit(Event{Step: StepReset, Slot: slot, Revision: revision, Status: status.String()}, err)
+	if err != nil {
+		return err
+	}
+
+	err = nvme.IOCtlRescan(file)
+	p.emit(Event{Step: StepRescan, Slot: slot, Revision: revision}, err)
+
+	return err
+}
+
+// activate commits the slot with the action and performs the reset to activate it. If the
+// immediate activation violates MTFA, the slot is activated again by a reset.
+func activate(file *os.File, slot uint8, act action, p *Policy) (uint8, string, error) {
+	status, err := CommitFirmware(file, slot, act, 0)
+	if status == CommitMaxTimeViolation && slot != 0 {
+		p.emit(Event{Step: StepCommit, Slot: slot, Status: status.String()}, err)
+		act = Activate
+		status, err = CommitFirmware(file, slot, act, 0)
+	}
+
+	if err != nil {
+		p.emit(Event{Step: StepCommit, Slot: slot, Status: status.String()}, err)
+		return slot, "", err
+	}
+
+	// the slot chosen by the controller is the next or the active slot
+	info := getlog.FirmwareSlotInfo{}
+	if err = getlog.GetFirmwareSlotInfo(file, &info); err != nil {
+		return slot, "", err
+	}
+
+	if slot == 0 {
+		if next, _ := info.Next(); next != getlog.NoFw {
+			slot = uint8(next)
+		} else {
+			active, _ := info.Active()
+			slot = uint8(active)
+		}
+	}
+
+	revision := slotRevision(&info, slot)
+
+	p.emit(Event{Step: StepCommit, Slot: slot, Revision: revision, Status: status.String()}, nil)
+
+	return slot, revision, reset(file, act, status, p, slot, revision)
+}
+
+// Update downloads the firmware image, commits it into the target slot and performs the reset
+// required to activate it. Before the download, the current active slot is recorded and the
+// target slot and the action are checked with FRMW. After the reset, Update waits until the
+// expected revision is active, and re-activates the previous slot if it isn't. Each step is
+// reported through Policy.Events, so the caller can log the progress and resume from the failed
+// step. If the target slot is the active slot, the previous image is overwritten, so the rollback
+// is skipped and the error says it is impossible. If the reset can't be done by Update,
+// ErrManualReset is returned after the commit without the verification.
+func Update(file *os.File, image io.Reader, p Policy) error {
+	// 1. record the current active firmware
+	info := getlog.FirmwareSlotInfo{}
+	err := getlog.GetFirmwareSlotInfo(file, &info)
+
+	prevSlot, prevRev := info.Active()
+	p.emit(Event{Step: StepSnapshot, Slot: uint8(prevSlot), Revision: prevRev}, err)
+	if err != nil {
+		return err
+	}
+
+	// 2. check the target slot and the action
+	idCtrl := identify.CtrlIdentify{}
+	if err = p.validate(); err == nil {
+		if err = identify.GetCtrlIdentify(file, &idCtrl); err == nil {
+			err = validateCommit(uint8(idCtrl.FRMW), p.Slot, p.Action, 0)
+		}
+	}
+
+	p.emit(Event{Step: StepPrecheck, Slot: p.Slot}, err)
+	if err != nil {
+		return err
+	}
+
+	// 3. download the image
+	downloaded, err := DownloadFirmware(file, image, func(downloaded int64) {
+		p.emit(Event{Step: StepDownload, Slot: p.Slot, Downloaded: downloaded}, nil)
+	})
+	if err != nil {
+		p.emit(Event{Step: StepDownload, Slot: p.Slot, Downloaded: downloaded}, err)
+		return err
+	}
+
+	// 4. commit and reset
+	slot, revision, err := activate(file, p.Slot, p.Action, &p)
+	if err != nil && revision == "" {
+		// the commit has failed, so the previous firmware is still active
+		return err
+	} else if errors.Is(err, ErrManualReset) {
+		// the image is committed, and it is activated by the reset of the operator
+		return err
+	}
+
+	if p.Revision != "" {
+		revision = p.Revision
+	}
+
+	// 5. verify the active revision
+	timeout := p.Timeout
+	if timeout == 0 {
+		timeout = DefaultVerifyTimeout
+	}
+
+	if err == nil {
+		err = waitActive(file, slot, revision, timeout)
+	}
+
+	p.emit(Event{Step: StepVerify, Slot: slot, Revision: revision}, err)
+	if err == nil {
+		p.emit(Event{Step: StepDone, Slot: slot, Revision: revision}, nil)
+		return nil
+	}
+
+	// 6. roll back to the previous firmware
+	verifyErr := err
+
+	if err = checkRollback(slot, uint8(prevSlot)); err != nil {
+		p.emit(Event{Step: StepRollback, Slot: uint8(prevSlot), Revision: prevRev}, err)
+		return fmt.Errorf("firmware update failed: %v, and %v", verifyErr, err)
+	}
+
+	if _, _, err = activate(file, uint8(prevSlot), Activate, &p); err == nil {
+		err = waitActive(file, uint8(prevSlot), prevRev, timeout)
+	}
+
+	p.emit(Event{Step: StepRollback, Slot: uint8(prevSlot), Revision: prevRev}, err)
+	if err != nil {
+		return fmt.Errorf("firmware update failed: %v, and rollback failed: %v", verifyErr, err)
+	}
+
+	return fmt.Errorf("firmware update failed and rolled back to slot %d: %v", prevSlot, verifyErr)
+}
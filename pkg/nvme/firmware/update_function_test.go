@@ -0,0 +1,130 @@
+package firmware
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/sungup/go-nvmecli/pkg/nvme/getlog"
+	"testing"
+)
+
+// testSlotInfo returns the firmware slot information of which slot 1 has "FW01" and slot 2 has
+// "FW02" and is active.
+func testSlotInfo() *getlog.FirmwareSlotInfo {
+	raw := make([]byte, 512)
+	raw[0] = 2
+	copy(raw[8:], "FW01")
+	copy(raw[16:], "FW02")
+
+	info, _ := getlog.ParseFirmwareSlotInfo(raw)
+
+	return info
+}
+
+func TestPolicy_validate(t *testing.T) {
+	a := assert.New(t)
+
+	a.NoError((&Policy{Action: ReplaceActivate}).validate())
+	a.NoError((&Policy{Action: ActivateImmediate}).validate())
+
+	a.Error((&Policy{}).validate())
+	a.Error((&Policy{Action: Activate}).validate())
+	a.Error((&Policy{Action: BootPartition}).validate())
+}
+
+func TestResetNeeded(t *testing.T) {
+	a := assert.New(t)
+
+	// the deferred activations need a reset even if the commit succeeds
+	a.True(resetNeeded(ReplaceActivate, CommitSuccess))
+	a.True(resetNeeded(Activate, CommitSuccess))
+	a.True(resetNeeded(Activate, CommitSubsystemRst))
+
+	// the immediate activation needs a reset only if the status requires it
+	a.False(resetNeeded(ActivateImmediate, CommitSuccess))
+	a.True(resetNeeded(ActivateImmediate, CommitConventionalRst))
+	a.True(resetNeeded(ActivateImmediate, CommitControllerRst))
+
+	// the image is not activated
+	a.False(resetNeeded(Replace, CommitSuccess))
+}
+
+func TestSubsystemReset(t *testing.T) {
+	a := assert.New(t)
+
+	// the conventional reset and the NVM subsystem reset are done by the NVM subsystem reset
+	for _, status := range []commitStatus{CommitConventionalRst, CommitSubsystemRst} {
+		subsystem, err := subsystemReset(status, true)
+		a.NoError(err)
+		a.True(subsystem)
+
+		_, err = subsystemReset(status, false)
+		a.ErrorIs(err, ErrManualReset)
+	}
+
+	// the controller reset doesn't need CAP.NSSRS
+	for _, status := range []commitStatus{CommitSuccess, CommitControllerRst, CommitMaxTimeViolation} {
+		subsystem, err := subsystemReset(status, false)
+		a.NoError(err)
+		a.False(subsystem)
+	}
+}
+
+func TestCheckRollback(t *testing.T) {
+	a := assert.New(t)
+
+	a.NoError(checkRollback(3, 2))
+	a.EqualError(checkRollback(2, 2), "rollback is impossible because the previous firmware in slot 2 is overwritten")
+}
+
+func TestPolicy_emit(t *testing.T) {
+	a := assert.New(t)
+
+	events := make([]Event, 0)
+	p := Policy{Events: func(e Event) { events = append(events, e) }}
+
+	p.emit(Event{Step: StepDownload, Slot: 2, Downloaded: 4096}, nil)
+	p.emit(Event{Step: StepCommit, Slot: 2, Status: CommitSuccess.String()}, errors.New("failed"))
+
+	a.Equal([]Event{
+		{Step: StepDownload, Slot: 2, Downloaded: 4096},
+		{Step: StepCommit, Slot: 2, Status: "Success", Error: "failed"},
+	}, events)
+
+	// events without callback are ignored
+	(&Policy{}).emit(Event{Step: StepDone}, nil)
+}
+
+func TestEvent_JSON(t *testing.T) {
+	a := assert.New(t)
+
+	tested, err := json.Marshal(Event{Step: StepReset, Slot: 2, Revision: "FW02", Status: "Conventional Reset Required"})
+	a.NoError(err)
+	a.JSONEq(`{"step": "reset", "slot": 2, "revision": "FW02", "status": "Conventional Reset Required"}`, string(tested))
+
+	tested, err = json.Marshal(Event{Step: StepDownload, Downloaded: 8192, Error: "failed"})
+	a.NoError(err)
+	a.JSONEq(`{"step": "download", "downloaded": 8192, "error": "failed"}`, string(tested))
+}
+
+func TestSlotRevision(t *testing.T) {
+	a := assert.New(t)
+
+	info := testSlotInfo()
+
+	a.Equal("FW01", slotRevision(info, 1))
+	a.Equal("FW02", slotRevision(info, 2))
+	a.Empty(slotRevision(info, 3))
+	a.Empty(slotRevision(info, 0))
+	a.Empty(slotRevision(info, 8))
+}
+
+func TestCheckActive(t *testing.T) {
+	a := assert.New(t)
+
+	info := testSlotInfo()
+
+	a.NoError(checkActive(info, 2, "FW02"))
+	a.Error(checkActive(info, 1, "FW01"))
+	a.Error(checkActive(info, 2, "FW03"))
+}
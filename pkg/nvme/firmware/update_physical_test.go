@@ -0,0 +1,43 @@
+// +build with_phys_device
+
+package firmware
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/sungup/go-nvmecli/pkg/nvme/getlog"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestWaitActive(t *testing.T) {
+	a := assert.New(t)
+
+	dev, _ := os.Open(targetDevice)
+
+	info := getlog.FirmwareSlotInfo{}
+	a.NoError(getlog.GetFirmwareSlotInfo(dev, &info))
+
+	active, revision := info.Active()
+	a.NoError(waitActive(dev, uint8(active), revision, time.Second))
+	a.Error(waitActive(dev, uint8(active), revision+"X", time.Second))
+}
+
+func TestUpdate(t *testing.T) {
+	// TODO re-verify this test code using the firmware image of the target NVMe device
+	// The firmware update resets the controller and changes the running firmware, so this test
+	// should run only on the device prepared for it.
+	/*
+		a := assert.New(t)
+
+		dev, _ := os.Open(targetDevice)
+
+		image, _ := os.Open("firmware.bin")
+
+		a.NoError(Update(dev, image, Policy{
+			Slot:   2,
+			Action: ReplaceActivate,
+			Events: func(e Event) { t.Log(e) },
+		}))
+	*/
+}
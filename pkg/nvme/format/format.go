@@ -0,0 +1,267 @@
+package format
+
+import (
+	"fmt"
+	"github.com/sungup/go-nvmecli/pkg/nvme"
+	"github.com/sungup/go-nvmecli/pkg/nvme/identify"
+	"math"
+	"os"
+	"sync"
+	"time"
+)
+
+// ses is the Secure Erase Settings (SES) field of the Format NVM command.
+type ses uint8
+
+const (
+	NoSecureErase = ses(0x0)
+	UserDataErase = ses(0x1)
+	CryptoErase   = ses(0x2)
+)
+
+// piType is the Protection Information (PI) field of the Format NVM command.
+type piType uint8
+
+const (
+	NoPI    = piType(0x0)
+	PIType1 = piType(0x1)
+	PIType2 = piType(0x2)
+	PIType3 = piType(0x3)
+)
+
+const (
+	// AllNamespaces is the broadcast NSID to format all namespaces attached to the controller.
+	AllNamespaces = uint32(math.MaxUint32)
+
+	// FNA bits of the Identify Controller data structure.
+	fnaFormatAll = 1 << 0 // format applies to all namespaces
+	fnaEraseAll  = 1 << 1 // secure erase applies to all namespaces
+	fnaCrypto    = 1 << 2 // cryptographic erase is supported
+
+	// MC bits of the Identify Namespace data structure.
+	mcExtended = uint8(1 << 0) // metadata transferred as part of an extended data LBA
+	mcSeparate = uint8(1 << 1) // metadata transferred in a separate buffer
+
+	// DPC bits of the Identify Namespace data structure.
+	dpcFirst = uint8(1 << 3) // PI transferred as the first bytes of metadata
+	dpcLast  = uint8(1 << 4) // PI transferred as the last bytes of metadata
+
+	// piSize is the size of the protection information in the metadata.
+	piSize = 8
+
+	// DefaultTimeout is the command timeout if Options.Timeout is 0. Format NVM with the secure
+	// erase takes minutes on the large capacity devices.
+	DefaultTimeout = 10 * time.Minute
+
+	// pollInterval is the interval to retrieve the Format Progress Indicator while formatting.
+	pollInterval = time.Second
+)
+
+// Options is the optional fields of the Format NVM command. The zero value formats without the
+// secure erase and the protection information.
+type Options struct {
+	SES  ses    // Secure Erase Settings
+	PI   piType // Protection Information type
+	PIL  bool   // Protection Information Location, true to transfer PI as the first bytes of metadata
+	MSET bool   // Metadata Settings, true to transfer metadata as a part of an extended data LBA
+
+	// Timeout is the command timeout, and DefaultTimeout is used if it is 0.
+	Timeout time.Duration
+
+	// Progress is called with the percentage of the completed format operation while formatting,
+	// if it is not nil and the namespace supports the Format Progress Indicator.
+	Progress func(percent uint8)
+}
+
+// newFormatCmd generates an AdminCmd structure to format the namespace with the LBA format index.
+func newFormatCmd(nsid uint32, lbaf uint8, opts Options) *nvme.AdminCmd {
+	cdw10 := uint32(lbaf)&0x0F | uint32(opts.PI&0x07)<<5 | uint32(opts.SES&0x07)<<9
+
+	if opts.MSET {
+		cdw10 |= 1 << 4
+	}
+
+	if opts.PIL {
+		cdw10 |= 1 << 8
+	}
+
+	timeout := opts.Timeout
+	if timeout == 0 {
+		timeout = DefaultTimeout
+	}
+
+	return &nvme.AdminCmd{
+		PassthruCmd: nvme.PassthruCmd{
+			OpCode: nvme.AdminFormatNVM,
+			NSId:   nsid,
+			CDW10:  cdw10,
+		},
+		TimeoutMSec: uint32(timeout / time.Millisecond),
+		Result:      0,
+	}
+}
+
+// validate checks the options with the controller and the namespace capabilities.
+func validate(idCtrl *identify.CtrlIdentify, idNs *identify.NamespaceIdentify, nsid uint32, lbaf uint8, opts Options) error {
+	// 1. LBA format: NLBAF is a 0's based value
+	if lbaf > idNs.NLBAF || int(lbaf) >= len(idNs.LBAF) || idNs.LBAF[lbaf].LBADataSize() == 0 {
+		return fmt.Errorf("LBA format %d is not supported by the namespace", lbaf)
+	}
+
+	format := idNs.LBAF[lbaf]
+
+	// 2. secure erase and the scope of the format
+	fna := uint8(idCtrl.FNA)
+
+	switch opts.SES {
+	case NoSecureErase, UserDataErase:
+	case CryptoErase:
+		if fna&fnaCrypto == 0 {
+			return fmt.Errorf("cryptographic erase is not supported by the controller")
+		}
+	default:
+		return fmt.Errorf("invalid secure erase setting: %Xh", uint8(opts.SES))
+	}
+
+	if nsid != AllNamespaces {
+		if fna&fnaFormatAll != 0 {
+			return fmt.Errorf("format applies to all namespaces, and NSID should be %Xh", AllNamespaces)
+		} else if opts.SES != NoSecureErase && fna&fnaEraseAll != 0 {
+			return fmt.Errorf("secure erase applies to all namespaces, and NSID should be %Xh", AllNamespaces)
+		}
+	}
+
+	// 3. metadata settings
+	if format.MetadataSize() > 0 {
+		if opts.MSET && idNs.MC&mcExtended == 0 {
+			return fmt.Errorf("extended data LBA metadata is not supported by the namespace")
+		} else if !opts.MSET && idNs.MC&mcSeparate == 0 {
+			return fmt.Errorf("separate buffer metadata is not supported by the namespace")
+		}
+	}
+
+	// 4. protection information
+	if opts.PI == NoPI {
+		return nil
+	} else if opts.PI > PIType3 || idNs.DPC&(1<<(opts.PI-1)) == 0 {
+		return fmt.Errorf("protection information type %d is not supported by the namespace", opts.PI)
+	} else if format.MetadataSize() < piSize {
+		return fmt.Errorf("LBA format %d doesn't have the metadata for the protection information", lbaf)
+	} else if opts.PIL && idNs.DPC&dpcFirst == 0 {
+		return fmt.Errorf("protection information in the first bytes of metadata is not supported")
+	} else if !opts.PIL && idNs.DPC&dpcLast == 0 {
+		return fmt.Errorf("protection information in the last bytes of metadata is not supported")
+	}
+
+	return nil
+}
+
+// FindLBAF returns the best performance LBA format index of the namespace which has the LBA data
+// size and the metadata size in bytes. For example, FindLBAF(idNs, 4096, 0) finds the 4KiB LBA
+// format without metadata.
+func FindLBAF(idNs *identify.NamespaceIdentify, dataSize, metaSize int) (uint8, error) {
+	found := -1
+
+	for i := 0; i <= int(idNs.NLBAF) && i < len(idNs.LBAF); i++ {
+		format := idNs.LBAF[i]
+
+		if shift := format.LBADataSize(); shift == 0 || 1<<shift != dataSize || format.MetadataSize() != metaSize {
+			continue
+		}
+
+		if found < 0 || format.RelativePerformance() < idNs.LBAF[found].RelativePerformance() {
+			found = i
+		}
+	}
+
+	if found < 0 {
+		return 0, fmt.Errorf("no LBA format has %dB data and %dB metadata", dataSize, metaSize)
+	}
+
+	return uint8(found), nil
+}
+
+// Progress returns the percentage of the completed format operation of the namespace. If the
+// namespace doesn't support the Format Progress Indicator, Progress returns false.
+func Progress(file *os.File, nsid uint32) (uint8, bool, error) {
+	idNs := identify.NamespaceIdentify{}
+	if err := identify.GetNamespaceIdentify(file, nsid, &idNs); err != nil {
+		return 0, false, err
+	}
+
+	// FPI bit[7] indicates the support, and bit[6:0] is the percentage remained to be formatted
+	if idNs.FPI&(1<<7) == 0 {
+		return 0, false, nil
+	}
+
+	return 100 - idNs.FPI&0x7F, true, nil
+}
+
+// pollProgress reports the format progress until done is closed.
+func pollProgress(file *os.File, nsid uint32, report func(percent uint8), done <-chan struct{}) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+		}
+
+		// the controller may not respond to Identify while formatting, so errors are ignored
+		if percent, ok, err := Progress(file, nsid); err == nil && ok {
+			report(percent)
+		}
+	}
+}
+
+// Format formats the namespace with the LBA format index. The options are validated with the
+// Identify Controller and the Identify Namespace data structures before issuing the command. If
+// the format applies to all namespaces on this controller (FNA), nsid should be AllNamespaces to
+// avoid erasing the other namespaces unexpectedly, and the LBA format is validated with the first
+// active namespace.
+func Format(file *os.File, nsid uint32, lbaf uint8, opts Options) error {
+	idCtrl := identify.CtrlIdentify{}
+	if err := identify.GetCtrlIdentify(file, &idCtrl); err != nil {
+		return err
+	}
+
+	target := nsid
+	if nsid == AllNamespaces {
+		if active, err := identify.ListActiveNamespaces(file); err != nil {
+			return err
+		} else if len(active) == 0 {
+			return fmt.Errorf("no active namespace to format")
+		} else {
+			target = active[0]
+		}
+	}
+
+	idNs := identify.NamespaceIdentify{}
+	if err := identify.GetNamespaceIdentify(file, target, &idNs); err != nil {
+		return err
+	}
+
+	if err := validate(&idCtrl, &idNs, nsid, lbaf, opts); err != nil {
+		return err
+	}
+
+	if opts.Progress != nil {
+		done := make(chan struct{})
+		wg := sync.WaitGroup{}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			pollProgress(file, target, opts.Progress, done)
+		}()
+
+		defer func() {
+			close(done)
+			wg.Wait()
+		}()
+	}
+
+	return nvme.IOCtlAdminCmd(file, newFormatCmd(nsid, lbaf, opts))
+}
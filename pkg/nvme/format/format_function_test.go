@@ -0,0 +1,122 @@
+package format
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/sungup/go-nvmecli/pkg/nvme"
+	"github.com/sungup/go-nvmecli/pkg/nvme/identify"
+	"testing"
+	"time"
+)
+
+func testNamespace() *identify.NamespaceIdentify {
+	idNs := identify.NamespaceIdentify{
+		NLBAF: 3,
+		MC:    mcExtended | mcSeparate,
+		DPC:   0x1F,
+	}
+
+	idNs.LBAF[0] = 9 << 16             // 512B, no metadata
+	idNs.LBAF[1] = 2<<24 | 9<<16 | 8   // 512B + 8B metadata, degraded performance
+	idNs.LBAF[2] = 12 << 16            // 4KiB, no metadata
+	idNs.LBAF[3] = 1<<24 | 12<<16 | 64 // 4KiB + 64B metadata
+	idNs.LBAF[4] = 12 << 16            // over NLBAF
+
+	return &idNs
+}
+
+func TestNewFormatCmd(t *testing.T) {
+	a := assert.New(t)
+
+	tested := newFormatCmd(expectedNSId, 2, Options{})
+	a.Equal(nvme.AdminFormatNVM, tested.OpCode)
+	a.Equal(uint32(expectedNSId), tested.NSId)
+	a.Equal(uint32(0x2), tested.CDW10)
+	a.Equal(uint32(DefaultTimeout/time.Millisecond), tested.TimeoutMSec)
+	a.Zero(tested.DataLength)
+
+	tested = newFormatCmd(AllNamespaces, 3, Options{SES: CryptoErase, PI: PIType1, PIL: true, MSET: true, Timeout: time.Hour})
+	a.Equal(AllNamespaces, tested.NSId)
+	a.Equal(uint32(2<<9|1<<8|1<<5|1<<4|0x3), tested.CDW10)
+	a.Equal(uint32(time.Hour/time.Millisecond), tested.TimeoutMSec)
+}
+
+func TestValidate(t *testing.T) {
+	a := assert.New(t)
+
+	idCtrl := &identify.CtrlIdentify{FNA: fnaCrypto}
+	idNs := testNamespace()
+
+	a.NoError(validate(idCtrl, idNs, expectedNSId, 0, Options{}))
+	a.NoError(validate(idCtrl, idNs, expectedNSId, 2, Options{SES: UserDataErase}))
+	a.NoError(validate(idCtrl, idNs, expectedNSId, 2, Options{SES: CryptoErase}))
+	a.NoError(validate(idCtrl, idNs, expectedNSId, 1, Options{PI: PIType2, PIL: true, MSET: true}))
+	a.NoError(validate(idCtrl, idNs, AllNamespaces, 3, Options{PI: PIType3}))
+
+	// unsupported LBA formats
+	a.Error(validate(idCtrl, idNs, expectedNSId, 4, Options{}))
+	a.Error(validate(idCtrl, idNs, expectedNSId, 16, Options{}))
+
+	idNs.LBAF[2] = 0
+	a.Error(validate(idCtrl, idNs, expectedNSId, 2, Options{}))
+	idNs = testNamespace()
+
+	// secure erase settings
+	a.Error(validate(&identify.CtrlIdentify{}, idNs, expectedNSId, 0, Options{SES: CryptoErase}))
+	a.Error(validate(idCtrl, idNs, expectedNSId, 0, Options{SES: ses(3)}))
+
+	// format and secure erase scopes
+	a.Error(validate(&identify.CtrlIdentify{FNA: fnaFormatAll}, idNs, expectedNSId, 0, Options{}))
+	a.NoError(validate(&identify.CtrlIdentify{FNA: fnaFormatAll}, idNs, AllNamespaces, 0, Options{}))
+	a.NoError(validate(&identify.CtrlIdentify{FNA: fnaEraseAll}, idNs, expectedNSId, 0, Options{}))
+	a.Error(validate(&identify.CtrlIdentify{FNA: fnaEraseAll}, idNs, expectedNSId, 0, Options{SES: UserDataErase}))
+
+	// metadata settings
+	idNs.MC = mcSeparate
+	a.Error(validate(idCtrl, idNs, expectedNSId, 1, Options{MSET: true}))
+	a.NoError(validate(idCtrl, idNs, expectedNSId, 1, Options{}))
+	a.NoError(validate(idCtrl, idNs, expectedNSId, 0, Options{MSET: true}))
+
+	idNs.MC = mcExtended
+	a.Error(validate(idCtrl, idNs, expectedNSId, 1, Options{}))
+	idNs = testNamespace()
+
+	// protection information
+	a.Error(validate(idCtrl, idNs, expectedNSId, 2, Options{PI: PIType1}))
+	a.Error(validate(idCtrl, idNs, expectedNSId, 1, Options{PI: piType(4)}))
+
+	idNs.DPC = 0x1F &^ 0x2
+	a.Error(validate(idCtrl, idNs, expectedNSId, 1, Options{PI: PIType2}))
+	a.NoError(validate(idCtrl, idNs, expectedNSId, 1, Options{PI: PIType1}))
+
+	idNs.DPC = 0x1F &^ dpcFirst
+	a.Error(validate(idCtrl, idNs, expectedNSId, 1, Options{PI: PIType1, PIL: true}))
+
+	idNs.DPC = 0x1F &^ dpcLast
+	a.Error(validate(idCtrl, idNs, expectedNSId, 1, Options{PI: PIType1}))
+}
+
+func TestFindLBAF(t *testing.T) {
+	a := assert.New(t)
+
+	idNs := testNamespace()
+
+	tested, err := FindLBAF(idNs, 4096, 0)
+	a.NoError(err)
+	a.Equal(uint8(2), tested)
+
+	tested, err = FindLBAF(idNs, 512, 8)
+	a.NoError(err)
+	a.Equal(uint8(1), tested)
+
+	// the best relative performance is selected between the same formats
+	idNs.LBAF[3] = 12 << 16
+	idNs.LBAF[2] = 1<<24 | 12<<16
+
+	tested, err = FindLBAF(idNs, 4096, 0)
+	a.NoError(err)
+	a.Equal(uint8(3), tested)
+
+	// the format over NLBAF is not used
+	_, err = FindLBAF(idNs, 4096, 64)
+	a.Error(err)
+}
@@ -0,0 +1,58 @@
+// +build with_phys_device
+
+package format
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/sungup/go-nvmecli/pkg/nvme/identify"
+	"os"
+	"testing"
+)
+
+func TestFormat(t *testing.T) {
+	// TODO re-verify this test code using the NVMe device prepared for the format
+	// The format operation erases all user data in the namespace, so this test should run only on
+	// the device prepared for it.
+	/*
+		a := assert.New(t)
+
+		dev, _ := os.Open(targetDevice)
+
+		idNs := identify.NamespaceIdentify{}
+		a.NoError(identify.GetNamespaceIdentify(dev, expectedNSId, &idNs))
+
+		a.NoError(Format(dev, expectedNSId, idNs.FLBAS&0x0F, Options{}))
+	*/
+}
+
+func TestProgress(t *testing.T) {
+	a := assert.New(t)
+
+	dev, _ := os.Open(targetDevice)
+
+	idNs := identify.NamespaceIdentify{}
+	a.NoError(identify.GetNamespaceIdentify(dev, expectedNSId, &idNs))
+
+	percent, supported, err := Progress(dev, expectedNSId)
+	a.NoError(err)
+	a.Equal(idNs.FPI&(1<<7) != 0, supported)
+
+	if supported {
+		a.Equal(100-idNs.FPI&0x7F, percent)
+	}
+}
+
+func TestFindLBAFWithDevice(t *testing.T) {
+	a := assert.New(t)
+
+	dev, _ := os.Open(targetDevice)
+
+	idNs := identify.NamespaceIdentify{}
+	a.NoError(identify.GetNamespaceIdentify(dev, expectedNSId, &idNs))
+
+	// LBA format 0 is mandatory, so its data size and metadata size are always found
+	lbaf0 := idNs.LBAF[0]
+	tested, err := FindLBAF(&idNs, 1<<lbaf0.LBADataSize(), lbaf0.MetadataSize())
+	a.NoError(err)
+	a.Equal(lbaf0.LBADataSize(), idNs.LBAF[tested].LBADataSize())
+}
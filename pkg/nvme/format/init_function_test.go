@@ -0,0 +1,5 @@
+package format
+
+const (
+	expectedNSId = 1
+)
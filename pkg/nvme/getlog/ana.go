@@ -0,0 +1,216 @@
+package getlog
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"github.com/sungup/go-nvmecli/pkg/nvme/identify"
+	"github.com/sungup/go-nvmecli/pkg/utils"
+	"io"
+	"os"
+	"unsafe"
+)
+
+// ------------------------------------ //
+// LID 0Ch: Asymmetric Namespace Access //
+// ------------------------------------ //
+
+const (
+	// lspANARGO is the Return Groups Only bit of the LSP for Asymmetric Namespace Access log page.
+	lspANARGO = uint8(1 << 0)
+
+	// cmicANAReporting is the CMIC bit which indicates the ANA reporting is supported.
+	cmicANAReporting = uint8(1 << 3)
+)
+
+// anaState is the Asymmetric Namespace Access state of an ANA group.
+type anaState uint8
+
+const (
+	ANAOptimized      = anaState(0x1)
+	ANANonOptimized   = anaState(0x2)
+	ANAInaccessible   = anaState(0x3)
+	ANAPersistentLoss = anaState(0x4)
+	ANAChange         = anaState(0xF)
+)
+
+// anaLogHeader is the header of the Asymmetric Namespace Access log page.
+type anaLogHeader struct {
+	ChangeCount uint64  // [07:00]
+	NumGroups   uint16  // [09:08]
+	_           [6]byte // [15:10] reserved
+}
+
+// anaGroupDescHeader is the fixed part of the ANA Group Descriptor followed by the NSIDs.
+type anaGroupDescHeader struct {
+	ANAGRPID    uint32   // [03:00]
+	NNSID       uint32   // [07:04]
+	ChangeCount uint64   // [15:08]
+	State       uint8    // [16]
+	_           [15]byte // [31:17] reserved
+}
+
+// ANAGroupDesc is an ANA Group Descriptor with the namespace identifiers attached to the ANA group.
+// NSIDs is empty if the log page has been retrieved with the groups only option.
+type ANAGroupDesc struct {
+	ANAGRPID    uint32
+	ChangeCount uint64
+	State       anaState
+	NSIDs       []uint32
+}
+
+// ANALog is the Asymmetric Namespace Access log page which reports the ANA state of each ANA group
+// on the controller.
+type ANALog struct {
+	ChangeCount uint64
+	Groups      []ANAGroupDesc
+}
+
+// State returns the ANA state of the namespace. If the namespace is not in any ANA group, State
+// returns false.
+//goland:noinspection GoExportedFuncWithUnexportedType
+func (l *ANALog) State(nsid uint32) (anaState, bool) {
+	for _, group := range l.Groups {
+		for _, id := range group.NSIDs {
+			if id == nsid {
+				return group.State, true
+			}
+		}
+	}
+
+	return 0, false
+}
+
+// NsStates returns the ANA states of all namespaces reported in the log page keyed by NSID.
+//goland:noinspection GoExportedFuncWithUnexportedType
+func (l *ANALog) NsStates() map[uint32]anaState {
+	states := make(map[uint32]anaState)
+
+	for _, group := range l.Groups {
+		for _, nsid := range group.NSIDs {
+			states[nsid] = group.State
+		}
+	}
+
+	return states
+}
+
+// readANALog reads the header and each ANA group descriptor in sequence, because the size of the
+// descriptors depends on the number of NSIDs in each descriptor.
+func readANALog(r io.Reader) (*ANALog, error) {
+	header := anaLogHeader{}
+	if err := binary.Read(r, utils.SystemEndian, &header); err != nil {
+		return nil, err
+	}
+
+	l := ANALog{ChangeCount: header.ChangeCount, Groups: make([]ANAGroupDesc, header.NumGroups)}
+
+	for i := range l.Groups {
+		desc := anaGroupDescHeader{}
+		if err := binary.Read(r, utils.SystemEndian, &desc); err != nil {
+			return nil, fmt.Errorf("reading ANA group descriptor %d failed: %v", i, err)
+		}
+
+		nsids := make([]uint32, desc.NNSID)
+		if err := binary.Read(r, utils.SystemEndian, nsids); err != nil {
+			return nil, fmt.Errorf("reading NSIDs of ANA group %d failed: %v", desc.ANAGRPID, err)
+		}
+
+		l.Groups[i] = ANAGroupDesc{
+			ANAGRPID:    desc.ANAGRPID,
+			ChangeCount: desc.ChangeCount,
+			State:       anaState(desc.State & 0x0F),
+			NSIDs:       nsids,
+		}
+	}
+
+	return &l, nil
+}
+
+// GetANALog will retrieve the Asymmetric Namespace Access (0Ch) from NVMe device. If groupsOnly is
+// true, the log page is retrieved with the RGO bit and the descriptors don't have NSIDs. The log
+// page is read into a buffer up to the maximum size calculated from the controller identify data
+// and parsed from the buffer. If the log page needs two or more get-log commands, the change count
+// is read again after the log page, and the log page is read again if it has been changed.
+func GetANALog(file *os.File, groupsOnly bool) (*ANALog, error) {
+	const (
+		headerSz = int64(unsafe.Sizeof(anaLogHeader{}))
+		descSz   = int64(unsafe.Sizeof(anaGroupDescHeader{}))
+	)
+
+	idCtrl := identify.CtrlIdentify{}
+	if err := identify.GetCtrlIdentify(file, &idCtrl); err != nil {
+		return nil, err
+	} else if uint8(idCtrl.CMIC)&cmicANAReporting == 0 {
+		return nil, fmt.Errorf("asymmetric namespace access reporting is not supported")
+	}
+
+	opts := LogOptions{RAE: true}
+	size := headerSz + int64(idCtrl.NANAGRPID)*descSz
+
+	if groupsOnly {
+		opts.LSP = lspANARGO
+	} else {
+		size += int64(idCtrl.NN) * 4
+	}
+
+	for retry := 0; ; retry++ {
+		reader, err := newLogReader(file, logPageAsyncNsAccess, size, &opts, &idCtrl)
+		if err != nil {
+			return nil, err
+		}
+
+		raw, err := reader.readAll()
+		if err != nil {
+			return nil, err
+		}
+
+		l, err := ParseANALog(raw)
+		if err != nil || !reader.multiChunk() {
+			return l, err
+		}
+
+		// the descriptors may be mixed with the next generation if the change count is changed
+		header := anaLogHeader{}
+		if err = GetLogPage(file, logPageAsyncNsAccess, &opts, &header); err != nil {
+			return nil, err
+		} else if header.ChangeCount == l.ChangeCount {
+			return l, nil
+		} else if retry == maxLogRetry {
+			return nil, fmt.Errorf("ANA log page has been changed while reading: change count %d", header.ChangeCount)
+		}
+	}
+}
+
+// ParseANALog parses the Asymmetric Namespace Access log page from raw data. If the raw data is
+// shorter than the reported descriptors, this function raises an error.
+func ParseANALog(raw []byte) (*ANALog, error) {
+	if len(raw) < int(unsafe.Sizeof(anaLogHeader{})) {
+		return nil, fmt.Errorf("unexpected asymmetric namespace access data size: %d", len(raw))
+	}
+
+	return readANALog(bytes.NewReader(raw))
+}
+
+// GetNsANAState returns the ANA state of the namespace on each controller keyed by the controller
+// identifier. Each file should be a controller device connected to the same NVM subsystem like the
+// each port of a dual port drive. The controller which doesn't report the namespace is omitted.
+//goland:noinspection GoExportedFuncWithUnexportedType
+func GetNsANAState(nsid uint32, files ...*os.File) (map[uint16]anaState, error) {
+	states := make(map[uint16]anaState)
+
+	for _, file := range files {
+		idCtrl := identify.CtrlIdentify{}
+		if err := identify.GetCtrlIdentify(file, &idCtrl); err != nil {
+			return nil, err
+		}
+
+		if log, err := GetANALog(file, false); err != nil {
+			return nil, fmt.Errorf("retrieving ANA log of controller %d failed: %v", idCtrl.CNTLID, err)
+		} else if state, ok := log.State(nsid); ok {
+			states[idCtrl.CNTLID] = state
+		}
+	}
+
+	return states, nil
+}
@@ -0,0 +1,97 @@
+package getlog
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/sungup/go-nvmecli/pkg/utils"
+	"testing"
+	"unsafe"
+)
+
+// buildANALog generates a raw ANA log page with the groups which have the state and NSIDs.
+func buildANALog(changeCount uint64, states []anaState, nsids [][]uint32) []byte {
+	raw := make([]byte, 16)
+	utils.SystemEndian.PutUint64(raw, changeCount)
+	utils.SystemEndian.PutUint16(raw[8:], uint16(len(states)))
+
+	for i, state := range states {
+		desc := make([]byte, 32+len(nsids[i])*4)
+		utils.SystemEndian.PutUint32(desc, uint32(i+1))
+		utils.SystemEndian.PutUint32(desc[4:], uint32(len(nsids[i])))
+		utils.SystemEndian.PutUint64(desc[8:], changeCount+uint64(i))
+		desc[16] = uint8(state)
+
+		for j, nsid := range nsids[i] {
+			utils.SystemEndian.PutUint32(desc[32+j*4:], nsid)
+		}
+
+		raw = append(raw, desc...)
+	}
+
+	return raw
+}
+
+func TestANALogStructSize(t *testing.T) {
+	a := assert.New(t)
+
+	a.Equal(uintptr(16), unsafe.Sizeof(anaLogHeader{}))
+	a.Equal(uintptr(32), unsafe.Sizeof(anaGroupDescHeader{}))
+}
+
+func TestParseANALog(t *testing.T) {
+	a := assert.New(t)
+
+	states := []anaState{ANAOptimized, ANANonOptimized, ANAInaccessible, ANAPersistentLoss, ANAChange}
+	nsids := [][]uint32{{1, 2}, {3}, {}, {5, 6, 7}, {8}}
+
+	raw := buildANALog(10, states, nsids)
+
+	// check too short header
+	tested, err := ParseANALog(raw[:15])
+	a.Error(err)
+	a.Nil(tested)
+
+	// check truncated descriptor
+	tested, err = ParseANALog(raw[:len(raw)-1])
+	a.Error(err)
+	a.Nil(tested)
+
+	// check normal parsing
+	tested, err = ParseANALog(raw)
+	a.NoError(err)
+	a.Equal(uint64(10), tested.ChangeCount)
+	a.Len(tested.Groups, len(states))
+
+	for i, group := range tested.Groups {
+		a.Equal(uint32(i+1), group.ANAGRPID)
+		a.Equal(uint64(10+i), group.ChangeCount)
+		a.Equal(states[i], group.State)
+		a.Equal(nsids[i], group.NSIDs)
+	}
+
+	// check namespace state mapping
+	for i, list := range nsids {
+		for _, nsid := range list {
+			state, ok := tested.State(nsid)
+			a.True(ok)
+			a.Equal(states[i], state)
+		}
+	}
+
+	_, ok := tested.State(4)
+	a.False(ok)
+
+	mapped := tested.NsStates()
+	a.Len(mapped, 7)
+	a.Equal(ANAPersistentLoss, mapped[6])
+}
+
+func TestParseANALog_GroupsOnly(t *testing.T) {
+	a := assert.New(t)
+
+	raw := buildANALog(1, []anaState{ANAOptimized, ANAInaccessible}, [][]uint32{{}, {}})
+
+	tested, err := ParseANALog(raw)
+	a.NoError(err)
+	a.Len(tested.Groups, 2)
+	a.Empty(tested.NsStates())
+}
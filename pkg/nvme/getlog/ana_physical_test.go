@@ -0,0 +1,37 @@
+// +build with_phys_device
+
+package getlog
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"testing"
+)
+
+func TestGetANALog(t *testing.T) {
+	// TODO re-verify this test code using the multipath support NVMe device
+	/*
+		a := assert.New(t)
+
+		dev, _ := os.Open(targetDevice)
+
+		tested, err := GetANALog(dev, false)
+		a.NoError(err)
+		a.NotEmpty(tested.Groups)
+
+		groups, err := GetANALog(dev, true)
+		a.NoError(err)
+		a.Equal(len(tested.Groups), len(groups.Groups))
+		a.Empty(groups.NsStates())
+	*/
+}
+
+func TestGetNsANAState(t *testing.T) {
+	a := assert.New(t)
+
+	dev, _ := os.Open(targetDevice)
+
+	if tested, err := GetNsANAState(1, dev); err == nil {
+		a.LessOrEqual(len(tested), 1)
+	}
+}
@@ -0,0 +1,224 @@
+package getlog
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"github.com/sungup/go-nvmecli/pkg/nvme"
+	"github.com/sungup/go-nvmecli/pkg/nvme/identify"
+	"github.com/sungup/go-nvmecli/pkg/utils"
+	"os"
+	"sort"
+	"unsafe"
+)
+
+// ------------------------------- //
+// LID 04h: Changed Namespace List //
+// ------------------------------- //
+
+const (
+	// changedNsOverflow is the first entry of the Changed Namespace List when more than 1024
+	// namespaces have been changed.
+	changedNsOverflow = uint32(0xFFFFFFFF)
+
+	// scInvalidNamespace is the generic status code for the Invalid Namespace or Format.
+	scInvalidNamespace = uint8(0x0B)
+)
+
+// ChangedNsList is the Changed Namespace List log page which contains up to 1024 namespace
+// identifiers of the namespaces whose attributes have been changed since the last read with RAE
+// cleared. Unused entries are cleared to 0h.
+type ChangedNsList [1024]uint32
+
+// Overflow returns true if more than 1024 namespaces have been changed. In this case, host software
+// should rescan all active namespaces because the list doesn't contain the changed namespaces.
+func (c *ChangedNsList) Overflow() bool {
+	return c[0] == changedNsOverflow
+}
+
+// NSIDs returns the changed namespace identifiers. If the list has been overflowed, NSIDs returns
+// nil.
+func (c *ChangedNsList) NSIDs() []uint32 {
+	if c.Overflow() {
+		return nil
+	}
+
+	nsids := make([]uint32, 0)
+	for _, nsid := range c {
+		if nsid == 0 {
+			break
+		}
+
+		nsids = append(nsids, nsid)
+	}
+
+	return nsids
+}
+
+// GetChangedNsList will retrieve the Changed Namespace List (04h) from NVMe device. If retain is
+// false, the Namespace Attribute Changed asynchronous event is cleared by this read.
+func GetChangedNsList(file *os.File, retain bool, v interface{}) error {
+	return GetLogPage(file, logPageChangedNsList, &LogOptions{RAE: retain}, v)
+}
+
+// ParseChangedNsList parses the Changed Namespace List from raw data. If the size of raw data is
+// under 4096B, this function raises an error.
+func ParseChangedNsList(raw []byte) (*ChangedNsList, error) {
+	if len(raw) < int(unsafe.Sizeof(ChangedNsList{})) {
+		return nil, fmt.Errorf("unexpected changed namespace list data size: %d", len(raw))
+	}
+
+	c := ChangedNsList{}
+	if err := binary.Read(bytes.NewReader(raw), utils.SystemEndian, &c); err == nil {
+		return &c, nil
+	} else {
+		return nil, err
+	}
+}
+
+// NsState is a set of namespace attributes to detect the namespace changes. NUSE is not included
+// because it is changed by the normal I/O.
+type NsState struct {
+	NSZE     uint64
+	NCAP     uint64
+	FLBAS    uint8
+	DPS      uint8
+	NMIC     uint8
+	NSATTR   uint8
+	ANAGRPID uint32
+	NVMSETID uint16
+	ENDGID   uint16
+}
+
+// newNsState extracts the NsState from the namespace identify data.
+func newNsState(id *identify.NamespaceIdentify) NsState {
+	return NsState{
+		NSZE:     id.NSZE,
+		NCAP:     id.NCAP,
+		FLBAS:    id.FLBAS,
+		DPS:      id.DPS,
+		NMIC:     id.NMIC,
+		NSATTR:   id.NSATTR,
+		ANAGRPID: id.ANAGRPID,
+		NVMSETID: id.NVMSETID,
+		ENDGID:   id.ENDGID,
+	}
+}
+
+// NsSnapshot is the namespace states of the active namespaces keyed by the namespace identifier.
+type NsSnapshot map[uint32]NsState
+
+// NsChanges is the difference between two NsSnapshot. Each list is sorted by namespace identifier.
+type NsChanges struct {
+	Added   []uint32
+	Removed []uint32
+	Changed []uint32
+}
+
+// Empty returns true if there is no namespace change.
+func (c *NsChanges) Empty() bool {
+	return len(c.Added) == 0 && len(c.Removed) == 0 && len(c.Changed) == 0
+}
+
+// Diff compares the snapshot with the next snapshot and returns the added, removed and changed
+// namespaces.
+func (s NsSnapshot) Diff(next NsSnapshot) *NsChanges {
+	changes := NsChanges{
+		Added:   make([]uint32, 0),
+		Removed: make([]uint32, 0),
+		Changed: make([]uint32, 0),
+	}
+
+	for nsid, state := range next {
+		if prev, ok := s[nsid]; !ok {
+			changes.Added = append(changes.Added, nsid)
+		} else if prev != state {
+			changes.Changed = append(changes.Changed, nsid)
+		}
+	}
+
+	for nsid := range s {
+		if _, ok := next[nsid]; !ok {
+			changes.Removed = append(changes.Removed, nsid)
+		}
+	}
+
+	for _, list := range [][]uint32{changes.Added, changes.Removed, changes.Changed} {
+		sort.Slice(list, func(i, j int) bool { return list[i] < list[j] })
+	}
+
+	return &changes
+}
+
+// getNsState retrieves the NsState of a namespace. If the namespace is not active, getNsState
+// returns false because the identify namespace data of an inactive namespace is zero filled.
+func getNsState(file *os.File, nsid uint32) (NsState, bool, error) {
+	var cErr *nvme.CompletionError
+
+	id := identify.NamespaceIdentify{}
+
+	err := identify.GetNamespaceIdentify(file, nsid, &id)
+	if errors.As(err, &cErr) && cErr.HasStatus(nvme.SCTGeneric, scInvalidNamespace) {
+		return NsState{}, false, nil
+	} else if err != nil {
+		return NsState{}, false, err
+	}
+
+	return newNsState(&id), id.NSZE != 0, nil
+}
+
+// TakeNsSnapshot scans all active namespaces and returns their states.
+func TakeNsSnapshot(file *os.File) (NsSnapshot, error) {
+	nsids, err := identify.ListActiveNamespaces(file)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := make(NsSnapshot, len(nsids))
+	for _, nsid := range nsids {
+		if state, active, err := getNsState(file, nsid); err != nil {
+			return nil, err
+		} else if active {
+			snapshot[nsid] = state
+		}
+	}
+
+	return snapshot, nil
+}
+
+// TrackNsChanges reads the Changed Namespace List with clearing the asynchronous event and returns
+// the namespace changes against the previous snapshot with the updated snapshot. If the previous
+// snapshot is nil or the list has been overflowed, all active namespaces are rescanned. Otherwise,
+// only the namespaces in the list are identified again.
+func TrackNsChanges(file *os.File, prev NsSnapshot) (*NsChanges, NsSnapshot, error) {
+	list := ChangedNsList{}
+	if err := GetChangedNsList(file, false, &list); err != nil {
+		return nil, nil, err
+	}
+
+	if prev == nil || list.Overflow() {
+		if next, err := TakeNsSnapshot(file); err != nil {
+			return nil, nil, err
+		} else {
+			return prev.Diff(next), next, nil
+		}
+	}
+
+	next := make(NsSnapshot, len(prev))
+	for nsid, state := range prev {
+		next[nsid] = state
+	}
+
+	for _, nsid := range list.NSIDs() {
+		if state, active, err := getNsState(file, nsid); err != nil {
+			return nil, nil, err
+		} else if active {
+			next[nsid] = state
+		} else {
+			delete(next, nsid)
+		}
+	}
+
+	return prev.Diff(next), next, nil
+}
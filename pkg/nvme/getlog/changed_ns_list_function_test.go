@@ -0,0 +1,99 @@
+package getlog
+
+import (
+	"bytes"
+	"encoding/binary"
+	"github.com/stretchr/testify/assert"
+	"github.com/sungup/go-nvmecli/pkg/nvme/identify"
+	"github.com/sungup/go-nvmecli/pkg/utils"
+	"testing"
+	"unsafe"
+)
+
+func TestChangedNsListSize(t *testing.T) {
+	a := assert.New(t)
+
+	a.Equal(uintptr(4096), unsafe.Sizeof(ChangedNsList{}))
+}
+
+func TestChangedNsList_NSIDs(t *testing.T) {
+	a := assert.New(t)
+
+	tested := ChangedNsList{}
+	a.False(tested.Overflow())
+	a.Empty(tested.NSIDs())
+
+	tested[0], tested[1] = 2, 5
+	a.False(tested.Overflow())
+	a.Equal([]uint32{2, 5}, tested.NSIDs())
+
+	tested[0] = changedNsOverflow
+	a.True(tested.Overflow())
+	a.Nil(tested.NSIDs())
+}
+
+func TestParseChangedNsList(t *testing.T) {
+	a := assert.New(t)
+
+	expected := ChangedNsList{1, 3, 4}
+
+	buffer := new(bytes.Buffer)
+	a.NoError(binary.Write(buffer, utils.SystemEndian, expected))
+
+	// check invalid size error
+	tested, err := ParseChangedNsList(buffer.Bytes()[1:])
+	a.Error(err)
+	a.Nil(tested)
+
+	// check normal parsing
+	tested, err = ParseChangedNsList(buffer.Bytes())
+	a.NoError(err)
+	a.Equal([]uint32{1, 3, 4}, tested.NSIDs())
+}
+
+func TestNewNsState(t *testing.T) {
+	a := assert.New(t)
+
+	id := identify.NamespaceIdentify{NSZE: 100, NCAP: 90, NUSE: 10, FLBAS: 1, ANAGRPID: 2, ENDGID: 3}
+	expected := newNsState(&id)
+
+	// NUSE changes are not a namespace change
+	id.NUSE = 20
+	a.Equal(expected, newNsState(&id))
+
+	id.NCAP = 80
+	a.NotEqual(expected, newNsState(&id))
+}
+
+func TestNsSnapshot_Diff(t *testing.T) {
+	a := assert.New(t)
+
+	prev := NsSnapshot{
+		1: {NSZE: 100, NCAP: 100},
+		2: {NSZE: 200, NCAP: 200},
+		3: {NSZE: 300, NCAP: 300},
+		5: {NSZE: 500, NCAP: 500},
+	}
+
+	next := NsSnapshot{
+		1: {NSZE: 100, NCAP: 100},
+		3: {NSZE: 300, NCAP: 300, ANAGRPID: 2},
+		4: {NSZE: 400, NCAP: 400},
+		6: {NSZE: 600, NCAP: 600},
+	}
+
+	tested := prev.Diff(next)
+	a.Equal([]uint32{4, 6}, tested.Added)
+	a.Equal([]uint32{2, 5}, tested.Removed)
+	a.Equal([]uint32{3}, tested.Changed)
+	a.False(tested.Empty())
+
+	// same snapshot has no changes
+	a.True(prev.Diff(prev).Empty())
+
+	// nil snapshot is an empty snapshot
+	tested = NsSnapshot(nil).Diff(next)
+	a.Equal([]uint32{1, 3, 4, 6}, tested.Added)
+	a.Empty(tested.Removed)
+	a.Empty(tested.Changed)
+}
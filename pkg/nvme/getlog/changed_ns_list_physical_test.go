@@ -0,0 +1,45 @@
+// +build with_phys_device
+
+package getlog
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"testing"
+)
+
+func TestGetChangedNsList(t *testing.T) {
+	a := assert.New(t)
+
+	dev, _ := os.Open(targetDevice)
+
+	tested := ChangedNsList{}
+	a.NoError(GetChangedNsList(dev, true, &tested))
+}
+
+func TestTakeNsSnapshot(t *testing.T) {
+	a := assert.New(t)
+
+	dev, _ := os.Open(targetDevice)
+
+	tested, err := TakeNsSnapshot(dev)
+	a.NoError(err)
+	a.Contains(tested, uint32(expectedNSId))
+	a.NotZero(tested[expectedNSId].NSZE)
+}
+
+func TestTrackNsChanges(t *testing.T) {
+	a := assert.New(t)
+
+	dev, _ := os.Open(targetDevice)
+
+	// 1. nil snapshot rescans all namespaces
+	changes, snapshot, err := TrackNsChanges(dev, nil)
+	a.NoError(err)
+	a.Contains(changes.Added, uint32(expectedNSId))
+
+	// 2. there is no namespace change without namespace management
+	changes, _, err = TrackNsChanges(dev, snapshot)
+	a.NoError(err)
+	a.True(changes.Empty())
+}
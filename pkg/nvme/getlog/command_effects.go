@@ -0,0 +1,135 @@
+package getlog
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"github.com/sungup/go-nvmecli/pkg/utils"
+	"os"
+	"unsafe"
+)
+
+// --------------------------------------- //
+// LID 05h: Commands Supported and Effects //
+// --------------------------------------- //
+
+// cmdSubmitExec is the Command Submission and Execution (CSE) field of the command effects.
+type cmdSubmitExec uint8
+
+const (
+	CSENoRestriction       = cmdSubmitExec(0b000)
+	CSENamespaceExclusive  = cmdSubmitExec(0b001)
+	CSEControllerExclusive = cmdSubmitExec(0b010)
+)
+
+// cmdEffect is a Commands Supported and Effects data structure of an opcode.
+type cmdEffect uint32
+
+// Supported returns true if the command is supported by the controller (CSUPP).
+func (e cmdEffect) Supported() bool {
+	return e&(1<<0) != 0
+}
+
+// ChangesLogicalBlocks returns true if the command may change the logical block content of any
+// namespace (LBCC).
+func (e cmdEffect) ChangesLogicalBlocks() bool {
+	return e&(1<<1) != 0
+}
+
+// ChangesNamespaceCapability returns true if the command may change the capabilities of a namespace
+// like the namespace size or the formatted LBA size (NCC).
+func (e cmdEffect) ChangesNamespaceCapability() bool {
+	return e&(1<<2) != 0
+}
+
+// ChangesNamespaceInventory returns true if the command may change the number of namespaces or the
+// namespace attachment (NIC).
+func (e cmdEffect) ChangesNamespaceInventory() bool {
+	return e&(1<<3) != 0
+}
+
+// ChangesControllerCapability returns true if the command may change the controller capabilities
+// (CCC).
+func (e cmdEffect) ChangesControllerCapability() bool {
+	return e&(1<<4) != 0
+}
+
+// Disruptive returns true if the command may change the logical blocks, the namespaces or the
+// controller capabilities. Host software should be careful before issuing this kind of command.
+func (e cmdEffect) Disruptive() bool {
+	return e&0b11110 != 0
+}
+
+// SubmissionExecution returns the restriction of the command submission and execution (CSE).
+func (e cmdEffect) SubmissionExecution() cmdSubmitExec {
+	return cmdSubmitExec(e>>16) & 0b111
+}
+
+// UUIDSelection returns true if the command supports the UUID index selection (USS).
+func (e cmdEffect) UUIDSelection() bool {
+	return e&(1<<19) != 0
+}
+
+// CommandEffects is the Commands Supported and Effects log page which describes the commands that
+// the controller supports and the effects of those commands on the state of the NVM subsystem.
+type CommandEffects struct {
+	ACS  [256]cmdEffect // [1023:0]    Admin Command Supported
+	IOCS [256]cmdEffect // [2047:1024] I/O Command Supported
+	_    [2048]byte     // [4095:2048] reserved
+}
+
+// AdminEffects returns the effects of the admin command opcode.
+//goland:noinspection GoExportedFuncWithUnexportedType
+func (c *CommandEffects) AdminEffects(opcode uint8) cmdEffect {
+	return c.ACS[opcode]
+}
+
+// IOEffects returns the effects of the I/O command opcode.
+//goland:noinspection GoExportedFuncWithUnexportedType
+func (c *CommandEffects) IOEffects(opcode uint8) cmdEffect {
+	return c.IOCS[opcode]
+}
+
+// supported returns the opcodes which have the CSUPP bit.
+func supported(effects []cmdEffect) []uint8 {
+	opcodes := make([]uint8, 0)
+
+	for opcode, effect := range effects {
+		if effect.Supported() {
+			opcodes = append(opcodes, uint8(opcode))
+		}
+	}
+
+	return opcodes
+}
+
+// SupportedAdmin returns the admin command opcodes supported by the controller.
+func (c *CommandEffects) SupportedAdmin() []uint8 {
+	return supported(c.ACS[:])
+}
+
+// SupportedIO returns the I/O command opcodes supported by the controller.
+func (c *CommandEffects) SupportedIO() []uint8 {
+	return supported(c.IOCS[:])
+}
+
+// GetCommandEffects will retrieve the Commands Supported and Effects (05h) of the command set from
+// NVMe device. The csi should be 0h (NVM Command Set) for the controller under NVMe 2.0.
+func GetCommandEffects(file *os.File, csi uint8, v interface{}) error {
+	return GetLogPage(file, logPageCommandSupport, &LogOptions{CSI: csi}, v)
+}
+
+// ParseCommandEffects parses the Commands Supported and Effects from raw data. If the size of raw
+// data is under 4096B, this function raises an error.
+func ParseCommandEffects(raw []byte) (*CommandEffects, error) {
+	if len(raw) < int(unsafe.Sizeof(CommandEffects{})) {
+		return nil, fmt.Errorf("unexpected commands supported and effects data size: %d", len(raw))
+	}
+
+	c := CommandEffects{}
+	if err := binary.Read(bytes.NewReader(raw), utils.SystemEndian, &c); err == nil {
+		return &c, nil
+	} else {
+		return nil, err
+	}
+}
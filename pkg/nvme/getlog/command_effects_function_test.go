@@ -0,0 +1,91 @@
+package getlog
+
+import (
+	"bytes"
+	"encoding/binary"
+	"github.com/stretchr/testify/assert"
+	"github.com/sungup/go-nvmecli/pkg/nvme"
+	"github.com/sungup/go-nvmecli/pkg/utils"
+	"testing"
+	"unsafe"
+)
+
+func TestCommandEffectsSize(t *testing.T) {
+	a := assert.New(t)
+
+	a.Equal(uintptr(4096), unsafe.Sizeof(CommandEffects{}))
+}
+
+func TestCmdEffect_Bits(t *testing.T) {
+	a := assert.New(t)
+
+	tested := cmdEffect(0)
+	a.False(tested.Supported())
+	a.False(tested.Disruptive())
+	a.Equal(CSENoRestriction, tested.SubmissionExecution())
+	a.False(tested.UUIDSelection())
+
+	checkers := []func(cmdEffect) bool{
+		cmdEffect.Supported,
+		cmdEffect.ChangesLogicalBlocks,
+		cmdEffect.ChangesNamespaceCapability,
+		cmdEffect.ChangesNamespaceInventory,
+		cmdEffect.ChangesControllerCapability,
+	}
+
+	for bit := range checkers {
+		tested = cmdEffect(1 << bit)
+
+		for i, other := range checkers {
+			a.Equal(i == bit, other(tested), "bit %d, checker %d", bit, i)
+		}
+
+		a.Equal(bit != 0, tested.Disruptive())
+	}
+
+	tested = cmdEffect(CSEControllerExclusive)<<16 | 1<<19 | 0x01
+	a.Equal(CSEControllerExclusive, tested.SubmissionExecution())
+	a.True(tested.UUIDSelection())
+	a.False(tested.Disruptive())
+}
+
+func TestCommandEffects_Query(t *testing.T) {
+	a := assert.New(t)
+
+	tested := CommandEffects{}
+	tested.ACS[nvme.AdminFormatNVM] = 0b111 | cmdEffect(CSENamespaceExclusive)<<16
+	tested.ACS[nvme.AdminIdentify] = 0b1
+	tested.IOCS[0x01] = 0b11 // Write
+
+	a.Equal([]uint8{uint8(nvme.AdminIdentify), uint8(nvme.AdminFormatNVM)}, tested.SupportedAdmin())
+	a.Equal([]uint8{0x01}, tested.SupportedIO())
+
+	a.True(tested.AdminEffects(uint8(nvme.AdminFormatNVM)).ChangesLogicalBlocks())
+	a.True(tested.AdminEffects(uint8(nvme.AdminFormatNVM)).ChangesNamespaceCapability())
+	a.Equal(CSENamespaceExclusive, tested.AdminEffects(uint8(nvme.AdminFormatNVM)).SubmissionExecution())
+	a.False(tested.AdminEffects(uint8(nvme.AdminIdentify)).Disruptive())
+	a.True(tested.IOEffects(0x01).ChangesLogicalBlocks())
+	a.False(tested.IOEffects(0x02).Supported())
+}
+
+func TestParseCommandEffects(t *testing.T) {
+	a := assert.New(t)
+
+	expected := CommandEffects{}
+	expected.ACS[nvme.AdminGetLogPage] = 0x01
+	expected.IOCS[0xFF] = 0x03
+
+	buffer := new(bytes.Buffer)
+	a.NoError(binary.Write(buffer, utils.SystemEndian, expected))
+
+	// check invalid size error
+	tested, err := ParseCommandEffects(buffer.Bytes()[1:])
+	a.Error(err)
+	a.Nil(tested)
+
+	// check normal parsing
+	tested, err = ParseCommandEffects(buffer.Bytes())
+	a.NoError(err)
+	a.Equal(expected.ACS, tested.ACS)
+	a.Equal(expected.IOCS, tested.IOCS)
+}
@@ -0,0 +1,24 @@
+// +build with_phys_device
+
+package getlog
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/sungup/go-nvmecli/pkg/nvme"
+	"os"
+	"testing"
+)
+
+func TestGetCommandEffects(t *testing.T) {
+	a := assert.New(t)
+
+	dev, _ := os.Open(targetDevice)
+
+	tested := CommandEffects{}
+	a.NoError(GetCommandEffects(dev, 0, &tested))
+
+	// get-log and identify are mandatory admin commands
+	a.True(tested.AdminEffects(uint8(nvme.AdminGetLogPage)).Supported())
+	a.True(tested.AdminEffects(uint8(nvme.AdminIdentify)).Supported())
+	a.Contains(tested.SupportedAdmin(), uint8(nvme.AdminIdentify))
+}
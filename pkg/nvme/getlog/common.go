@@ -1,29 +1,38 @@
 package getlog
 
 import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
 	"github.com/sungup/go-nvmecli/pkg/nvme"
+	"github.com/sungup/go-nvmecli/pkg/nvme/identify"
+	"github.com/sungup/go-nvmecli/pkg/nvme/spec"
+	"github.com/sungup/go-nvmecli/pkg/utils"
+	"io"
 	"math"
 	"os"
 )
 
 const (
-	logPageErrorInfo      = uint8(0x01)
-	logPageSMART          = uint8(0x02)
-	logPageFWSlot         = uint8(0x03)
-	logPageChangedNsList  = uint8(0x04)
-	logPageCommandSupport = uint8(0x05)
-	logPageDevSelfTest    = uint8(0x06)
-	logPageTelemetryHost  = uint8(0x07)
-	logPageTelemetryCtrl  = uint8(0x08)
-	logPageEndurGrpInfo   = uint8(0x09)
-	logPagePredLatNVMSet  = uint8(0x0A)
-	logPagePredLatEvt     = uint8(0x0B)
-	logPageAsyncNsAccess  = uint8(0x0C)
-	logPagePersistEvtLog  = uint8(0x0D)
-	logPageLBAStatusInfo  = uint8(0x0E)
-	logPageEndurGrpEvt    = uint8(0x0F)
+	logPageErrorInfo      = spec.LogID(0x01)
+	logPageSMART          = spec.LogID(0x02)
+	logPageFWSlot         = spec.LogID(0x03)
+	logPageChangedNsList  = spec.LogID(0x04)
+	logPageCommandSupport = spec.LogID(0x05)
+	logPageDevSelfTest    = spec.LogID(0x06)
+	logPageTelemetryHost  = spec.LogID(0x07)
+	logPageTelemetryCtrl  = spec.LogID(0x08)
+	logPageEndurGrpInfo   = spec.LogID(0x09)
+	logPagePredLatNVMSet  = spec.LogID(0x0A)
+	logPagePredLatEvt     = spec.LogID(0x0B)
+	logPageAsyncNsAccess  = spec.LogID(0x0C)
+	logPagePersistEvtLog  = spec.LogID(0x0D)
+	logPageLBAStatusInfo  = spec.LogID(0x0E)
+	logPageEndurGrpEvt    = spec.LogID(0x0F)
+	logPageSanitizeStatus = spec.LogID(0x81)
 
 	maskUint4   = uint32(1<<4 - 1)
+	maskUint7   = uint32(1<<7 - 1)
 	maskUint8   = uint32(math.MaxUint8)
 	maskUint16  = uint32(math.MaxUint16)
 	maskUint32  = uint64(math.MaxUint32)
@@ -31,48 +40,152 @@ const (
 	shiftUint8  = 8
 	shiftUint16 = 16
 	shiftUint32 = 32
+
+	bitRAE        = uint32(1 << 15) // CDW10[15]
+	bitOffsetType = uint32(1 << 23) // CDW14[23]
+	shiftCSI      = 24              // CDW14[31:24]
+
+	lpaExtendedData = uint8(1 << 2)  // LPA[2]: extended NUMD and LPOL/LPOU are supported
+	ctrattUUIDList  = uint32(1 << 9) // CTRATT[9]: UUID List is supported
+
+	specVersion2 = uint32(0x00020000) // VER: MJR 2, MNR 0
 )
 
+// LogOptions is a set of the Get Log Page command parameters except the Log Identifier and the
+// data buffer. Zero value of LogOptions is a plain get-log command for the controller scope log.
+type LogOptions struct {
+	NSID        uint32 // Namespace Identifier
+	Offset      uint64 // LPOL/LPOU: byte offset, or an index offset if IndexOffset is true
+	LSP         uint8  // CDW10[14:08]: 7bit Log Specific Field
+	LSI         uint16 // CDW11[31:16]: Log Specific Identifier
+	RAE         bool   // CDW10[15]: Retain Asynchronous Event
+	UUIDIndex   uint8  // CDW14[06:00]: 7bit UUID Index
+	CSI         uint8  // CDW14[31:24]: Command Set Identifier
+	IndexOffset bool   // CDW14[23]: Offset Type, the Offset is an index of the log page entry
+}
+
+// check validates that each field fits in its command dword field.
+func (o *LogOptions) check() error {
+	if uint32(o.LSP) > maskUint7 {
+		return fmt.Errorf("LSP (%02Xh) exceeds 7bit field", o.LSP)
+	}
+
+	if uint32(o.UUIDIndex) > maskUint7 {
+		return fmt.Errorf("UUID index (%02Xh) exceeds 7bit field", o.UUIDIndex)
+	}
+
+	return nil
+}
+
+// Validate checks the log options against the controller's capability bits in the controller
+// identify data. Validate doesn't check the log page specific LSP/LSI values, because each log page
+// has different meaning about those fields.
+func (o *LogOptions) Validate(ctrl *identify.CtrlIdentify) error {
+	if err := o.check(); err != nil {
+		return err
+	}
+
+	if o.Offset != 0 && uint8(ctrl.LPA)&lpaExtendedData == 0 {
+		return fmt.Errorf("log page offset is not supported by the controller")
+	}
+
+	if o.UUIDIndex != 0 && ctrl.CTRATT&ctrattUUIDList == 0 {
+		return fmt.Errorf("UUID index is not supported by the controller")
+	}
+
+	if ctrl.VER < specVersion2 {
+		switch {
+		case uint32(o.LSP) > maskUint4:
+			return fmt.Errorf("7bit LSP is not supported under NVMe 2.0: %08Xh", ctrl.VER)
+		case o.CSI != 0:
+			return fmt.Errorf("CSI is not supported under NVMe 2.0: %08Xh", ctrl.VER)
+		case o.IndexOffset:
+			return fmt.Errorf("index offset type is not supported under NVMe 2.0: %08Xh", ctrl.VER)
+		}
+	}
+
+	return nil
+}
+
 type getLogCmd struct {
 	nvme.AdminCmd
 }
 
-// DWords changes the dwords fields (NUMDL and NUMDU).
+// DWords changes the dwords fields (NUMDL and NUMDU). NUMD is a 0's based value, so DWords stores
+// the dwords - 1 into the command.
 func (l *getLogCmd) DWords(dwords uint32) {
+	if dwords > 0 {
+		dwords--
+	}
+
 	l.CDW10 = (dwords << shiftUint16) | (l.CDW10 & maskUint16)
 	l.CDW11 = (dwords >> shiftUint16) | (l.CDW11 & umaskUint16)
 }
 
 // Offset changes the offset fields (LPOL and LPOU).
 func (l *getLogCmd) Offset(offset uint64) {
-	l.CDW12 = uint32(offset >> shiftUint32)
-	l.CDW13 = uint32(offset & maskUint32)
+	l.CDW12 = uint32(offset & maskUint32)
+	l.CDW13 = uint32(offset >> shiftUint32)
 }
 
-// LSP change the 4bit Log Specific Identifier.
+// LSP change the 7bit Log Specific Field.
 func (l *getLogCmd) LSP(lsp uint8) {
-	const UMaskLSP = ^(maskUint4 << shiftUint8)
+	const UMaskLSP = ^(maskUint7 << shiftUint8)
 
-	l.CDW10 = (l.CDW10 & UMaskLSP) | (uint32(lsp)&maskUint4)<<shiftUint8
+	l.CDW10 = (l.CDW10 & UMaskLSP) | (uint32(lsp)&maskUint7)<<shiftUint8
 }
 
-// newGetLogCmd generate an AdminCmd structure to retrieve the NVMe's log pages. To issue a get-log
-// command, the size and offset should be set for the large size log data like the Telemetry.
-// However the dwords is a value to retrieve from log page and not same with the size of the dptr.
-// If user issue get-log command larger than specified size of the real log data, get-log commands
-// will return with undefined results beyond the end of the log page.
-// Host software should clear the RAE bit to '0' for log pages that are not used with Asynchronous
-// Events.
-func newGetLogCmd(nsid uint32, offset uint64, lid, lsp uint8, lsi uint16, v interface{}) (*getLogCmd, error) {
+// LSI change the Log Specific Identifier.
+func (l *getLogCmd) LSI(lsi uint16) {
+	l.CDW11 = (l.CDW11 & maskUint16) | uint32(lsi)<<shiftUint16
+}
+
+// RAE change the Retain Asynchronous Event bit.
+func (l *getLogCmd) RAE(retain bool) {
+	if retain {
+		l.CDW10 |= bitRAE
+	} else {
+		l.CDW10 &^= bitRAE
+	}
+}
+
+// UUIDIndex change the 7bit UUID Index field.
+func (l *getLogCmd) UUIDIndex(index uint8) {
+	l.CDW14 = (l.CDW14 &^ maskUint7) | uint32(index)&maskUint7
+}
+
+// CSI change the Command Set Identifier field.
+func (l *getLogCmd) CSI(csi uint8) {
+	l.CDW14 = (l.CDW14 &^ (maskUint8 << shiftCSI)) | uint32(csi)<<shiftCSI
+}
+
+// OffsetType change the Offset Type bit. If index is true, the LPOL/LPOU fields are used as an index
+// of the log page entry instead of the byte offset.
+func (l *getLogCmd) OffsetType(index bool) {
+	if index {
+		l.CDW14 |= bitOffsetType
+	} else {
+		l.CDW14 &^= bitOffsetType
+	}
+}
+
+// newGetLogCmdWithOptions generate an AdminCmd structure to retrieve the NVMe's log pages with all
+// the Get Log Page parameters. The dwords of command is the size of the data buffer.
+func newGetLogCmdWithOptions(lid spec.LogID, opts *LogOptions, v interface{}) (*getLogCmd, error) {
+	if opts == nil {
+		opts = &LogOptions{}
+	}
+
+	if err := opts.check(); err != nil {
+		return nil, err
+	}
+
 	cmd := getLogCmd{
 		nvme.AdminCmd{
 			PassthruCmd: nvme.PassthruCmd{
 				OpCode: nvme.AdminGetLogPage,
-				NSId:   nsid,
-				CDW10:  (uint32(lsp)&maskUint4)<<shiftUint8 | uint32(lid)&maskUint8,
-				CDW11:  uint32(lsi) << shiftUint16,
-				CDW12:  uint32(offset >> shiftUint32), // Log Page Offset Lower
-				CDW13:  uint32(offset & maskUint32),   // Log Page Offset Upper
+				NSId:   opts.NSID,
+				CDW10:  uint32(lid) & maskUint8,
 			},
 			TimeoutMSec: 0,
 			Result:      0,
@@ -81,9 +194,46 @@ func newGetLogCmd(nsid uint32, offset uint64, lid, lsp uint8, lsi uint16, v inte
 
 	if err := cmd.SetData(v); err != nil {
 		return nil, err
+	}
+
+	cmd.DWords(cmd.DataLength >> 2)
+	cmd.Offset(opts.Offset)
+	cmd.LSP(opts.LSP)
+	cmd.LSI(opts.LSI)
+	cmd.RAE(opts.RAE)
+	cmd.UUIDIndex(opts.UUIDIndex)
+	cmd.CSI(opts.CSI)
+	cmd.OffsetType(opts.IndexOffset)
+
+	return &cmd, nil
+}
+
+// newGetLogCmd generate an AdminCmd structure to retrieve the NVMe's log pages. To issue a get-log
+// command, the size and offset should be set for the large size log data like the Telemetry.
+// However the dwords is a value to retrieve from log page and not same with the size of the dptr.
+// If user issue get-log command larger than specified size of the real log data, get-log commands
+// will return with undefined results beyond the end of the log page.
+// Host software should clear the RAE bit to '0' for log pages that are not used with Asynchronous
+// Events.
+func newGetLogCmd(nsid uint32, offset uint64, lid spec.LogID, lsp uint8, lsi uint16, v interface{}) (*getLogCmd, error) {
+	opts := LogOptions{
+		NSID:   nsid,
+		Offset: offset,
+		LSP:    uint8(uint32(lsp) & maskUint7),
+		LSI:    lsi,
+	}
+
+	return newGetLogCmdWithOptions(lid, &opts, v)
+}
+
+// GetLogPage retrieves a log page with the full Get Log Page parameters. Host software can check
+// the options with LogOptions.Validate before issuing the command, because the controller which
+// doesn't support the requested fields may ignore them silently.
+func GetLogPage(file *os.File, lid spec.LogID, opts *LogOptions, v interface{}) error {
+	if cmd, err := newGetLogCmdWithOptions(lid, opts, v); err != nil {
+		return err
 	} else {
-		cmd.DWords(cmd.DataLength >> 2)
-		return &cmd, nil
+		return nvme.IOCtlAdminCmd(file, &cmd.AdminCmd)
 	}
 }
 
@@ -92,11 +242,71 @@ func newGetLogCmd(nsid uint32, offset uint64, lid, lsp uint8, lsi uint16, v inte
 // --------------------------------- //
 
 // GetVendorCMD retrieve a log data for the vendor specific command. The vendorID is an aliased
-// parameter about the lid (Log Page Identifier).
-func GetVendorCMD(file *os.File, nsid uint32, vendorID, lsp uint8, lsi uint16, v interface{}) error {
-	if cmd, err := newGetLogCmd(nsid, 0, vendorID, lsp, lsi, v); err != nil {
-		return err
-	} else {
-		return nvme.IOCtlAdminCmd(file, &cmd.AdminCmd)
+// parameter about the lid (Log Page Identifier). If v is a byte slice, the log data is retrieved
+// through the LogReader, so the vendor log larger than MDTS can be read at once.
+func GetVendorCMD(file *os.File, nsid uint32, vendorID spec.LogID, lsp uint8, lsi uint16, v interface{}) error {
+	opts := LogOptions{NSID: nsid, LSP: lsp, LSI: lsi}
+
+	if buffer, ok := v.([]byte); ok {
+		if reader, err := ReadLog(file, vendorID, int64(len(buffer)), &opts); err != nil {
+			return err
+		} else {
+			_, err = io.ReadFull(reader, buffer)
+			return err
+		}
 	}
+
+	return GetLogPage(file, vendorID, &opts, v)
+}
+
+// eventAggregateHeaderSz is the size of the Number of Entries field in the event aggregate log pages.
+const eventAggregateHeaderSz = 8
+
+// getEventAggregate retrieves the event aggregate log page which has the number of entries and the
+// 16bit identifiers like Predictable Latency Event Aggregate (0Bh) or Endurance Group Event
+// Aggregate (0Fh). The number of entries is read with RAE first, so the asynchronous event is
+// cleared only by the whole log page read when retain is false.
+func getEventAggregate(file *os.File, lid spec.LogID, retain bool) ([]uint16, error) {
+	// 1. read the number of entries without clearing the asynchronous event.
+	header := make([]byte, eventAggregateHeaderSz)
+	if err := GetLogPage(file, lid, &LogOptions{RAE: true}, header); err != nil {
+		return nil, err
+	}
+
+	count := utils.SystemEndian.Uint64(header)
+	if count > math.MaxUint16 {
+		return nil, fmt.Errorf("unexpected event aggregate entries of %v: %d", lid, count)
+	}
+
+	// 2. read whole log page with the entries.
+	reader, err := ReadLog(file, lid, eventAggregateHeaderSz+int64(count)*2, &LogOptions{RAE: retain})
+	if err != nil {
+		return nil, err
+	}
+
+	raw := make([]byte, reader.Size())
+	if _, err = reader.ReadAt(raw, 0); err != nil {
+		return nil, err
+	}
+
+	return parseEventAggregate(raw)
+}
+
+// parseEventAggregate parses the identifiers of the event aggregate log page from raw data.
+func parseEventAggregate(raw []byte) ([]uint16, error) {
+	if len(raw) < eventAggregateHeaderSz {
+		return nil, fmt.Errorf("unexpected event aggregate data size: %d", len(raw))
+	}
+
+	count := utils.SystemEndian.Uint64(raw)
+	if uint64(len(raw)-eventAggregateHeaderSz)/2 < count {
+		return nil, fmt.Errorf("event aggregate has %d entries over %dB", count, len(raw))
+	}
+
+	entries := make([]uint16, count)
+	if err := binary.Read(bytes.NewReader(raw[eventAggregateHeaderSz:]), utils.SystemEndian, entries); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
 }
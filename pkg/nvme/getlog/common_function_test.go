@@ -3,6 +3,9 @@ package getlog
 import (
 	"github.com/stretchr/testify/assert"
 	"github.com/sungup/go-nvmecli/pkg/nvme"
+	"github.com/sungup/go-nvmecli/pkg/nvme/identify"
+	"github.com/sungup/go-nvmecli/pkg/nvme/spec"
+	"github.com/sungup/go-nvmecli/pkg/nvme/types"
 	"math"
 	"reflect"
 	"testing"
@@ -10,7 +13,7 @@ import (
 
 const (
 	expectedOffset = uint64(0xAB<<32 | 0xCD)
-	expectedLID    = uint8(0xCA)
+	expectedLID    = spec.LogID(0xCA)
 	expectedLSP    = uint8(0xE)
 	expectedLSI    = uint16(0xA)
 )
@@ -29,26 +32,28 @@ func TestGetLogCmd_SetDWords(t *testing.T) {
 	origin, _ := newGetLogCmd(expectedNSId, expectedOffset, expectedLID, expectedLSP, expectedLSI, v)
 	tested, _ := newGetLogCmd(expectedNSId, expectedOffset, expectedLID, expectedLSP, expectedLSI, v)
 
-	tested.DWords(^expectedDWords)
+	// NUMD is a 0's based value, so ^expectedDWords + 1 will be stored as ^expectedDWords
+	tested.DWords(^expectedDWords + 1)
 
 	// CDW10 check
 	a.NotEqual(origin.CDW10, tested.CDW10)
 	a.Equal(origin.CDW10&maskUint16, tested.CDW10&maskUint16) // keep lsp, lid field (lower 16bit)
-	a.Equal(umaskUint16, origin.CDW10^tested.CDW10)           // XOR between origin and tested should be 0xFFFF0000
 
 	// CDW11 check
 	a.NotEqual(origin.CDW11, tested.CDW11)
 	a.Equal(origin.CDW11&umaskUint16, tested.CDW11&umaskUint16) // keep lsi field (upper 16bit)
-	a.Equal(maskUint16, origin.CDW11^tested.CDW11)              // XOR between origin and tested should be 0x0000FFFF
 
 	// changed value check
+	a.Equal(expectedDWords-1, (origin.CDW10>>16)|(origin.CDW11<<16))
 	a.Equal(^expectedDWords, (tested.CDW10>>16)|(tested.CDW11<<16))
-	a.NotZero((origin.CDW10 >> 16) | (origin.CDW11 << 16))
-	a.NotZero((tested.CDW10 >> 16) | (tested.CDW11 << 16))
 
 	// CDW12/13 check
 	a.Equal(origin.CDW12, tested.CDW12)
 	a.Equal(origin.CDW13, tested.CDW13)
+
+	// 0 dwords can't be expressed by 0's based value, so it should be set as 0
+	tested.DWords(0)
+	a.Zero((tested.CDW10 >> 16) | (tested.CDW11 << 16))
 }
 
 func TestGetLogCmd_SetOffset(t *testing.T) {
@@ -73,21 +78,21 @@ func TestGetLogCmd_SetOffset(t *testing.T) {
 	a.NotEqual(origin.CDW13, tested.CDW13)
 	a.Equal(^uint32(0x0), origin.CDW13|tested.CDW13)
 
-	// changed value check
-	a.Equal(^expectedOffset, uint64(tested.CDW12)<<32|uint64(tested.CDW13))
+	// changed value check: CDW12 is LPOL and CDW13 is LPOU
+	a.Equal(^expectedOffset, uint64(tested.CDW13)<<32|uint64(tested.CDW12))
 }
 
 func TestGetLogCmd_SetLSP(t *testing.T) {
 	a := assert.New(t)
 	v := make([]byte, 4)
 
-	const UMaskLSP = ^(maskUint4 << shiftUint8)
+	const UMaskLSP = ^(maskUint7 << shiftUint8)
 
 	// 1. create same get-log command
 	origin, _ := newGetLogCmd(expectedNSId, expectedOffset, expectedLID, expectedLSP, expectedLSI, v)
 	tested, _ := newGetLogCmd(expectedNSId, expectedOffset, expectedLID, expectedLSP, expectedLSI, v)
 
-	// The passwd value is 0xfff1 but 0xfff0 is dirty value. So LSP should mask out that dirty value.
+	// The passed value is 0xf1 but 0x80 is dirty value. So LSP should mask out that dirty value.
 	tested.LSP(^expectedLSP)
 
 	// CDW11/12/13 check
@@ -97,12 +102,139 @@ func TestGetLogCmd_SetLSP(t *testing.T) {
 
 	// CDW10 Check
 	a.NotEqual(origin.CDW10, tested.CDW10)
-	a.Equal(maskUint4, (origin.CDW10^tested.CDW10)>>8)
+	a.Equal(maskUint7, (origin.CDW10^tested.CDW10)>>8)
 	a.Equal(origin.CDW10&UMaskLSP, tested.CDW10&UMaskLSP)
 
-	// Check set value and masked out dirty bits. "uint32(^expectedLSP) & maskUint4" will be clean
-	// 4bit value.
-	a.Equal(uint32(^expectedLSP)&maskUint4, (tested.CDW10>>8)&maskUint4)
+	// Check set value and masked out dirty bits. "uint32(^expectedLSP) & maskUint7" will be clean
+	// 7bit value.
+	a.Equal(uint32(^expectedLSP)&maskUint7, (tested.CDW10>>8)&maskUint7)
+}
+
+func TestGetLogCmd_SetLSI(t *testing.T) {
+	a := assert.New(t)
+	v := make([]byte, 4)
+
+	origin, _ := newGetLogCmd(expectedNSId, expectedOffset, expectedLID, expectedLSP, expectedLSI, v)
+	tested, _ := newGetLogCmd(expectedNSId, expectedOffset, expectedLID, expectedLSP, expectedLSI, v)
+
+	tested.LSI(^expectedLSI)
+
+	a.Equal(origin.CDW10, tested.CDW10)
+	a.Equal(origin.CDW11&maskUint16, tested.CDW11&maskUint16) // keep NUMDU field
+	a.Equal(^expectedLSI, uint16(tested.CDW11>>16))
+}
+
+func TestGetLogCmd_SetRAE(t *testing.T) {
+	a := assert.New(t)
+	v := make([]byte, 4)
+
+	origin, _ := newGetLogCmd(expectedNSId, expectedOffset, expectedLID, expectedLSP, expectedLSI, v)
+	tested, _ := newGetLogCmd(expectedNSId, expectedOffset, expectedLID, expectedLSP, expectedLSI, v)
+
+	a.Zero(origin.CDW10 & bitRAE)
+
+	tested.RAE(true)
+	a.Equal(bitRAE, origin.CDW10^tested.CDW10)
+
+	tested.RAE(false)
+	a.Equal(origin.CDW10, tested.CDW10)
+}
+
+func TestGetLogCmd_SetCDW14(t *testing.T) {
+	a := assert.New(t)
+	v := make([]byte, 4)
+
+	const (
+		expectedUUID = uint8(0x7E)
+		expectedCSI  = uint8(0xA5)
+	)
+
+	tested, _ := newGetLogCmd(expectedNSId, expectedOffset, expectedLID, expectedLSP, expectedLSI, v)
+	a.Zero(tested.CDW14)
+
+	// UUID index should mask out the 8th bit
+	tested.UUIDIndex(expectedUUID | 0x80)
+	a.Equal(uint32(expectedUUID), tested.CDW14)
+
+	tested.CSI(expectedCSI)
+	a.Equal(uint32(expectedCSI)<<24|uint32(expectedUUID), tested.CDW14)
+
+	tested.OffsetType(true)
+	a.Equal(uint32(expectedCSI)<<24|bitOffsetType|uint32(expectedUUID), tested.CDW14)
+
+	tested.CSI(0)
+	tested.UUIDIndex(0)
+	a.Equal(bitOffsetType, tested.CDW14)
+
+	tested.OffsetType(false)
+	a.Zero(tested.CDW14)
+}
+
+func TestNewGetLogCmdWithOptions(t *testing.T) {
+	a := assert.New(t)
+	v := make([]byte, 8)
+
+	opts := LogOptions{
+		NSID:        expectedNSId,
+		Offset:      expectedOffset,
+		LSP:         0x7F,
+		LSI:         expectedLSI,
+		RAE:         true,
+		UUIDIndex:   0x11,
+		CSI:         0x02,
+		IndexOffset: true,
+	}
+
+	tested, err := newGetLogCmdWithOptions(expectedLID, &opts, v)
+	a.NoError(err)
+	a.Equal(nvme.AdminGetLogPage, tested.OpCode)
+	a.Equal(uint32(expectedNSId), tested.NSId)
+	a.Equal(uint32(1)<<16|bitRAE|uint32(0x7F)<<8|uint32(expectedLID), tested.CDW10)
+	a.Equal(uint32(expectedLSI)<<16, tested.CDW11)
+	a.Equal(uint32(expectedOffset&maskUint32), tested.CDW12)
+	a.Equal(uint32(expectedOffset>>32), tested.CDW13)
+	a.Equal(uint32(0x02)<<24|bitOffsetType|uint32(0x11), tested.CDW14)
+
+	// nil options are same with zero value options
+	tested, err = newGetLogCmdWithOptions(expectedLID, nil, v)
+	a.NoError(err)
+	a.Equal(uint32(1)<<16|uint32(expectedLID), tested.CDW10)
+	a.Zero(tested.CDW11 | tested.CDW12 | tested.CDW13 | tested.CDW14)
+
+	// out of range fields
+	for _, tc := range []LogOptions{{LSP: 0x80}, {UUIDIndex: 0x80}} {
+		tested, err = newGetLogCmdWithOptions(expectedLID, &tc, v)
+		a.Nil(tested)
+		a.Error(err)
+	}
+}
+
+func TestLogOptions_Validate(t *testing.T) {
+	a := assert.New(t)
+
+	legacy := identify.CtrlIdentify{VER: 0x00010400}
+	modern := identify.CtrlIdentify{VER: 0x00020000, LPA: types.Uint8(lpaExtendedData), CTRATT: ctrattUUIDList}
+
+	tcList := []struct {
+		opts   LogOptions
+		legacy bool
+		modern bool
+	}{
+		{opts: LogOptions{}, legacy: true, modern: true},
+		{opts: LogOptions{NSID: 1, LSP: 0xF, LSI: 1, RAE: true}, legacy: true, modern: true},
+		{opts: LogOptions{Offset: 512}, legacy: false, modern: true},
+		{opts: LogOptions{UUIDIndex: 1}, legacy: false, modern: true},
+		{opts: LogOptions{LSP: 0x10}, legacy: false, modern: true},
+		{opts: LogOptions{CSI: 0x02}, legacy: false, modern: true},
+		{opts: LogOptions{IndexOffset: true}, legacy: false, modern: true},
+		{opts: LogOptions{LSP: 0x80}, legacy: false, modern: false},
+		{opts: LogOptions{UUIDIndex: 0x80}, legacy: false, modern: false},
+	}
+
+	for _, tc := range tcList {
+		a.Equal(tc.legacy, tc.opts.Validate(&legacy) == nil, "%+v", tc.opts)
+		a.Equal(tc.modern, tc.opts.Validate(&modern) == nil, "%+v", tc.opts)
+	}
 }
 
 func TestNewGetLogCmd(t *testing.T) {
@@ -124,9 +256,9 @@ func TestNewGetLogCmd(t *testing.T) {
 		a.NoError(err)
 		a.Equal(nvme.AdminGetLogPage, tested.OpCode)
 		a.Equal(uint32(expectedNSId), tested.NSId)
-		a.Equal(expectedDWords, (tested.CDW10>>16)|(tested.CDW11<<16))
-		a.Equal(expectedOffset, (uint64(tested.CDW12)<<32)|uint64(tested.CDW13))
-		a.Equal(expectedLID, uint8(math.MaxUint8&tested.CDW10))
+		a.Equal(expectedDWords-1, (tested.CDW10>>16)|(tested.CDW11<<16))
+		a.Equal(expectedOffset, (uint64(tested.CDW13)<<32)|uint64(tested.CDW12))
+		a.Equal(expectedLID, spec.LogID(math.MaxUint8&tested.CDW10))
 		a.Equal(expectedLSP, uint8((tested.CDW10<<16)>>24))
 		a.Equal(expectedLSI, uint16(tested.CDW11>>16))
 		a.Equal(expectedSz, tested.DataLength)
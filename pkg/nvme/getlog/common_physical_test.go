@@ -2,8 +2,38 @@
 
 package getlog
 
-import "testing"
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/sungup/go-nvmecli/pkg/nvme/identify"
+	"os"
+	"testing"
+)
 
 func TestGetVendorCMD(t *testing.T) {
 	// TODO implementing here
 }
+
+func TestLogOptions_ValidatePhysical(t *testing.T) {
+	a := assert.New(t)
+
+	dev, _ := os.Open(targetDevice)
+
+	idCtrl := identify.CtrlIdentify{}
+	a.NoError(identify.GetCtrlIdentify(dev, &idCtrl))
+
+	// plain get-log options should be supported by all controllers
+	a.NoError((&LogOptions{}).Validate(&idCtrl))
+	a.Error((&LogOptions{LSP: 0x80}).Validate(&idCtrl))
+}
+
+func TestGetLogPage(t *testing.T) {
+	a := assert.New(t)
+
+	dev, _ := os.Open(targetDevice)
+
+	tested := SMART{}
+	a.NoError(GetLogPage(dev, logPageSMART, &LogOptions{NSID: 0xFFFFFFFF}, &tested))
+	a.NotZero(tested.AvailableSpareThreshold)
+
+	a.Error(GetLogPage(dev, logPageSMART, &LogOptions{LSP: 0x80}, &tested))
+}
@@ -0,0 +1,143 @@
+package getlog
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"github.com/sungup/go-nvmecli/pkg/nvme"
+	"github.com/sungup/go-nvmecli/pkg/nvme/types"
+	"github.com/sungup/go-nvmecli/pkg/utils"
+	"os"
+	"unsafe"
+)
+
+// ------------------------- //
+// LID 06h: Device Self-test //
+// ------------------------- //
+
+// selfTestCode is the type of device self-test operation.
+type selfTestCode uint8
+
+const (
+	NoSelfTest       = selfTestCode(0x0)
+	ShortSelfTest    = selfTestCode(0x1)
+	ExtendedSelfTest = selfTestCode(0x2)
+	VendorSelfTest   = selfTestCode(0xE)
+)
+
+// selfTestResult is the result of the device self-test operation.
+type selfTestResult uint8
+
+const (
+	SelfTestNoError           = selfTestResult(0x0)
+	SelfTestAbortedByCmd      = selfTestResult(0x1)
+	SelfTestAbortedByReset    = selfTestResult(0x2)
+	SelfTestAbortedByNsRm     = selfTestResult(0x3)
+	SelfTestAbortedByFormat   = selfTestResult(0x4)
+	SelfTestFatalError        = selfTestResult(0x5)
+	SelfTestUnknownSegFail    = selfTestResult(0x6)
+	SelfTestSegmentFail       = selfTestResult(0x7)
+	SelfTestAbortedUnknown    = selfTestResult(0x8)
+	SelfTestAbortedBySanitize = selfTestResult(0x9)
+	SelfTestEntryNotUsed      = selfTestResult(0xF)
+)
+
+// selfTestEntry is a Self-test Result Data Structure of the Device Self-test log page.
+type selfTestEntry struct {
+	Status        uint8        // [00]    Device Self-test Status
+	SegmentNumber uint8        // [01]    Segment Number
+	ValidInfo     uint8        // [02]    Valid Diagnostic Information
+	_             uint8        // [03]    reserved
+	PowerOnHours  types.Uint64 // [11:04] Power On Hours
+	NSID          uint32       // [15:12] Namespace Identifier
+	FailingLBA    types.Uint64 // [23:16] Failing LBA
+	SCT           uint8        // [24]    Status Code Type
+	SC            uint8        // [25]    Status Code
+	_             [2]byte      // [27:26] Vendor Specific
+}
+
+// Code returns the self-test operation of this result.
+func (e *selfTestEntry) Code() selfTestCode {
+	return selfTestCode(e.Status >> 4)
+}
+
+// Result returns the result of the self-test operation.
+func (e *selfTestEntry) Result() selfTestResult {
+	return selfTestResult(e.Status & 0x0F)
+}
+
+// Used returns false if this entry doesn't contain a valid result.
+func (e *selfTestEntry) Used() bool {
+	return e.Result() != SelfTestEntryNotUsed
+}
+
+// Namespace returns the namespace identifier which the failure occurred and its validity.
+func (e *selfTestEntry) Namespace() (uint32, bool) {
+	return e.NSID, e.ValidInfo&(1<<0) != 0
+}
+
+// LBA returns the first failed LBA and its validity.
+func (e *selfTestEntry) LBA() (uint64, bool) {
+	return e.FailingLBA.Uint(), e.ValidInfo&(1<<1) != 0
+}
+
+// StatusCode returns the status code type and the status code of the failure and its validity.
+func (e *selfTestEntry) StatusCode() (uint8, uint8, bool) {
+	const validSCTSC = 1<<2 | 1<<3
+	return e.SCT & 0b111, e.SC, e.ValidInfo&validSCTSC == validSCTSC
+}
+
+// DevSelfTest is the Device Self-test log page which contains the status of the current self-test
+// operation and the results of the last 20 self-test operations. The newest result is the first
+// entry.
+type DevSelfTest struct {
+	CurrentOperation  uint8             // [00]      Current Device Self-Test Operation
+	CurrentCompletion uint8             // [01]      Current Device Self-Test Completion
+	_                 [2]byte           // [03:02]   reserved
+	Results           [20]selfTestEntry // [563:04]  Self-test Result Data Structures
+}
+
+// Operation returns the self-test operation in progress. If there is no self-test operation in
+// progress, Operation returns NoSelfTest.
+func (d *DevSelfTest) Operation() selfTestCode {
+	return selfTestCode(d.CurrentOperation & 0x0F)
+}
+
+// Completion returns the percentage of the self-test operation in progress.
+func (d *DevSelfTest) Completion() uint8 {
+	return d.CurrentCompletion & 0x7F
+}
+
+// Latest returns the newest self-test result. If there is no self-test result, Latest returns nil.
+//goland:noinspection GoExportedFuncWithUnexportedType
+func (d *DevSelfTest) Latest() *selfTestEntry {
+	if d.Results[0].Used() {
+		return &d.Results[0]
+	}
+
+	return nil
+}
+
+// GetDevSelfTest will retrieve the Device Self-test log (06h) from NVMe device.
+func GetDevSelfTest(file *os.File, v interface{}) error {
+	if cmd, err := newGetLogCmd(0, 0, logPageDevSelfTest, 0, 0, v); err != nil {
+		return err
+	} else {
+		return nvme.IOCtlAdminCmd(file, &cmd.AdminCmd)
+	}
+}
+
+// ParseDevSelfTest parses the Device Self-test log from raw data. If the size of raw data is under
+// 564B, this function raises an error.
+func ParseDevSelfTest(raw []byte) (*DevSelfTest, error) {
+	if len(raw) < int(unsafe.Sizeof(DevSelfTest{})) {
+		return nil, fmt.Errorf("unexpected device self-test data size: %d", len(raw))
+	}
+
+	d := DevSelfTest{}
+	if err := binary.Read(bytes.NewReader(raw), utils.SystemEndian, &d); err == nil {
+		return &d, nil
+	} else {
+		return nil, err
+	}
+}
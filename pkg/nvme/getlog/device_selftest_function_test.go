@@ -0,0 +1,99 @@
+package getlog
+
+import (
+	"bytes"
+	"encoding/binary"
+	"github.com/stretchr/testify/assert"
+	"github.com/sungup/go-nvmecli/pkg/nvme/types"
+	"github.com/sungup/go-nvmecli/pkg/utils"
+	"testing"
+	"unsafe"
+)
+
+func TestDevSelfTestSize(t *testing.T) {
+	a := assert.New(t)
+
+	a.Equal(uintptr(28), unsafe.Sizeof(selfTestEntry{}))
+	a.Equal(uintptr(564), unsafe.Sizeof(DevSelfTest{}))
+}
+
+func TestSelfTestEntry(t *testing.T) {
+	a := assert.New(t)
+
+	tested := selfTestEntry{
+		Status:     uint8(ExtendedSelfTest)<<4 | uint8(SelfTestSegmentFail),
+		ValidInfo:  0b1011,
+		NSID:       expectedNSId,
+		FailingLBA: types.Uint64{0x34, 0x12},
+		SCT:        0x2,
+		SC:         0x81,
+	}
+
+	a.Equal(ExtendedSelfTest, tested.Code())
+	a.Equal(SelfTestSegmentFail, tested.Result())
+	a.True(tested.Used())
+
+	nsid, valid := tested.Namespace()
+	a.Equal(uint32(expectedNSId), nsid)
+	a.True(valid)
+
+	lba, valid := tested.LBA()
+	a.Equal(uint64(0x1234), lba)
+	a.True(valid)
+
+	// SC is valid but SCT is not valid
+	sct, sc, valid := tested.StatusCode()
+	a.Equal(uint8(0x2), sct)
+	a.Equal(uint8(0x81), sc)
+	a.False(valid)
+
+	tested.ValidInfo = 0b1100
+	_, valid = tested.Namespace()
+	a.False(valid)
+	_, valid = tested.LBA()
+	a.False(valid)
+	_, _, valid = tested.StatusCode()
+	a.True(valid)
+
+	tested.Status = 0xFF
+	a.False(tested.Used())
+}
+
+func TestDevSelfTest(t *testing.T) {
+	a := assert.New(t)
+
+	tested := DevSelfTest{CurrentOperation: 0xF1, CurrentCompletion: 0x80 | 42}
+
+	a.Equal(ShortSelfTest, tested.Operation())
+	a.Equal(uint8(42), tested.Completion())
+
+	for i := range tested.Results {
+		tested.Results[i].Status = uint8(SelfTestEntryNotUsed)
+	}
+	a.Nil(tested.Latest())
+
+	tested.Results[0].Status = uint8(ShortSelfTest)<<4 | uint8(SelfTestNoError)
+	a.Equal(&tested.Results[0], tested.Latest())
+}
+
+func TestParseDevSelfTest(t *testing.T) {
+	a := assert.New(t)
+
+	expected := DevSelfTest{CurrentOperation: uint8(ExtendedSelfTest), CurrentCompletion: 10}
+	expected.Results[1].PowerOnHours = types.Uint64{0x01, 0x02}
+
+	buffer := new(bytes.Buffer)
+	a.NoError(binary.Write(buffer, utils.SystemEndian, expected))
+
+	// check invalid size error
+	tested, err := ParseDevSelfTest(buffer.Bytes()[1:])
+	a.Error(err)
+	a.Nil(tested)
+
+	// check normal parsing
+	tested, err = ParseDevSelfTest(buffer.Bytes())
+	a.NoError(err)
+	a.Equal(ExtendedSelfTest, tested.Operation())
+	a.Equal(uint8(10), tested.Completion())
+	a.Equal(uint64(0x0201), tested.Results[1].PowerOnHours.Uint())
+}
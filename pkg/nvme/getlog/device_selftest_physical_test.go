@@ -0,0 +1,28 @@
+// +build with_phys_device
+
+package getlog
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"testing"
+	"unsafe"
+)
+
+func TestGetDevSelfTest(t *testing.T) {
+	a := assert.New(t)
+
+	dev, _ := os.Open(targetDevice)
+
+	// 1. byte array buffer
+	buffer := make([]byte, unsafe.Sizeof(DevSelfTest{}))
+	a.NoError(GetDevSelfTest(dev, buffer))
+
+	// 2. struct data
+	tested := DevSelfTest{}
+	a.NoError(GetDevSelfTest(dev, &tested))
+
+	parsed, err := ParseDevSelfTest(buffer)
+	a.NoError(err)
+	a.Equal(tested.Operation(), parsed.Operation())
+}
@@ -0,0 +1,121 @@
+package getlog
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"github.com/sungup/go-nvmecli/pkg/nvme"
+	"github.com/sungup/go-nvmecli/pkg/nvme/identify"
+	"github.com/sungup/go-nvmecli/pkg/nvme/types"
+	"github.com/sungup/go-nvmecli/pkg/utils"
+	"os"
+	"unsafe"
+)
+
+// ------------------------------------ //
+// LID 09h: Endurance Group Information //
+// ------------------------------------ //
+
+const (
+	// ctrattEnduranceGroups is the CTRATT bit which indicates the Endurance Groups are supported.
+	ctrattEnduranceGroups = uint32(1 << 4)
+
+	// scInvalidField is the generic status code for the Invalid Field in Command.
+	scInvalidField = uint8(0x02)
+)
+
+// EnduranceGroupInfo is the Endurance Group Information log page which contains the health
+// information of an endurance group. Different from SMART, each counter is accumulated by the
+// endurance group instead of the controller.
+type EnduranceGroupInfo struct {
+	CriticalWarning         uint8    // [00]
+	_                       [2]byte  // [02:01] reserved
+	AvailableSpare          uint8    // [03]
+	AvailableSpareThreshold uint8    // [04]
+	PercentageUsed          uint8    // [05]
+	_                       [26]byte // [31:06] reserved
+
+	EnduranceEstimate   types.Uint128 // [47:32]
+	DataUnitsRead       types.Uint128 // [63:48]
+	DataUnitsWritten    types.Uint128 // [79:64]
+	MediaUnitsWritten   types.Uint128 // [95:80]
+	HostReadCommands    types.Uint128 // [111:96]
+	HostWriteCommands   types.Uint128 // [127:112]
+	IntegrityErrors     types.Uint128 // [143:128]
+	ErrorInfoLogEntries types.Uint128 // [159:144]
+
+	_ [352]byte // [511:160] reserved
+}
+
+// GetEnduranceGroupInfo will retrieve the Endurance Group Information (09h) of the endurance group
+// from NVMe device. The endurance group is selected by the Log Specific Identifier.
+func GetEnduranceGroupInfo(file *os.File, endgid uint16, v interface{}) error {
+	return GetLogPage(file, logPageEndurGrpInfo, &LogOptions{LSI: endgid}, v)
+}
+
+// ParseEnduranceGroupInfo parses the Endurance Group Information from raw data. If the size of raw
+// data is under 512B, this function raises an error.
+func ParseEnduranceGroupInfo(raw []byte) (*EnduranceGroupInfo, error) {
+	if len(raw) < int(unsafe.Sizeof(EnduranceGroupInfo{})) {
+		return nil, fmt.Errorf("unexpected endurance group information data size: %d", len(raw))
+	}
+
+	e := EnduranceGroupInfo{}
+	if err := binary.Read(bytes.NewReader(raw), utils.SystemEndian, &e); err == nil {
+		return &e, nil
+	} else {
+		return nil, err
+	}
+}
+
+// WalkEnduranceGroups retrieves the Endurance Group Information of all endurance groups from 1 to
+// ENDGIDMAX and calls fn with each endurance group identifier and its log. The identifiers which
+// the controller rejects as an invalid field are skipped because the identifiers may be sparse.
+// If fn returns an error, the walk is stopped with that error.
+func WalkEnduranceGroups(file *os.File, fn func(endgid uint16, log *EnduranceGroupInfo) error) error {
+	idCtrl := identify.CtrlIdentify{}
+	if err := identify.GetCtrlIdentify(file, &idCtrl); err != nil {
+		return err
+	} else if idCtrl.CTRATT&ctrattEnduranceGroups == 0 {
+		return fmt.Errorf("endurance groups are not supported by the controller")
+	}
+
+	for endgid := uint16(1); endgid != 0 && endgid <= idCtrl.ENDGIDMAX; endgid++ {
+		var cErr *nvme.CompletionError
+
+		log := EnduranceGroupInfo{}
+
+		err := GetEnduranceGroupInfo(file, endgid, &log)
+		if errors.As(err, &cErr) && cErr.HasStatus(nvme.SCTGeneric, scInvalidField) {
+			continue
+		} else if err != nil {
+			return err
+		}
+
+		if err = fn(endgid, &log); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ---------------------------------------- //
+// LID 0Fh: Endurance Group Event Aggregate //
+// ---------------------------------------- //
+
+// GetEnduranceGroupEventAggregate will retrieve the Endurance Group Event Aggregate (0Fh) from NVMe
+// device and returns the identifiers of endurance groups which have the pending critical warning
+// events. If retain is false, the Endurance Group Event Aggregate Log Page Change asynchronous event
+// is cleared by this read.
+func GetEnduranceGroupEventAggregate(file *os.File, retain bool) ([]uint16, error) {
+	return getEventAggregate(file, logPageEndurGrpEvt, retain)
+}
+
+// ParseEnduranceGroupEventAggregate parses the endurance group identifiers from raw data of the
+// Endurance Group Event Aggregate. If the raw data is shorter than the number of entries, this
+// function raises an error.
+func ParseEnduranceGroupEventAggregate(raw []byte) ([]uint16, error) {
+	return parseEventAggregate(raw)
+}
@@ -0,0 +1,67 @@
+package getlog
+
+import (
+	"bytes"
+	"encoding/binary"
+	"github.com/stretchr/testify/assert"
+	"github.com/sungup/go-nvmecli/pkg/nvme/types"
+	"github.com/sungup/go-nvmecli/pkg/utils"
+	"testing"
+	"unsafe"
+)
+
+func TestEnduranceGroupInfoSize(t *testing.T) {
+	a := assert.New(t)
+
+	a.Equal(uintptr(512), unsafe.Sizeof(EnduranceGroupInfo{}))
+}
+
+func TestParseEnduranceGroupInfo(t *testing.T) {
+	a := assert.New(t)
+
+	raw := make([]byte, 512)
+	raw[0] = 0x04  // critical warning
+	raw[3] = 95    // available spare
+	raw[5] = 3     // percentage used
+	raw[64] = 0x10 // data units written
+	raw[112] = 0x7 // host write commands
+
+	// check invalid size error
+	tested, err := ParseEnduranceGroupInfo(raw[1:])
+	a.Error(err)
+	a.Nil(tested)
+
+	// check normal parsing
+	tested, err = ParseEnduranceGroupInfo(raw)
+	a.NoError(err)
+	a.Equal(uint8(0x04), tested.CriticalWarning)
+	a.Equal(uint8(95), tested.AvailableSpare)
+	a.Equal(uint8(3), tested.PercentageUsed)
+	a.Equal(uint64(0x10), tested.DataUnitsWritten[0].Uint())
+	a.Equal(uint64(0x7), tested.HostWriteCommands[0].Uint())
+
+	// parsed data should be same with the raw data
+	buffer := new(bytes.Buffer)
+	a.NoError(binary.Write(buffer, utils.SystemEndian, tested))
+	a.Equal(raw, buffer.Bytes())
+	a.Equal(types.Uint128{}, tested.IntegrityErrors)
+}
+
+func TestParseEnduranceGroupEventAggregate(t *testing.T) {
+	a := assert.New(t)
+
+	raw := make([]byte, eventAggregateHeaderSz+4)
+	utils.SystemEndian.PutUint64(raw, 2)
+	utils.SystemEndian.PutUint16(raw[eventAggregateHeaderSz:], 2)
+	utils.SystemEndian.PutUint16(raw[eventAggregateHeaderSz+2:], 5)
+
+	// check truncated entries
+	tested, err := ParseEnduranceGroupEventAggregate(raw[:len(raw)-1])
+	a.Error(err)
+	a.Nil(tested)
+
+	// check normal parsing
+	tested, err = ParseEnduranceGroupEventAggregate(raw)
+	a.NoError(err)
+	a.Equal([]uint16{2, 5}, tested)
+}
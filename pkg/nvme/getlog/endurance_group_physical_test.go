@@ -0,0 +1,50 @@
+// +build with_phys_device
+
+package getlog
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"testing"
+)
+
+func TestWalkEnduranceGroups(t *testing.T) {
+	// TODO re-verify this test code using the endurance group support NVMe device
+	a := assert.New(t)
+
+	dev, _ := os.Open(targetDevice)
+
+	walked := make([]uint16, 0)
+	err := WalkEnduranceGroups(dev, func(endgid uint16, log *EnduranceGroupInfo) error {
+		walked = append(walked, endgid)
+		return nil
+	})
+
+	if err == nil {
+		a.NotEmpty(walked)
+	}
+}
+
+func TestGetEnduranceGroupInfo(t *testing.T) {
+	// TODO re-verify this test code using the endurance group support NVMe device
+	/*
+		a := assert.New(t)
+
+		dev, _ := os.Open(targetDevice)
+
+		tested := EnduranceGroupInfo{}
+		a.NoError(GetEnduranceGroupInfo(dev, 1, &tested))
+		a.NotZero(tested.AvailableSpareThreshold)
+	*/
+}
+
+func TestGetEnduranceGroupEventAggregate(t *testing.T) {
+	a := assert.New(t)
+
+	dev, _ := os.Open(targetDevice)
+
+	// retain the asynchronous event not to affect the host's event handling.
+	if tested, err := GetEnduranceGroupEventAggregate(dev, true); err == nil {
+		a.NotNil(tested)
+	}
+}
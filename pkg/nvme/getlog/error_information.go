@@ -1,9 +1,11 @@
 package getlog
 
 import (
+	"encoding/binary"
 	"fmt"
-	"github.com/sungup/go-nvmecli/pkg/nvme"
 	"github.com/sungup/go-nvmecli/pkg/nvme/identify"
+	"github.com/sungup/go-nvmecli/pkg/nvme/spec"
+	"github.com/sungup/go-nvmecli/pkg/utils"
 	"math"
 	"os"
 	"unsafe"
@@ -13,62 +15,58 @@ import (
 // LID 01h: Error Information //
 // -------------------------- //
 
+// getELPE returns the number of the Error Information log entries. ELPE is a 0's based value, so
+// the controller keeps at least 1 entry.
 func getELPE(file *os.File) (uint32, error) {
 	idCtrl := identify.CtrlIdentify{}
-	err := identify.GetCtrlIdentify(file, &idCtrl)
+	if err := identify.GetCtrlIdentify(file, &idCtrl); err != nil {
+		return 0, err
+	}
+
+	return uint32(idCtrl.ELPE.Uint()) + 1, nil
+}
+
+// validErrorEntries returns the leading entries which have the ErrorCount. The entry of which
+// ErrorCount is 0 is an empty entry, and all entries are valid if every slot is populated.
+func validErrorEntries(entries []errorEntry) []errorEntry {
+	count := 0
+	for count < len(entries) && entries[count].ErrorCount > 0 {
+		count++
+	}
 
-	return uint32(idCtrl.ELPE.Uint()), err
+	return entries[:count]
 }
 
 // GetErrorInformation will retrieve all NVMe error log entries from NVMe device
 func GetErrorInformation(file *os.File, latest uint32) ([]errorEntry, error) {
-	const (
-		errEntrySz     = uint32(unsafe.Sizeof(errorEntry{}))
-		unitErrInfoCnt = 4096 / errEntrySz
-	)
+	const errEntrySz = uint32(unsafe.Sizeof(errorEntry{}))
 
 	// 1. get identify from the identify.GetCtrlIdentify
 	maxEntry, err := getELPE(file)
-	if err != nil || maxEntry == 0 {
-		if err == nil {
-			err = fmt.Errorf("ELPE (Error Log Page Entries) is 0")
-		}
-
-		return nil, fmt.Errorf("getting ELPE failed or unsupported: %v", err)
+	if err != nil {
+		return nil, fmt.Errorf("getting ELPE failed: %v", err)
 	} else if latest < maxEntry {
 		maxEntry = latest
 	}
 
-	// 2. make Buffer, Return-Data and Get-Log command.
-	fetchCnt := unitErrInfoCnt
-
-	errors := make([]errorEntry, 0, maxEntry)
-	buffer := make([]errorEntry, unitErrInfoCnt)
-	cmd, _ := newGetLogCmd(0, 0, logPageErrorInfo, 0, 0, buffer)
-
-	// 3. get entries each unitErrInfoCnt error logs.
-	for index := uint32(0); index < maxEntry; index += unitErrInfoCnt {
-		// 3-1. update offset and dwords of getLogCmd
-		if maxEntry < index+unitErrInfoCnt {
-			fetchCnt = maxEntry - index
-			cmd.DWords(fetchCnt * errEntrySz >> 2)
-		}
-		cmd.Offset(uint64(index * errEntrySz))
-
-		// 3-2. send ioctl to device
-		if err := nvme.IOCtlAdminCmd(file, &cmd.AdminCmd); err != nil {
-			return nil, err
-		}
+	if maxEntry == 0 {
+		return []errorEntry{}, nil
+	}
 
-		errors = append(errors, buffer[:fetchCnt]...)
+	// 2. read all entries through the LogReader which splits the get-log commands by MDTS.
+	reader, err := ReadLog(file, logPageErrorInfo, int64(maxEntry*errEntrySz), nil)
+	if err != nil {
+		return nil, err
 	}
 
-	// 4. returns the valid error logs has ErrorCount > 1.
-	count := 0
-	for ; errors[count].ErrorCount > 0; count++ {
+	// 3. convert raw data to the error entries.
+	errors := make([]errorEntry, maxEntry)
+	if err = binary.Read(reader, utils.SystemEndian, errors); err != nil {
+		return nil, err
 	}
 
-	return errors[:count], nil
+	// 4. returns the valid error logs has ErrorCount > 0.
+	return validErrorEntries(errors), nil
 }
 
 type errStatField uint16
@@ -83,6 +81,21 @@ func (e errStatField) PhaseTag() uint16 {
 	return uint16(e) & 0x01
 }
 
+// SC returns the Status Code of the status field.
+func (e errStatField) SC() uint8 {
+	return uint8(e.StatusField())
+}
+
+// SCT returns the Status Code Type of the status field.
+func (e errStatField) SCT() uint8 {
+	return uint8(e.StatusField()>>8) & 0b111
+}
+
+// String returns the status name with the status code type and the status code.
+func (e errStatField) String() string {
+	return spec.StatusString(e.SCT(), e.SC())
+}
+
 type paramErrLoc uint16
 
 // LocationBit returns the bit of the command parameters that error is associated with.
@@ -105,6 +118,11 @@ const (
 	IntraHostTransport = trType(0xFE)
 )
 
+// String returns the name of the transport type.
+func (t trType) String() string {
+	return spec.TransportName(uint8(t))
+}
+
 type errorEntry struct {
 	ErrorCount uint64
 
@@ -127,3 +145,10 @@ type errorEntry struct {
 
 	_ [22]byte
 }
+
+// String returns the error entry like "SQ 1 CID 0012h: Unrecovered Read Error (SCT 2h/SC 81h) @LBA
+// 0x1234 ns 1". The error entry doesn't have the opcode, so the command is identified by the
+// submission queue and the command identifier.
+func (e errorEntry) String() string {
+	return fmt.Sprintf("SQ %d CID %04Xh: %v @LBA %#x ns %d", e.SqID, e.CommandID, e.StatusField, e.LBA, e.Namespace)
+}
@@ -69,3 +69,51 @@ func TestParamErrLoc_LocationByte(t *testing.T) {
 		}
 	}
 }
+
+func TestValidErrorEntries(t *testing.T) {
+	a := assert.New(t)
+
+	// every slot is populated
+	entries := []errorEntry{{ErrorCount: 3}, {ErrorCount: 2}, {ErrorCount: 1}}
+	a.Len(validErrorEntries(entries), 3)
+
+	entries[2].ErrorCount = 0
+	a.Len(validErrorEntries(entries), 2)
+
+	a.Empty(validErrorEntries([]errorEntry{{}, {ErrorCount: 1}}))
+	a.Empty(validErrorEntries(nil))
+}
+
+func TestErrStatField_Status(t *testing.T) {
+	a := assert.New(t)
+
+	// SCT 2h, SC 81h with the phase tag
+	tested := errStatField((2<<8|0x81)<<1 | 1)
+
+	a.Equal(uint8(0x81), tested.SC())
+	a.Equal(uint8(2), tested.SCT())
+	a.Equal("Unrecovered Read Error (SCT 2h/SC 81h)", tested.String())
+}
+
+func TestTrType_String(t *testing.T) {
+	a := assert.New(t)
+
+	a.Equal("TCP", TCPTransport.String())
+	a.Equal("Intra Host", IntraHostTransport.String())
+	a.Equal("Unknown (10h)", trType(0x10).String())
+}
+
+func TestErrorEntry_String(t *testing.T) {
+	a := assert.New(t)
+
+	tested := errorEntry{
+		ErrorCount:  1,
+		SqID:        1,
+		CommandID:   0x12,
+		StatusField: errStatField((2<<8 | 0x81) << 1),
+		LBA:         0x1234,
+		Namespace:   expectedNSId,
+	}
+
+	a.Equal("SQ 1 CID 0012h: Unrecovered Read Error (SCT 2h/SC 81h) @LBA 0x1234 ns 1", tested.String())
+}
@@ -19,7 +19,7 @@ func TestGetELPE(t *testing.T) {
 
 	tested, err := getELPE(dev)
 	a.NoError(err)
-	a.Equal(idCtrl.ELPE.Uint(), uint64(tested))
+	a.Equal(idCtrl.ELPE.Uint()+1, uint64(tested))
 }
 
 func TestGetErrorInformation(t *testing.T) {
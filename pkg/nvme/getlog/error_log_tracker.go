@@ -0,0 +1,143 @@
+package getlog
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// ----------------------------------- //
+// LID 01h: Error Information Tracking //
+// ----------------------------------- //
+
+// errorLogState is the persisted cursor of the ErrorLogTracker.
+type errorLogState struct {
+	ErrorCount uint64 `json:"error_count"`
+}
+
+// ErrorLogUpdate is the result of an ErrorLogTracker poll.
+type ErrorLogUpdate struct {
+	// Entries are the new error entries since the last poll ordered by the ErrorCount.
+	Entries []errorEntry
+
+	// Lost is the number of the error entries overwritten in the ring before the poll.
+	Lost uint64
+
+	// Reset is true if the ErrorCount of the controller restarted from 1 by a controller reset or
+	// a device replacement. The entries after the restart are reported as the new entries.
+	Reset bool
+}
+
+// ErrorLogTracker tracks the Error Information log page across the polls. The last seen ErrorCount
+// is persisted to the state file, so the new entries are returned only once even if the process
+// restarts. The ring of ELPE entries is overwritten by the controller, so the entries between the
+// polls more than the ring size are reported as Lost.
+type ErrorLogTracker struct {
+	path    string
+	state   errorLogState
+	tracked bool
+}
+
+// NewErrorLogTracker creates an ErrorLogTracker with the state file. If the state file doesn't
+// exist, the first poll returns all entries in the ring without the Lost count.
+func NewErrorLogTracker(path string) (*ErrorLogTracker, error) {
+	tracker := ErrorLogTracker{path: path}
+
+	if raw, err := ioutil.ReadFile(path); os.IsNotExist(err) {
+		return &tracker, nil
+	} else if err != nil {
+		return nil, err
+	} else if err = json.Unmarshal(raw, &tracker.state); err != nil {
+		return nil, err
+	}
+
+	tracker.tracked = true
+
+	return &tracker, nil
+}
+
+// ErrorCount returns the last seen ErrorCount.
+func (t *ErrorLogTracker) ErrorCount() uint64 {
+	return t.state.ErrorCount
+}
+
+// update returns the new entries after the last seen ErrorCount and moves the cursor to the latest
+// entry.
+func (t *ErrorLogTracker) update(entries []errorEntry) *ErrorLogUpdate {
+	sorted := append([]errorEntry{}, validErrorEntries(entries)...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].ErrorCount < sorted[j].ErrorCount })
+
+	update := ErrorLogUpdate{Entries: []errorEntry{}}
+	if len(sorted) == 0 {
+		return &update
+	}
+
+	last := t.state.ErrorCount
+
+	// the ErrorCount is a monotonically increasing value, so a smaller latest ErrorCount means the
+	// controller restarted the counter.
+	if latest := sorted[len(sorted)-1].ErrorCount; latest < last {
+		update.Reset = true
+		last = 0
+	}
+
+	first := sort.Search(len(sorted), func(i int) bool { return sorted[i].ErrorCount > last })
+	update.Entries = sorted[first:]
+
+	if len(update.Entries) > 0 {
+		if oldest := update.Entries[0].ErrorCount; (t.tracked || update.Reset) && oldest > last+1 {
+			update.Lost = oldest - last - 1
+		}
+
+		t.state.ErrorCount = update.Entries[len(update.Entries)-1].ErrorCount
+	}
+
+	return &update
+}
+
+// save writes the state file through a temporary file, so the state file is not broken by a
+// crash while writing.
+func (t *ErrorLogTracker) save() error {
+	raw, err := json.Marshal(&t.state)
+	if err != nil {
+		return err
+	}
+
+	temp, err := ioutil.TempFile(filepath.Dir(t.path), filepath.Base(t.path)+".*")
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = os.Remove(temp.Name()) }()
+
+	if _, err = temp.Write(raw); err != nil {
+		_ = temp.Close()
+		return err
+	} else if err = temp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(temp.Name(), t.path)
+}
+
+// Poll reads the Error Information log page and returns the new entries since the last poll. The
+// cursor is persisted to the state file before returning.
+func (t *ErrorLogTracker) Poll(file *os.File) (*ErrorLogUpdate, error) {
+	entries, err := GetErrorInformation(file, math.MaxUint32)
+	if err != nil {
+		return nil, err
+	}
+
+	update := t.update(entries)
+
+	if err = t.save(); err != nil {
+		return nil, err
+	}
+
+	t.tracked = true
+
+	return update, nil
+}
@@ -0,0 +1,111 @@
+package getlog
+
+import (
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// errorRing builds the error entries in the controller order which the latest entry is first.
+func errorRing(counts ...uint64) []errorEntry {
+	entries := make([]errorEntry, len(counts))
+	for i, count := range counts {
+		entries[i] = errorEntry{ErrorCount: count, Namespace: expectedNSId}
+	}
+
+	return entries
+}
+
+func errorCounts(entries []errorEntry) []uint64 {
+	counts := make([]uint64, len(entries))
+	for i, entry := range entries {
+		counts[i] = entry.ErrorCount
+	}
+
+	return counts
+}
+
+func TestErrorLogTracker_update(t *testing.T) {
+	a := assert.New(t)
+
+	tested := ErrorLogTracker{}
+
+	// 1. first poll reports all entries without the lost count
+	update := tested.update(errorRing(5, 4, 3, 0))
+	a.Equal([]uint64{3, 4, 5}, errorCounts(update.Entries))
+	a.Zero(update.Lost)
+	a.False(update.Reset)
+	a.Equal(uint64(5), tested.ErrorCount())
+
+	tested.tracked = true
+
+	// 2. no new entries, entries still in the ring are not counted again
+	update = tested.update(errorRing(5, 4, 3, 0))
+	a.Empty(update.Entries)
+	a.Zero(update.Lost)
+
+	// 3. new entries with every slot populated
+	update = tested.update(errorRing(8, 7, 6, 5))
+	a.Equal([]uint64{6, 7, 8}, errorCounts(update.Entries))
+	a.Zero(update.Lost)
+
+	// 4. the ring wrapped between the polls
+	update = tested.update(errorRing(15, 14, 13, 12))
+	a.Equal([]uint64{12, 13, 14, 15}, errorCounts(update.Entries))
+	a.Equal(uint64(3), update.Lost)
+	a.Equal(uint64(15), tested.ErrorCount())
+
+	// 5. the controller restarted the counter
+	update = tested.update(errorRing(4, 3, 2, 0))
+	a.True(update.Reset)
+	a.Equal([]uint64{2, 3, 4}, errorCounts(update.Entries))
+	a.Equal(uint64(1), update.Lost)
+	a.Equal(uint64(4), tested.ErrorCount())
+
+	// 6. empty log
+	update = tested.update(errorRing(0, 0))
+	a.Empty(update.Entries)
+	a.False(update.Reset)
+	a.Equal(uint64(4), tested.ErrorCount())
+}
+
+func TestNewErrorLogTracker(t *testing.T) {
+	a := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "error_log_tracker")
+	a.NoError(err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	path := filepath.Join(dir, "state.json")
+
+	// no state file
+	tested, err := NewErrorLogTracker(path)
+	a.NoError(err)
+	a.False(tested.tracked)
+	a.Zero(tested.ErrorCount())
+
+	// persisted cursor
+	tested.update(errorRing(10, 9))
+	a.NoError(tested.save())
+
+	tested, err = NewErrorLogTracker(path)
+	a.NoError(err)
+	a.True(tested.tracked)
+	a.Equal(uint64(10), tested.ErrorCount())
+
+	update := tested.update(errorRing(13, 12))
+	a.Equal([]uint64{12, 13}, errorCounts(update.Entries))
+	a.Equal(uint64(1), update.Lost)
+
+	// broken state file
+	a.NoError(ioutil.WriteFile(path, []byte("{"), 0644))
+	_, err = NewErrorLogTracker(path)
+	a.Error(err)
+
+	// only the state file remains
+	files, err := ioutil.ReadDir(dir)
+	a.NoError(err)
+	a.Len(files, 1)
+}
@@ -0,0 +1,34 @@
+// +build with_phys_device
+
+package getlog
+
+import (
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestErrorLogTracker_Poll(t *testing.T) {
+	a := assert.New(t)
+
+	dev, _ := os.Open(targetDevice)
+
+	dir, err := ioutil.TempDir("", "error_log_tracker")
+	a.NoError(err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	tested, err := NewErrorLogTracker(filepath.Join(dir, "state.json"))
+	a.NoError(err)
+
+	_, err = tested.Poll(dev)
+	a.NoError(err)
+
+	// the second poll doesn't return the entries of the first poll
+	update, err := tested.Poll(dev)
+	a.NoError(err)
+	for _, entry := range update.Entries {
+		a.Greater(entry.ErrorCount, uint64(0))
+	}
+}
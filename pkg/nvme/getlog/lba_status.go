@@ -0,0 +1,158 @@
+package getlog
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"github.com/sungup/go-nvmecli/pkg/utils"
+	"io"
+	"os"
+)
+
+// ------------------------------- //
+// LID 0Eh: LBA Status Information //
+// ------------------------------- //
+
+// lbaStatusHeader is the header of the LBA Status Information log page.
+type lbaStatusHeader struct {
+	LSLPLEN uint32  // [03:00] LBA Status Log Page Length
+	NLSLNE  uint32  // [07:04] Number of LBA Status Log Namespace Elements
+	ESTULB  uint32  // [11:08] Estimate of Unrecoverable Logical Blocks
+	_       [2]byte // [13:12] reserved
+	LSGC    uint16  // [15:14] LBA Status Generation Counter
+}
+
+// lbaStatusNsHeader is the fixed part of the LBA Status Log Namespace Element.
+type lbaStatusNsHeader struct {
+	NSID   uint32  // [03:00]
+	NLRD   uint32  // [07:04] Number of LBA Range Descriptors
+	RATYPE uint8   // [08] Recommended Action Type
+	_      [7]byte // [15:09] reserved
+}
+
+// LBAStatusRange is an LBA Range Descriptor of the LBA Status Information log page.
+type LBAStatusRange struct {
+	RSLBA uint64  // [07:00] Range Starting LBA
+	RNLB  uint32  // [11:08] Range Number of Logical Blocks
+	_     [4]byte // [15:12] reserved
+}
+
+// LBAStatusNsElement is the LBA ranges of a namespace which the controller has tracked as
+// potentially unrecoverable. RATYPE is the action type of Get LBA Status command which should be
+// used to examine the ranges.
+type LBAStatusNsElement struct {
+	NSID   uint32
+	RATYPE uint8
+	Ranges []LBAStatusRange
+}
+
+// LBAStatusInfo is the LBA Status Information log page.
+type LBAStatusInfo struct {
+	ESTULB     uint32
+	LSGC       uint16
+	Namespaces []LBAStatusNsElement
+}
+
+// Namespace returns the namespace element of the nsid. If the namespace has no tracked LBA range,
+// Namespace returns nil.
+func (l *LBAStatusInfo) Namespace(nsid uint32) *LBAStatusNsElement {
+	for i := range l.Namespaces {
+		if l.Namespaces[i].NSID == nsid {
+			return &l.Namespaces[i]
+		}
+	}
+
+	return nil
+}
+
+// readLBAStatusInfo reads the header and each namespace element in sequence.
+func readLBAStatusInfo(r io.Reader) (*LBAStatusInfo, error) {
+	header := lbaStatusHeader{}
+	if err := binary.Read(r, utils.SystemEndian, &header); err != nil {
+		return nil, err
+	}
+
+	l := LBAStatusInfo{
+		ESTULB:     header.ESTULB,
+		LSGC:       header.LSGC,
+		Namespaces: make([]LBAStatusNsElement, header.NLSLNE),
+	}
+
+	for i := range l.Namespaces {
+		element := lbaStatusNsHeader{}
+		if err := binary.Read(r, utils.SystemEndian, &element); err != nil {
+			return nil, fmt.Errorf("reading LBA status namespace element %d failed: %v", i, err)
+		}
+
+		ranges := make([]LBAStatusRange, element.NLRD)
+		if err := binary.Read(r, utils.SystemEndian, ranges); err != nil {
+			return nil, fmt.Errorf("reading LBA ranges of namespace %d failed: %v", element.NSID, err)
+		}
+
+		l.Namespaces[i] = LBAStatusNsElement{NSID: element.NSID, RATYPE: element.RATYPE, Ranges: ranges}
+	}
+
+	return &l, nil
+}
+
+// GetLBAStatusInfo will retrieve the LBA Status Information (0Eh) from NVMe device. All fragments
+// of the log page are read into a buffer with the RAE bit, and the LBA Status Generation Counter
+// is checked again after reading them. If the counter has been changed, the log page is read
+// again. If retain is false, the LBA Status Information Alert asynchronous event is cleared only
+// after the consistent log page has been read.
+func GetLBAStatusInfo(file *os.File, retain bool) (*LBAStatusInfo, error) {
+	retained := LogOptions{RAE: true}
+
+	for retry := 0; ; retry++ {
+		// 1. read the log page length without clearing the asynchronous event.
+		header := lbaStatusHeader{}
+		if err := GetLogPage(file, logPageLBAStatusInfo, &retained, &header); err != nil {
+			return nil, err
+		}
+
+		// 2. read whole log page with the namespace elements into a buffer.
+		reader, err := ReadLog(file, logPageLBAStatusInfo, int64(header.LSLPLEN), &retained)
+		if err != nil {
+			return nil, err
+		}
+
+		raw, err := reader.readAll()
+		if err != nil {
+			return nil, err
+		}
+
+		info, err := ParseLBAStatusInfo(raw)
+		if err != nil {
+			return nil, err
+		}
+
+		// 3. check the generation again, and clear the asynchronous event if it is not changed.
+		check := lbaStatusHeader{}
+		if err = GetLogPage(file, logPageLBAStatusInfo, &retained, &check); err != nil {
+			return nil, err
+		}
+
+		if check.LSGC == info.LSGC && !retain {
+			err = GetLogPage(file, logPageLBAStatusInfo, &LogOptions{RAE: false}, &check)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if check.LSGC == info.LSGC {
+			return info, nil
+		} else if retry == maxLogRetry {
+			return nil, fmt.Errorf("LBA status information has been changed while reading: LSGC %d", check.LSGC)
+		}
+	}
+}
+
+// ParseLBAStatusInfo parses the LBA Status Information from raw data. If the raw data is shorter
+// than the reported namespace elements, this function raises an error.
+func ParseLBAStatusInfo(raw []byte) (*LBAStatusInfo, error) {
+	if len(raw) < binary.Size(lbaStatusHeader{}) {
+		return nil, fmt.Errorf("unexpected LBA status information data size: %d", len(raw))
+	}
+
+	return readLBAStatusInfo(bytes.NewReader(raw))
+}
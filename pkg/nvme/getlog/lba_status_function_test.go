@@ -0,0 +1,72 @@
+package getlog
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/sungup/go-nvmecli/pkg/utils"
+	"testing"
+	"unsafe"
+)
+
+func TestLBAStatusInfoStructSize(t *testing.T) {
+	a := assert.New(t)
+
+	a.Equal(uintptr(16), unsafe.Sizeof(lbaStatusHeader{}))
+	a.Equal(uintptr(16), unsafe.Sizeof(lbaStatusNsHeader{}))
+	a.Equal(uintptr(16), unsafe.Sizeof(LBAStatusRange{}))
+}
+
+func TestParseLBAStatusInfo(t *testing.T) {
+	a := assert.New(t)
+
+	// namespace 1 has 2 ranges and namespace 3 has 1 range
+	raw := make([]byte, 16+(16+2*16)+(16+16))
+	utils.SystemEndian.PutUint32(raw, uint32(len(raw)))
+	utils.SystemEndian.PutUint32(raw[4:], 2)
+	utils.SystemEndian.PutUint32(raw[8:], 100)
+	utils.SystemEndian.PutUint16(raw[14:], 5)
+
+	utils.SystemEndian.PutUint32(raw[16:], 1)
+	utils.SystemEndian.PutUint32(raw[20:], 2)
+	raw[24] = 0x10
+	utils.SystemEndian.PutUint64(raw[32:], 0x1000)
+	utils.SystemEndian.PutUint32(raw[40:], 8)
+	utils.SystemEndian.PutUint64(raw[48:], 0x2000)
+	utils.SystemEndian.PutUint32(raw[56:], 16)
+
+	utils.SystemEndian.PutUint32(raw[64:], 3)
+	utils.SystemEndian.PutUint32(raw[68:], 1)
+	raw[72] = 0x11
+	utils.SystemEndian.PutUint64(raw[80:], 0x3000)
+	utils.SystemEndian.PutUint32(raw[88:], 1)
+
+	// check too short header
+	tested, err := ParseLBAStatusInfo(raw[:15])
+	a.Error(err)
+	a.Nil(tested)
+
+	// check truncated namespace element
+	tested, err = ParseLBAStatusInfo(raw[:len(raw)-1])
+	a.Error(err)
+	a.Nil(tested)
+
+	// check normal parsing
+	tested, err = ParseLBAStatusInfo(raw)
+	a.NoError(err)
+	a.Equal(uint32(100), tested.ESTULB)
+	a.Equal(uint16(5), tested.LSGC)
+	a.Len(tested.Namespaces, 2)
+
+	ns := tested.Namespace(1)
+	a.NotNil(ns)
+	a.Equal(uint8(0x10), ns.RATYPE)
+	a.Len(ns.Ranges, 2)
+	a.Equal(uint64(0x2000), ns.Ranges[1].RSLBA)
+	a.Equal(uint32(16), ns.Ranges[1].RNLB)
+
+	ns = tested.Namespace(3)
+	a.NotNil(ns)
+	a.Equal(uint8(0x11), ns.RATYPE)
+	a.Len(ns.Ranges, 1)
+
+	a.Nil(tested.Namespace(2))
+}
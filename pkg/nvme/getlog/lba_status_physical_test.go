@@ -0,0 +1,21 @@
+// +build with_phys_device
+
+package getlog
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"testing"
+)
+
+func TestGetLBAStatusInfo(t *testing.T) {
+	a := assert.New(t)
+
+	dev, _ := os.Open(targetDevice)
+
+	if tested, err := GetLBAStatusInfo(dev, true); err == nil {
+		for _, ns := range tested.Namespaces {
+			a.NotZero(ns.NSID)
+		}
+	}
+}
@@ -0,0 +1,259 @@
+package getlog
+
+import (
+	"errors"
+	"fmt"
+	"github.com/sungup/go-nvmecli/pkg/nvme"
+	"github.com/sungup/go-nvmecli/pkg/nvme/identify"
+	"github.com/sungup/go-nvmecli/pkg/nvme/spec"
+	"io"
+	"os"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const (
+	// maxLogChunkSz is the maximum transfer size of a get-log command when the controller doesn't
+	// report MDTS. It is the largest size that can be expressed by NUMDL only.
+	maxLogChunkSz = uint32(1<<16) << 2
+
+	// minMemPageShift is the shift of CAP.MPSMIN 0h, the minimum memory page size is 2^(12+MPSMIN).
+	minMemPageShift = 12
+
+	maxLogRetry = 3
+
+	// crdUnit is the unit of the Command Retry Delay Times (CRDT1-3) in the identify controller.
+	crdUnit = 100 * time.Millisecond
+)
+
+// transferSize returns the maximum byte size of a get-log command from MDTS and CAP.MPSMIN. MDTS is
+// reported in units of the minimum memory page size and 0h means there is no limitation.
+func transferSize(mdts, mpsmin uint8) uint32 {
+	shift := int(mdts) + int(mpsmin) + minMemPageShift
+
+	if mdts == 0 || shift >= 32 {
+		return maxLogChunkSz
+	}
+
+	if size := uint32(1) << shift; size < maxLogChunkSz {
+		return size
+	}
+
+	return maxLogChunkSz
+}
+
+// retryable returns true if the failed command can be resubmitted.
+func retryable(err error) bool {
+	var cErr *nvme.CompletionError
+
+	if errors.As(err, &cErr) {
+		return !cErr.DNR()
+	}
+
+	return errors.Is(err, syscall.EINTR) || errors.Is(err, syscall.EAGAIN)
+}
+
+// retryDelay returns the delay before resubmitting the failed command. The completion with the
+// Command Retry Delay (CRD) field should be retried after the CRDT of the index.
+func retryDelay(err error, crdt [3]uint16) time.Duration {
+	var cErr *nvme.CompletionError
+
+	if errors.As(err, &cErr) && cErr.CRD() != 0 {
+		return time.Duration(crdt[cErr.CRD()-1]) * crdUnit
+	}
+
+	return 0
+}
+
+// LogReader reads a log page larger than a single get-log command's transfer size. The log page is
+// fetched by the chunk which is not larger than MDTS and the chunk is buffered, so the small reads
+// in the same chunk don't issue the get-log commands again. The LogReader can stream the log page
+// without loading the whole log page into the memory.
+type LogReader struct {
+	file *os.File
+	lid  spec.LogID
+	opts LogOptions
+
+	size     int64
+	mdts     uint8
+	chunk    uint32
+	extended bool
+	crdt     [3]uint16
+	offset   int64
+	mutex    sync.Mutex
+
+	// buffer has the fetched chunk starting at bufOff of the log page
+	buffer []byte
+	bufOff int64
+}
+
+// ReadLog creates a LogReader to read size bytes of the log page. The options are validated with
+// the controller identify data and the offset in options is the base offset of the LogReader.
+// The index offset type is not supported because LogReader moves the byte offset of each command.
+func ReadLog(file *os.File, lid spec.LogID, size int64, opts *LogOptions) (*LogReader, error) {
+	idCtrl := identify.CtrlIdentify{}
+	if err := identify.GetCtrlIdentify(file, &idCtrl); err != nil {
+		return nil, err
+	}
+
+	return newLogReader(file, lid, size, opts, &idCtrl)
+}
+
+// newLogReader creates a LogReader using the already retrieved controller identify data.
+func newLogReader(file *os.File, lid spec.LogID, size int64, opts *LogOptions, ctrl *identify.CtrlIdentify) (*LogReader, error) {
+	r := LogReader{file: file, lid: lid, size: size}
+
+	if opts != nil {
+		r.opts = *opts
+	}
+
+	if size < 0 {
+		return nil, fmt.Errorf("invalid log page size: %d", size)
+	}
+
+	if r.opts.IndexOffset {
+		return nil, fmt.Errorf("index offset type is not supported by LogReader")
+	}
+
+	if err := r.opts.Validate(ctrl); err != nil {
+		return nil, err
+	}
+
+	r.mdts = uint8(ctrl.MDTS)
+	r.chunk = transferSize(r.mdts, 0)
+	r.extended = uint8(ctrl.LPA)&lpaExtendedData != 0
+	r.crdt = [3]uint16{ctrl.CRDT1, ctrl.CRDT2, ctrl.CRDT3}
+
+	// the controller doesn't support the offset, so the log page should be read in a command.
+	if !r.extended && size > int64(r.chunk) {
+		return nil, fmt.Errorf("log page offset is not supported for %d bytes log page", size)
+	}
+
+	return &r, nil
+}
+
+// SetMPSMin changes the minimum memory page size (CAP.MPSMIN) to calculate the transfer size from
+// MDTS. CAP register can't be read through the admin commands, so LogReader uses 0h (4KiB) which is
+// the smallest transfer size of the MDTS value.
+func (r *LogReader) SetMPSMin(mpsmin uint8) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.chunk = transferSize(r.mdts, mpsmin)
+	r.buffer = nil
+}
+
+// Size returns the total size of the log page to read.
+func (r *LogReader) Size() int64 {
+	return r.size
+}
+
+// fetch reads the chunk including the offset off into the internal buffer with a get-log
+// command. The chunks start at the multiple of the chunk size, so the offset and the length of the
+// get-log command are always dword aligned. The offset of the get-log command is not 0 only if the
+// controller supports the log page offset.
+func (r *LogReader) fetch(off int64) error {
+	start := off / int64(r.chunk) * int64(r.chunk)
+
+	length := int64(r.chunk)
+	if limit := (r.size + 0b11) &^ 0b11; start+length > limit {
+		length = limit - start
+	}
+
+	opts := r.opts
+	opts.Offset += uint64(start)
+
+	if opts.Offset != 0 && !r.extended {
+		return fmt.Errorf("log page offset is not supported by the controller: %d", opts.Offset)
+	}
+
+	buffer := make([]byte, length)
+
+	cmd, err := newGetLogCmdWithOptions(r.lid, &opts, buffer)
+	if err != nil {
+		return err
+	}
+
+	for retry := 0; ; retry++ {
+		if err = nvme.IOCtlAdminCmd(r.file, &cmd.AdminCmd); err == nil {
+			break
+		} else if retry == maxLogRetry || !retryable(err) {
+			r.buffer = nil
+			return err
+		}
+
+		time.Sleep(retryDelay(err, r.crdt))
+	}
+
+	r.buffer, r.bufOff = buffer, start
+
+	return nil
+}
+
+// buffered copies the buffered data at the offset off into p, and returns the copied size.
+func (r *LogReader) buffered(p []byte, off int64) int {
+	if r.buffer == nil || off < r.bufOff || off >= r.bufOff+int64(len(r.buffer)) {
+		return 0
+	}
+
+	return copy(p, r.buffer[off-r.bufOff:])
+}
+
+// ReadAt reads len(p) bytes of the log page starting at byte offset off. If the requested range
+// is over the size of log page, ReadAt returns the read size with io.EOF.
+func (r *LogReader) ReadAt(p []byte, off int64) (n int, err error) {
+	if off < 0 {
+		return 0, fmt.Errorf("negative offset: %d", off)
+	} else if off >= r.size {
+		return 0, io.EOF
+	}
+
+	if remain := r.size - off; int64(len(p)) > remain {
+		p, err = p[:remain], io.EOF
+	}
+
+	// internal buffer is shared between the get-log commands
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for n < len(p) {
+		if copied := r.buffered(p[n:], off+int64(n)); copied > 0 {
+			n += copied
+		} else if fErr := r.fetch(off + int64(n)); fErr != nil {
+			return n, fErr
+		}
+	}
+
+	return n, err
+}
+
+// readAll reads the whole log page into a buffer. The log page is fetched by the chunk sized
+// get-log commands, so the caller should check the log page has not been changed between the
+// commands if the log page is larger than a chunk.
+func (r *LogReader) readAll() ([]byte, error) {
+	buffer := make([]byte, r.size)
+
+	if n, err := r.ReadAt(buffer, 0); err != nil && !(err == io.EOF && int64(n) == r.size) {
+		return nil, err
+	}
+
+	return buffer, nil
+}
+
+// multiChunk returns true if the log page is read by two or more get-log commands.
+func (r *LogReader) multiChunk() bool {
+	return r.size > int64(r.chunk)
+}
+
+// Read reads the next len(p) bytes of the log page.
+func (r *LogReader) Read(p []byte) (int, error) {
+	n, err := r.ReadAt(p, r.offset)
+	r.offset += int64(n)
+
+	if n > 0 && err == io.EOF {
+		err = nil
+	}
+
+	return n, err
+}
@@ -0,0 +1,177 @@
+package getlog
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/sungup/go-nvmecli/pkg/nvme"
+	"github.com/sungup/go-nvmecli/pkg/nvme/identify"
+	"github.com/sungup/go-nvmecli/pkg/nvme/types"
+	"io"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestTransferSize(t *testing.T) {
+	a := assert.New(t)
+
+	tcList := []struct {
+		mdts     uint8
+		mpsmin   uint8
+		expected uint32
+	}{
+		{mdts: 0, mpsmin: 0, expected: maxLogChunkSz},
+		{mdts: 1, mpsmin: 0, expected: 8192},
+		{mdts: 5, mpsmin: 0, expected: 128 * 1024},
+		{mdts: 5, mpsmin: 1, expected: maxLogChunkSz},
+		{mdts: 2, mpsmin: 2, expected: 64 * 1024},
+		{mdts: 7, mpsmin: 0, expected: maxLogChunkSz},
+		{mdts: 0xFF, mpsmin: 0xF, expected: maxLogChunkSz},
+	}
+
+	for _, tc := range tcList {
+		a.Equal(tc.expected, transferSize(tc.mdts, tc.mpsmin), "%+v", tc)
+	}
+}
+
+func TestRetryable(t *testing.T) {
+	a := assert.New(t)
+
+	a.True(retryable(&nvme.CompletionError{Status: 0x0006}))
+	a.False(retryable(&nvme.CompletionError{Status: 1<<14 | 0x0006}))
+	a.True(retryable(fmt.Errorf("wrapped: %w", &nvme.CompletionError{Status: 0x0006})))
+	a.True(retryable(os.NewSyscallError("ioctl", syscall.EINTR)))
+	a.False(retryable(os.NewSyscallError("ioctl", syscall.EACCES)))
+	a.False(retryable(fmt.Errorf("unknown error")))
+}
+
+func TestRetryDelay(t *testing.T) {
+	a := assert.New(t)
+
+	crdt := [3]uint16{1, 5, 20}
+
+	a.Zero(retryDelay(&nvme.CompletionError{Status: 0x0006}, crdt))
+	a.Equal(100*time.Millisecond, retryDelay(&nvme.CompletionError{Status: 1<<11 | 0x0006}, crdt))
+	a.Equal(500*time.Millisecond, retryDelay(fmt.Errorf("wrapped: %w", &nvme.CompletionError{Status: 2<<11 | 0x0006}), crdt))
+	a.Equal(2*time.Second, retryDelay(&nvme.CompletionError{Status: 3<<11 | 0x0006}, crdt))
+	a.Zero(retryDelay(os.NewSyscallError("ioctl", syscall.EINTR), crdt))
+}
+
+func TestNewLogReader(t *testing.T) {
+	a := assert.New(t)
+
+	ctrl := identify.CtrlIdentify{VER: 0x00010400, MDTS: 5, LPA: types.Uint8(lpaExtendedData)}
+
+	tested, err := newLogReader(nil, logPageErrorInfo, 1<<20, &LogOptions{NSID: expectedNSId}, &ctrl)
+	a.NoError(err)
+	a.Equal(int64(1<<20), tested.Size())
+	a.Equal(uint32(128*1024), tested.chunk)
+	a.Equal(uint32(expectedNSId), tested.opts.NSID)
+
+	tested.SetMPSMin(1)
+	a.Equal(maxLogChunkSz, tested.chunk)
+
+	// nil options is allowed
+	tested, err = newLogReader(nil, logPageErrorInfo, 512, nil, &ctrl)
+	a.NoError(err)
+	a.Equal(LogOptions{}, tested.opts)
+
+	// invalid cases
+	for _, tc := range []struct {
+		size int64
+		opts *LogOptions
+	}{
+		{size: -1, opts: nil},
+		{size: 512, opts: &LogOptions{IndexOffset: true}},
+		{size: 512, opts: &LogOptions{CSI: 1}},
+	} {
+		tested, err = newLogReader(nil, logPageErrorInfo, tc.size, tc.opts, &ctrl)
+		a.Nil(tested)
+		a.Error(err)
+	}
+
+	// without the extended data support, log page should be read in a command
+	ctrl.LPA = 0
+
+	tested, err = newLogReader(nil, logPageErrorInfo, 128*1024, nil, &ctrl)
+	a.NoError(err)
+	a.NotNil(tested)
+
+	tested, err = newLogReader(nil, logPageErrorInfo, 128*1024+4, nil, &ctrl)
+	a.Error(err)
+	a.Nil(tested)
+
+	// the base offset needs the extended data support
+	tested, err = newLogReader(nil, logPageErrorInfo, 512, &LogOptions{Offset: 512}, &ctrl)
+	a.Error(err)
+	a.Nil(tested)
+}
+
+func TestLogReader_fetch(t *testing.T) {
+	a := assert.New(t)
+
+	// the chunk at the non-zero offset is not fetched without the extended data support
+	tested := LogReader{lid: logPageErrorInfo, size: 8192, chunk: 4096}
+	a.Error(tested.fetch(4096))
+	a.Error(tested.fetch(5000))
+	a.Nil(tested.buffer)
+}
+
+func TestLogReader_buffered(t *testing.T) {
+	a := assert.New(t)
+
+	tested := LogReader{size: 64, chunk: 16}
+	buffer := make([]byte, 8)
+
+	// nothing is buffered
+	a.Zero(tested.buffered(buffer, 0))
+
+	tested.buffer, tested.bufOff = []byte("0123456789abcdef"), 16
+
+	a.Zero(tested.buffered(buffer, 15))
+	a.Zero(tested.buffered(buffer, 32))
+
+	a.Equal(8, tested.buffered(buffer, 16))
+	a.Equal("01234567", string(buffer))
+
+	a.Equal(3, tested.buffered(buffer, 29))
+	a.Equal("def", string(buffer[:3]))
+}
+
+func TestLogReader_readAll(t *testing.T) {
+	a := assert.New(t)
+
+	// whole log page is buffered, so no get-log command is issued
+	tested := LogReader{size: 16, chunk: 16, buffer: []byte("0123456789abcdef")}
+
+	raw, err := tested.readAll()
+	a.NoError(err)
+	a.Equal("0123456789abcdef", string(raw))
+	a.False(tested.multiChunk())
+
+	tested.size = 17
+	a.True(tested.multiChunk())
+}
+
+func TestLogReader_ReadAt(t *testing.T) {
+	a := assert.New(t)
+
+	ctrl := identify.CtrlIdentify{VER: 0x00010400, LPA: types.Uint8(lpaExtendedData)}
+	tested, _ := newLogReader(nil, logPageErrorInfo, 512, nil, &ctrl)
+
+	buffer := make([]byte, 16)
+
+	// out of range offsets don't issue get-log commands
+	n, err := tested.ReadAt(buffer, -1)
+	a.Zero(n)
+	a.Error(err)
+
+	n, err = tested.ReadAt(buffer, 512)
+	a.Zero(n)
+	a.Equal(io.EOF, err)
+
+	n, err = tested.Read(buffer[:0])
+	a.Zero(n)
+	a.NoError(err)
+}
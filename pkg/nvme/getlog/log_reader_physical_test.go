@@ -0,0 +1,56 @@
+// +build with_phys_device
+
+package getlog
+
+import (
+	"github.com/stretchr/testify/assert"
+	"io"
+	"os"
+	"testing"
+	"unsafe"
+)
+
+func TestReadLog(t *testing.T) {
+	a := assert.New(t)
+
+	dev, _ := os.Open(targetDevice)
+
+	expected := make([]byte, unsafe.Sizeof(SMART{}))
+	a.NoError(GetSMART(dev, expected))
+
+	tested, err := ReadLog(dev, logPageSMART, int64(len(expected)), nil)
+	a.NoError(err)
+	a.NotNil(tested)
+
+	// 1. read all data at once
+	buffer := make([]byte, len(expected))
+	n, err := tested.ReadAt(buffer, 0)
+	a.NoError(err)
+	a.Equal(len(expected), n)
+	a.Equal(expected[3:5], buffer[3:5]) // available spare and threshold
+
+	// 2. read unaligned range
+	n, err = tested.ReadAt(buffer[:3], 3)
+	a.NoError(err)
+	a.Equal(3, n)
+	a.Equal(expected[3:5], buffer[:2])
+
+	// 3. read over the log size
+	n, err = tested.ReadAt(buffer, 256)
+	a.Equal(io.EOF, err)
+	a.Equal(256, n)
+
+	// 4. stream with the odd size buffer
+	streamed := make([]byte, 0, len(expected))
+	for chunk := make([]byte, 7); ; {
+		n, err = tested.Read(chunk)
+		streamed = append(streamed, chunk[:n]...)
+
+		if err == io.EOF {
+			break
+		}
+		a.NoError(err)
+	}
+	a.Len(streamed, len(expected))
+	a.Equal(expected[3:5], streamed[3:5])
+}
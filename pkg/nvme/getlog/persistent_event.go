@@ -0,0 +1,406 @@
+package getlog
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"github.com/sungup/go-nvmecli/pkg/nvme/identify"
+	"github.com/sungup/go-nvmecli/pkg/nvme/spec"
+	"github.com/sungup/go-nvmecli/pkg/nvme/types"
+	"github.com/sungup/go-nvmecli/pkg/utils"
+	"os"
+	"sort"
+	"time"
+	"unsafe"
+)
+
+// ----------------------------- //
+// LID 0Dh: Persistent Event Log //
+// ----------------------------- //
+
+const (
+	// lpaPersistentEvent is the LPA bit which indicates the Persistent Event log is supported.
+	lpaPersistentEvent = uint8(1 << 4)
+
+	// LSP values of the Persistent Event log page, the Management Action field.
+	pelReadLogData     = uint8(0x00)
+	pelEstablishCtx    = uint8(0x01)
+	pelReleaseCtx      = uint8(0x02)
+	pelEventHeaderBase = 3 // EHL doesn't include the first 3 bytes of the event header
+
+	pelTimestampMask = uint64(1<<48 - 1)
+)
+
+// errPELGenerationChanged is raised when the Persistent Event log is changed while reading.
+var errPELGenerationChanged = errors.New("persistent event log generation changed")
+
+// peType is the Event Type of a persistent event.
+type peType uint8
+
+const (
+	PESMARTSnapshot       = peType(0x01)
+	PEFirmwareCommit      = peType(0x02)
+	PETimestampChange     = peType(0x03)
+	PEPowerOnReset        = peType(0x04)
+	PENSSHardwareError    = peType(0x05)
+	PEChangeNamespace     = peType(0x06)
+	PEFormatStart         = peType(0x07)
+	PEFormatCompletion    = peType(0x08)
+	PESanitizeStart       = peType(0x09)
+	PESanitizeCompletion  = peType(0x0A)
+	PESetFeature          = peType(0x0B)
+	PETelemetryLogCreated = peType(0x0C)
+	PEThermalExcursion    = peType(0x0D)
+	PEVendorSpecific      = peType(0xDE)
+	PETCGDefined          = peType(0xDF)
+)
+
+// PersistentEventHeader is the 512 bytes header of the Persistent Event log page.
+type PersistentEventHeader struct {
+	LID              spec.LogID    // [00]
+	_                [3]byte       // [03:01] reserved
+	TNEV             uint32        // [07:04] Total Number of Events
+	TLL              uint64        // [15:08] Total Log Length
+	LogRevision      uint8         // [16]
+	_                uint8         // [17] reserved
+	LHL              uint16        // [19:18] Log Header Length
+	Timestamp        types.Uint64  // [27:20]
+	PowerOnHours     types.Uint128 // [43:28]
+	PowerCycleCount  types.Uint64  // [51:44]
+	VID              types.VID     // [53:52]
+	SSVID            types.SSVID   // [55:54]
+	SN               types.SN      // [75:56]
+	MN               types.MN      // [115:76]
+	SUBNQN           [256]byte     // [371:116]
+	GenerationNumber uint16        // [373:372]
+	RCI              types.Uint32  // [377:374] Reporting Context Information
+	_                [102]byte     // [479:378] reserved
+	SupportedEvents  [32]byte      // [511:480] Supported Events Bitmap
+}
+
+// Supports returns true if the event type is supported by the controller.
+func (h *PersistentEventHeader) Supports(t peType) bool {
+	return h.SupportedEvents[t/8]&(1<<(uint8(t)%8)) != 0
+}
+
+// peEventHeader is the common part of the persistent event header.
+type peEventHeader struct {
+	Type         peType  // [00]
+	Revision     uint8   // [01]
+	EHL          uint8   // [02] Event Header Length
+	_            uint8   // [03] reserved
+	CNTLID       uint16  // [05:04]
+	Timestamp    uint64  // [13:06]
+	PortID       uint16  // [15:14]
+	_            [4]byte // [19:16] reserved
+	VendorInfoSz uint16  // [21:20] Vendor Specific Information Length
+	EventLength  uint16  // [23:22] the length of vendor specific information and event data
+}
+
+// PEFirmwareCommitData is the event data of the Firmware Commit event.
+type PEFirmwareCommitData struct {
+	OldFirmwareRevision [8]byte
+	NewFirmwareRevision [8]byte
+	CommitAction        uint8
+	FirmwareSlot        uint8
+	StatusCodeType      uint8
+	StatusCode          uint8
+	VendorResultCode    uint16
+}
+
+// PETimestampChangeData is the event data of the Timestamp Change event.
+type PETimestampChangeData struct {
+	PreviousTimestamp uint64
+	MSecSinceReset    uint64
+}
+
+// PEPowerOnResetInfo is the power-on or reset information of a controller.
+type PEPowerOnResetInfo struct {
+	CNTLID              uint16
+	FirmwareActivation  uint8
+	OperationInProgress uint8
+	_                   [12]byte
+	CtrlPowerCycle      uint32
+	PowerOnMSec         uint64
+	CtrlTimestamp       uint64
+}
+
+// PEPowerOnResetData is the event data of the Power-on or Reset event.
+type PEPowerOnResetData struct {
+	FirmwareRevision [8]byte
+	Controllers      []PEPowerOnResetInfo
+}
+
+// PENSSHardwareErrorData is the event data of the NVM Subsystem Hardware Error event.
+type PENSSHardwareErrorData struct {
+	Code           uint16
+	AdditionalInfo []byte
+}
+
+// PEChangeNamespaceData is the event data of the Change Namespace event.
+type PEChangeNamespaceData struct {
+	NsMgmtCDW10 uint32
+	_           [4]byte
+	NSZE        uint64
+	_           [8]byte
+	NCAP        uint64
+	FLBAS       uint8
+	DPS         uint8
+	NMIC        uint8
+	_           uint8
+	ANAGRPID    uint32
+	NVMSETID    uint16
+	_           uint16
+	NSID        uint32
+}
+
+// PEFormatStartData is the event data of the Format NVM Start event.
+type PEFormatStartData struct {
+	NSID        uint32
+	Attributes  uint8
+	_           [3]byte
+	FormatCDW10 uint32
+}
+
+// PEFormatCompletionData is the event data of the Format NVM Completion event.
+type PEFormatCompletionData struct {
+	NSID           uint32
+	SmallestFPI    uint8
+	FormatStatus   uint8
+	CompletionInfo uint16
+	StatusField    uint32
+}
+
+// PESanitizeStartData is the event data of the Sanitize Start event.
+type PESanitizeStartData struct {
+	SANICAP       uint32
+	SanitizeCDW10 uint32
+	SanitizeCDW11 uint32
+}
+
+// PESanitizeCompletionData is the event data of the Sanitize Completion event.
+type PESanitizeCompletionData struct {
+	SanitizeProgress uint16
+	SanitizeStatus   uint16
+	CompletionInfo   uint16
+	_                uint16
+}
+
+// PEThermalExcursionData is the event data of the Thermal Excursion event.
+type PEThermalExcursionData struct {
+	OverTemperature uint8
+	Threshold       uint8
+}
+
+// PersistentEvent is a decoded persistent event. Payload is a pointer of the typed event data like
+// *PEFirmwareCommitData or *SMART. If the event type is unknown or the event data is shorter than
+// expected, Payload is nil and the event data is available in Data.
+type PersistentEvent struct {
+	Type       peType
+	Revision   uint8
+	CNTLID     uint16
+	PortID     uint16
+	Timestamp  uint64
+	VendorInfo []byte
+	Data       []byte
+	Payload    interface{}
+}
+
+// Time returns the event timestamp which is the milliseconds since the Unix epoch. The upper bits
+// of timestamp field about the synch and origin are ignored.
+func (e *PersistentEvent) Time() time.Time {
+	ms := int64(e.Timestamp & pelTimestampMask)
+
+	return time.Unix(ms/1000, (ms%1000)*int64(time.Millisecond))
+}
+
+// decodeFixed decodes a fixed size event data into v.
+func decodeFixed(data []byte, v interface{}) interface{} {
+	if len(data) < binary.Size(v) {
+		return nil
+	}
+
+	if err := binary.Read(bytes.NewReader(data), utils.SystemEndian, v); err != nil {
+		return nil
+	}
+
+	return v
+}
+
+// decodePayload decodes the event data into the typed payload of the event type.
+func decodePayload(t peType, data []byte) interface{} {
+	switch t {
+	case PESMARTSnapshot:
+		return decodeFixed(data, &SMART{})
+	case PEFirmwareCommit:
+		return decodeFixed(data, &PEFirmwareCommitData{})
+	case PETimestampChange:
+		return decodeFixed(data, &PETimestampChangeData{})
+	case PEPowerOnReset:
+		infoSz := binary.Size(PEPowerOnResetInfo{})
+
+		if len(data) < 8 {
+			return nil
+		}
+
+		p := PEPowerOnResetData{Controllers: make([]PEPowerOnResetInfo, (len(data)-8)/infoSz)}
+		copy(p.FirmwareRevision[:], data)
+
+		if err := binary.Read(bytes.NewReader(data[8:]), utils.SystemEndian, p.Controllers); err != nil {
+			return nil
+		}
+
+		return &p
+	case PENSSHardwareError:
+		if len(data) < 4 {
+			return nil
+		}
+
+		return &PENSSHardwareErrorData{Code: utils.SystemEndian.Uint16(data), AdditionalInfo: data[4:]}
+	case PEChangeNamespace:
+		return decodeFixed(data, &PEChangeNamespaceData{})
+	case PEFormatStart:
+		return decodeFixed(data, &PEFormatStartData{})
+	case PEFormatCompletion:
+		return decodeFixed(data, &PEFormatCompletionData{})
+	case PESanitizeStart:
+		return decodeFixed(data, &PESanitizeStartData{})
+	case PESanitizeCompletion:
+		return decodeFixed(data, &PESanitizeCompletionData{})
+	case PEThermalExcursion:
+		return decodeFixed(data, &PEThermalExcursionData{})
+	case PEVendorSpecific:
+		return data
+	}
+
+	return nil
+}
+
+// PersistentEventLog is the header and the events of the Persistent Event log page. Events are
+// sorted by the event timestamp.
+type PersistentEventLog struct {
+	Header PersistentEventHeader
+	Events []PersistentEvent
+}
+
+// ParsePersistentEventLog parses the whole Persistent Event log page from raw data. The events are
+// sorted by the event timestamp, and the events which have the same timestamp keep the log order.
+func ParsePersistentEventLog(raw []byte) (*PersistentEventLog, error) {
+	const headerSz = int(unsafe.Sizeof(PersistentEventHeader{}))
+
+	evtHeadSz := binary.Size(peEventHeader{})
+
+	if len(raw) < headerSz {
+		return nil, fmt.Errorf("unexpected persistent event log data size: %d", len(raw))
+	}
+
+	l := PersistentEventLog{}
+	if err := binary.Read(bytes.NewReader(raw), utils.SystemEndian, &l.Header); err != nil {
+		return nil, err
+	}
+
+	if l.Header.TLL < uint64(headerSz) || l.Header.TLL > uint64(len(raw)) {
+		return nil, fmt.Errorf("unexpected total log length %d over %dB", l.Header.TLL, len(raw))
+	}
+
+	raw = raw[:l.Header.TLL]
+	l.Events = make([]PersistentEvent, 0, l.Header.TNEV)
+
+	for offset, i := headerSz, uint32(0); i < l.Header.TNEV; i++ {
+		if offset+evtHeadSz > len(raw) {
+			return nil, fmt.Errorf("persistent event %d header is over the log length", i)
+		}
+
+		h := peEventHeader{}
+		if err := binary.Read(bytes.NewReader(raw[offset:]), utils.SystemEndian, &h); err != nil {
+			return nil, err
+		}
+
+		start := offset + int(h.EHL) + pelEventHeaderBase
+		end := start + int(h.EventLength)
+		if end > len(raw) || h.VendorInfoSz > h.EventLength {
+			return nil, fmt.Errorf("persistent event %d (type %02Xh) is over the log length", i, h.Type)
+		}
+
+		vsil := start + int(h.VendorInfoSz)
+		e := PersistentEvent{
+			Type:       h.Type,
+			Revision:   h.Revision,
+			CNTLID:     h.CNTLID,
+			PortID:     h.PortID,
+			Timestamp:  h.Timestamp,
+			VendorInfo: raw[start:vsil],
+			Data:       raw[vsil:end],
+		}
+		e.Payload = decodePayload(e.Type, e.Data)
+
+		l.Events = append(l.Events, e)
+		offset = end
+	}
+
+	sort.SliceStable(l.Events, func(i, j int) bool {
+		return l.Events[i].Timestamp&pelTimestampMask < l.Events[j].Timestamp&pelTimestampMask
+	})
+
+	return &l, nil
+}
+
+// getPersistentEventHeader retrieves the log header with the management action.
+func getPersistentEventHeader(file *os.File, action uint8, header *PersistentEventHeader) error {
+	return GetLogPage(file, logPagePersistEvtLog, &LogOptions{LSP: action}, header)
+}
+
+// readPersistentEventLog establishes the reporting context and reads the whole log page in the
+// context. The context is released after reading even if reading is failed.
+func readPersistentEventLog(file *os.File, ctrl *identify.CtrlIdentify) (raw []byte, err error) {
+	header := PersistentEventHeader{}
+
+	if err = getPersistentEventHeader(file, pelEstablishCtx, &header); err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		if rErr := getPersistentEventHeader(file, pelReleaseCtx, &PersistentEventHeader{}); err == nil {
+			err = rErr
+		}
+	}()
+
+	reader, err := newLogReader(file, logPagePersistEvtLog, int64(header.TLL), &LogOptions{LSP: pelReadLogData}, ctrl)
+	if err != nil {
+		return nil, err
+	}
+
+	raw = make([]byte, reader.Size())
+	if _, err = reader.ReadAt(raw, 0); err != nil {
+		return nil, err
+	}
+
+	// the generation number is changed if the log has been wrapped or cleared while reading
+	if gen := utils.SystemEndian.Uint16(raw[372:]); gen != header.GenerationNumber {
+		return nil, fmt.Errorf("%w: %d -> %d", errPELGenerationChanged, header.GenerationNumber, gen)
+	}
+
+	return raw, nil
+}
+
+// GetPersistentEventLog will retrieve the Persistent Event log page (0Dh) from NVMe device. It
+// establishes the reporting context, reads the header and the events through the LogReader, and
+// releases the context. If the generation number is changed while reading, the whole log page is
+// read again up to the retry limit.
+func GetPersistentEventLog(file *os.File) (*PersistentEventLog, error) {
+	idCtrl := identify.CtrlIdentify{}
+	if err := identify.GetCtrlIdentify(file, &idCtrl); err != nil {
+		return nil, err
+	} else if uint8(idCtrl.LPA)&lpaPersistentEvent == 0 {
+		return nil, fmt.Errorf("persistent event log is not supported")
+	}
+
+	for retry := 0; ; retry++ {
+		if raw, err := readPersistentEventLog(file, &idCtrl); err == nil {
+			return ParsePersistentEventLog(raw)
+		} else if retry == maxLogRetry || !errors.Is(err, errPELGenerationChanged) {
+			return nil, err
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package getlog
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/sungup/go-nvmecli/pkg/utils"
+	"testing"
+	"unsafe"
+)
+
+// buildPersistentEvent generates a raw persistent event with the 24 bytes event header.
+func buildPersistentEvent(t peType, timestamp uint64, vendor, data []byte) []byte {
+	raw := make([]byte, 24)
+	raw[0] = uint8(t)
+	raw[2] = 21
+	utils.SystemEndian.PutUint16(raw[4:], 1)
+	utils.SystemEndian.PutUint64(raw[6:], timestamp)
+	utils.SystemEndian.PutUint16(raw[20:], uint16(len(vendor)))
+	utils.SystemEndian.PutUint16(raw[22:], uint16(len(vendor)+len(data)))
+
+	return append(append(raw, vendor...), data...)
+}
+
+// buildPersistentEventLog generates a raw persistent event log page with the events.
+func buildPersistentEventLog(generation uint16, events ...[]byte) []byte {
+	raw := make([]byte, 512)
+	raw[0] = uint8(logPagePersistEvtLog)
+	utils.SystemEndian.PutUint32(raw[4:], uint32(len(events)))
+	utils.SystemEndian.PutUint16(raw[372:], generation)
+	raw[480] = 1<<PEFirmwareCommit | 1<<PEPowerOnReset
+
+	for _, event := range events {
+		raw = append(raw, event...)
+	}
+
+	utils.SystemEndian.PutUint64(raw[8:], uint64(len(raw)))
+
+	return raw
+}
+
+func TestPersistentEventHeaderSize(t *testing.T) {
+	a := assert.New(t)
+
+	a.Equal(uintptr(512), unsafe.Sizeof(PersistentEventHeader{}))
+}
+
+func TestParsePersistentEventLog(t *testing.T) {
+	a := assert.New(t)
+
+	fwCommit := make([]byte, 24)
+	copy(fwCommit, "OLD_REV1NEW_REV2")
+	fwCommit[16] = 0x3 // commit action
+	fwCommit[17] = 0x2 // firmware slot
+
+	powerOn := make([]byte, 8+36*2)
+	copy(powerOn, "FW_REV_1")
+	utils.SystemEndian.PutUint16(powerOn[8+36:], 2)
+	utils.SystemEndian.PutUint64(powerOn[8+36+20:], 1234)
+
+	nssError := []byte{0x0A, 0x00, 0x00, 0x00, 0xDE, 0xAD}
+
+	raw := buildPersistentEventLog(7,
+		buildPersistentEvent(PEFirmwareCommit, 3000, nil, fwCommit),
+		buildPersistentEvent(PEPowerOnReset, 1000, []byte{0xFF, 0xFF}, powerOn),
+		buildPersistentEvent(PENSSHardwareError, 2000, nil, nssError),
+		buildPersistentEvent(PEThermalExcursion, 1<<48|2000, nil, []byte{80}),
+		buildPersistentEvent(0xC0, 500, nil, []byte{1, 2, 3}),
+	)
+
+	// check too short header
+	tested, err := ParsePersistentEventLog(raw[:511])
+	a.Error(err)
+	a.Nil(tested)
+
+	// check truncated events
+	tested, err = ParsePersistentEventLog(raw[:len(raw)-1])
+	a.Error(err)
+	a.Nil(tested)
+
+	// check normal parsing
+	tested, err = ParsePersistentEventLog(raw)
+	a.NoError(err)
+	a.Equal(uint16(7), tested.Header.GenerationNumber)
+	a.True(tested.Header.Supports(PEFirmwareCommit))
+	a.True(tested.Header.Supports(PEPowerOnReset))
+	a.False(tested.Header.Supports(PESMARTSnapshot))
+
+	// events are sorted by timestamp without the timestamp attributes
+	a.Len(tested.Events, 5)
+	expected := []peType{0xC0, PEPowerOnReset, PENSSHardwareError, PEThermalExcursion, PEFirmwareCommit}
+	for i, event := range tested.Events {
+		a.Equal(expected[i], event.Type)
+	}
+
+	// unknown event has only raw data
+	a.Nil(tested.Events[0].Payload)
+	a.Equal([]byte{1, 2, 3}, tested.Events[0].Data)
+	a.Equal(int64(500), tested.Events[0].Time().UnixNano()/1000000)
+
+	if p, ok := tested.Events[1].Payload.(*PEPowerOnResetData); a.True(ok) {
+		a.Equal([]byte{0xFF, 0xFF}, tested.Events[1].VendorInfo)
+		a.Equal("FW_REV_1", string(p.FirmwareRevision[:]))
+		a.Len(p.Controllers, 2)
+		a.Equal(uint16(2), p.Controllers[1].CNTLID)
+		a.Equal(uint64(1234), p.Controllers[1].PowerOnMSec)
+	}
+
+	if p, ok := tested.Events[2].Payload.(*PENSSHardwareErrorData); a.True(ok) {
+		a.Equal(uint16(0x0A), p.Code)
+		a.Equal([]byte{0xDE, 0xAD}, p.AdditionalInfo)
+	}
+
+	// thermal excursion event is shorter than expected, so the payload is not decoded.
+	a.Nil(tested.Events[3].Payload)
+
+	if p, ok := tested.Events[4].Payload.(*PEFirmwareCommitData); a.True(ok) {
+		a.Equal("OLD_REV1", string(p.OldFirmwareRevision[:]))
+		a.Equal("NEW_REV2", string(p.NewFirmwareRevision[:]))
+		a.Equal(uint8(0x3), p.CommitAction)
+		a.Equal(uint8(0x2), p.FirmwareSlot)
+	}
+}
@@ -0,0 +1,24 @@
+// +build with_phys_device
+
+package getlog
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"testing"
+)
+
+func TestGetPersistentEventLog(t *testing.T) {
+	a := assert.New(t)
+
+	dev, _ := os.Open(targetDevice)
+
+	if tested, err := GetPersistentEventLog(dev); err == nil {
+		a.Equal(logPagePersistEvtLog, tested.Header.LID)
+		a.Len(tested.Events, int(tested.Header.TNEV))
+
+		for i := 1; i < len(tested.Events); i++ {
+			a.False(tested.Events[i].Time().Before(tested.Events[i-1].Time()))
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package getlog
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"github.com/sungup/go-nvmecli/pkg/utils"
+	"os"
+	"unsafe"
+)
+
+// -------------------------------------------------------------------------------------- //
+// LID 0Ah: Predictable Latency Per NVM Set, LID 0Bh: Predictable Latency Event Aggregate //
+// -------------------------------------------------------------------------------------- //
+
+// plStatus is the window status of an NVM Set in the predictable latency mode.
+type plStatus uint8
+
+const (
+	PLStatusDisabled = plStatus(0b000)
+	PLStatusDTWIN    = plStatus(0b001)
+	PLStatusNDWIN    = plStatus(0b010)
+)
+
+// plEventType is the predictable latency event types which have been occurred on an NVM Set.
+type plEventType uint16
+
+// DTWINReadsWarning returns true if the DTWIN Reads Estimate is under the DTWIN Reads Threshold.
+func (e plEventType) DTWINReadsWarning() bool {
+	return e&(1<<0) != 0
+}
+
+// DTWINWritesWarning returns true if the DTWIN Writes Estimate is under the DTWIN Writes Threshold.
+func (e plEventType) DTWINWritesWarning() bool {
+	return e&(1<<1) != 0
+}
+
+// DTWINTimeWarning returns true if the DTWIN Time Estimate is under the DTWIN Time Threshold.
+func (e plEventType) DTWINTimeWarning() bool {
+	return e&(1<<2) != 0
+}
+
+// AutonomousTransition returns true if the NVM Set has been autonomously moved from the
+// deterministic window to the non-deterministic window because the typical or the maximum values
+// of the deterministic window have been exceeded.
+func (e plEventType) AutonomousTransition() bool {
+	return e&(1<<14) != 0
+}
+
+// DeterministicExcursion returns true if the NVM Set has undergone a deterministic excursion, an
+// autonomous transition to the non-deterministic window for the controller's internal reasons.
+func (e plEventType) DeterministicExcursion() bool {
+	return e&(1<<15) != 0
+}
+
+// PredLatNVMSet is the Predictable Latency Per NVM Set log page which reports the window status,
+// the typical and maximum values of the deterministic window and its remaining estimates.
+type PredLatNVMSet struct {
+	Status    uint8       // [00]
+	_         uint8       // [01] reserved
+	EventType plEventType // [03:02]
+	_         [28]byte    // [31:04] reserved
+
+	DTWINReadsTypical  uint64 // [39:32] in 4KiB unit
+	DTWINWritesTypical uint64 // [47:40] in the optimal write size unit
+	DTWINTimeMaximum   uint64 // [55:48] in milliseconds
+	NDWINTimeMinHigh   uint64 // [63:56] in milliseconds
+	NDWINTimeMinLow    uint64 // [71:64] in milliseconds
+	_                  [56]byte
+
+	DTWINReadsEstimate  uint64 // [135:128] in 4KiB unit
+	DTWINWritesEstimate uint64 // [143:136] in the optimal write size unit
+	DTWINTimeEstimate   uint64 // [151:144] in milliseconds
+	_                   [360]byte
+}
+
+// WindowStatus returns the window which the NVM Set is currently operated in.
+//goland:noinspection GoExportedFuncWithUnexportedType
+func (p *PredLatNVMSet) WindowStatus() plStatus {
+	return plStatus(p.Status & 0b111)
+}
+
+// GetPredLatNVMSet will retrieve the Predictable Latency Per NVM Set (0Ah) of the NVM Set from NVMe
+// device. The NVM Set is selected by the Log Specific Identifier.
+func GetPredLatNVMSet(file *os.File, nvmSetID uint16, v interface{}) error {
+	return GetLogPage(file, logPagePredLatNVMSet, &LogOptions{LSI: nvmSetID}, v)
+}
+
+// ParsePredLatNVMSet parses the Predictable Latency Per NVM Set from raw data. If the size of raw
+// data is under 512B, this function raises an error.
+func ParsePredLatNVMSet(raw []byte) (*PredLatNVMSet, error) {
+	if len(raw) < int(unsafe.Sizeof(PredLatNVMSet{})) {
+		return nil, fmt.Errorf("unexpected predictable latency per nvm set data size: %d", len(raw))
+	}
+
+	p := PredLatNVMSet{}
+	if err := binary.Read(bytes.NewReader(raw), utils.SystemEndian, &p); err == nil {
+		return &p, nil
+	} else {
+		return nil, err
+	}
+}
+
+// GetPredLatEventAggregate will retrieve the Predictable Latency Event Aggregate (0Bh) from NVMe
+// device and returns the identifiers of NVM Sets which have the pending predictable latency events.
+// If retain is false, the Predictable Latency Event asynchronous event is cleared by this read.
+func GetPredLatEventAggregate(file *os.File, retain bool) ([]uint16, error) {
+	return getEventAggregate(file, logPagePredLatEvt, retain)
+}
+
+// ParsePredLatEventAggregate parses the NVM Set identifiers from raw data of the Predictable Latency
+// Event Aggregate. If the raw data is shorter than the number of entries, this function raises an
+// error.
+func ParsePredLatEventAggregate(raw []byte) ([]uint16, error) {
+	return parseEventAggregate(raw)
+}
@@ -0,0 +1,81 @@
+package getlog
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/sungup/go-nvmecli/pkg/utils"
+	"testing"
+	"unsafe"
+)
+
+func TestPredLatNVMSetSize(t *testing.T) {
+	a := assert.New(t)
+
+	a.Equal(uintptr(512), unsafe.Sizeof(PredLatNVMSet{}))
+}
+
+func TestParsePredLatNVMSet(t *testing.T) {
+	a := assert.New(t)
+
+	raw := make([]byte, 512)
+	raw[0] = byte(PLStatusNDWIN)
+	utils.SystemEndian.PutUint16(raw[2:], 1<<14|1<<1)
+	utils.SystemEndian.PutUint64(raw[32:], 100)
+	utils.SystemEndian.PutUint64(raw[48:], 300)
+	utils.SystemEndian.PutUint64(raw[144:], 250)
+
+	// check invalid size error
+	tested, err := ParsePredLatNVMSet(raw[1:])
+	a.Error(err)
+	a.Nil(tested)
+
+	// check normal parsing
+	tested, err = ParsePredLatNVMSet(raw)
+	a.NoError(err)
+	a.Equal(PLStatusNDWIN, tested.WindowStatus())
+	a.False(tested.EventType.DTWINReadsWarning())
+	a.True(tested.EventType.DTWINWritesWarning())
+	a.False(tested.EventType.DTWINTimeWarning())
+	a.True(tested.EventType.AutonomousTransition())
+	a.False(tested.EventType.DeterministicExcursion())
+
+	// deterministic excursion is bit 15, and it is distinguished from the autonomous transition
+	excursion := plEventType(1 << 15)
+	a.True(excursion.DeterministicExcursion())
+	a.False(excursion.AutonomousTransition())
+
+	a.Equal(uint64(100), tested.DTWINReadsTypical)
+	a.Equal(uint64(300), tested.DTWINTimeMaximum)
+	a.Equal(uint64(250), tested.DTWINTimeEstimate)
+}
+
+func TestParsePredLatEventAggregate(t *testing.T) {
+	a := assert.New(t)
+
+	expected := []uint16{1, 3, 7}
+
+	raw := make([]byte, eventAggregateHeaderSz+len(expected)*2)
+	utils.SystemEndian.PutUint64(raw, uint64(len(expected)))
+	for i, id := range expected {
+		utils.SystemEndian.PutUint16(raw[eventAggregateHeaderSz+i*2:], id)
+	}
+
+	// check too short header
+	tested, err := ParsePredLatEventAggregate(raw[:eventAggregateHeaderSz-1])
+	a.Error(err)
+	a.Nil(tested)
+
+	// check the entries over the raw data
+	tested, err = ParsePredLatEventAggregate(raw[:len(raw)-1])
+	a.Error(err)
+	a.Nil(tested)
+
+	// check normal parsing
+	tested, err = ParsePredLatEventAggregate(raw)
+	a.NoError(err)
+	a.Equal(expected, tested)
+
+	// check empty list
+	tested, err = ParsePredLatEventAggregate(make([]byte, 512))
+	a.NoError(err)
+	a.Empty(tested)
+}
@@ -0,0 +1,33 @@
+// +build with_phys_device
+
+package getlog
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"testing"
+)
+
+func TestGetPredLatNVMSet(t *testing.T) {
+	// TODO re-verify this test code using the I/O determinism support NVMe device
+	/*
+		a := assert.New(t)
+
+		dev, _ := os.Open(targetDevice)
+
+		tested := PredLatNVMSet{}
+		a.NoError(GetPredLatNVMSet(dev, 1, &tested))
+		a.NotZero(tested.DTWINTimeMaximum)
+	*/
+}
+
+func TestGetPredLatEventAggregate(t *testing.T) {
+	a := assert.New(t)
+
+	dev, _ := os.Open(targetDevice)
+
+	// the event aggregate can be empty, but retaining the asynchronous event should be succeeded.
+	if tested, err := GetPredLatEventAggregate(dev, true); err == nil {
+		a.NotNil(tested)
+	}
+}
@@ -0,0 +1,123 @@
+package getlog
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"github.com/sungup/go-nvmecli/pkg/utils"
+	"math"
+	"os"
+	"time"
+	"unsafe"
+)
+
+// ------------------------ //
+// LID 81h: Sanitize Status //
+// ------------------------ //
+
+// sanitizeState is the status of the most recent sanitize operation.
+type sanitizeState uint8
+
+const (
+	SanitizeNever        = sanitizeState(0x0) // the NVM subsystem has never been sanitized
+	SanitizeCompleted    = sanitizeState(0x1) // the most recent sanitize operation completed successfully
+	SanitizeInProgress   = sanitizeState(0x2) // a sanitize operation is in progress
+	SanitizeFailed       = sanitizeState(0x3) // the most recent sanitize operation failed
+	SanitizeCompletedNDA = sanitizeState(0x4) // completed successfully with deallocation not performed
+
+	// noEstimate is the estimated time value which means the time is not reported.
+	noEstimate = uint32(math.MaxUint32)
+)
+
+// String returns the name of the sanitize status.
+func (s sanitizeState) String() string {
+	switch s {
+	case SanitizeNever:
+		return "Never Sanitized"
+	case SanitizeCompleted:
+		return "Completed"
+	case SanitizeInProgress:
+		return "In Progress"
+	case SanitizeFailed:
+		return "Failed"
+	case SanitizeCompletedNDA:
+		return "Completed without Deallocation"
+	default:
+		return fmt.Sprintf("Reserved (%Xh)", uint8(s))
+	}
+}
+
+// SanitizeStatus is the Sanitize Status log page which reports the progress and the status of the
+// sanitize operation. The estimated times are in seconds.
+type SanitizeStatus struct {
+	SPROG  uint16 // [01:00] Sanitize Progress, numerator of the fraction of 65536
+	SSTAT  uint16 // [03:02] Sanitize Status
+	SCDW10 uint32 // [07:04] Sanitize Command Dword 10 of the most recent sanitize command
+
+	EstOverwrite     uint32 // [11:08] Estimated Time For Overwrite
+	EstBlockErase    uint32 // [15:12] Estimated Time For Block Erase
+	EstCryptoErase   uint32 // [19:16] Estimated Time For Crypto Erase
+	EstOverwriteND   uint32 // [23:20] Estimated Time For Overwrite With No-Deallocate Media Modification
+	EstBlockEraseND  uint32 // [27:24] Estimated Time For Block Erase With No-Deallocate Media Modification
+	EstCryptoEraseND uint32 // [31:28] Estimated Time For Crypto Erase With No-Deallocate Media Modification
+
+	_ [480]byte // [511:32] reserved
+}
+
+// State returns the status of the most recent sanitize operation.
+//goland:noinspection GoExportedFuncWithUnexportedType
+func (s *SanitizeStatus) State() sanitizeState {
+	return sanitizeState(s.SSTAT & 0x07)
+}
+
+// Progress returns the fraction of the sanitize operation in progress in the range of [0, 1). If
+// no sanitize operation is in progress, Progress returns 1.
+func (s *SanitizeStatus) Progress() float64 {
+	if s.State() != SanitizeInProgress {
+		return 1
+	}
+
+	return float64(s.SPROG) / 65536
+}
+
+// OverwritePasses returns the number of completed passes of the overwrite sanitize operation.
+func (s *SanitizeStatus) OverwritePasses() uint8 {
+	return uint8(s.SSTAT>>3) & 0x1F
+}
+
+// GlobalDataErased returns true if no user data has been written since the NVM subsystem was
+// manufactured or the most recent successful sanitize operation.
+func (s *SanitizeStatus) GlobalDataErased() bool {
+	return s.SSTAT&(1<<8) != 0
+}
+
+// Estimate converts the estimated time field to time.Duration. If the time is not reported,
+// Estimate returns false.
+func (s *SanitizeStatus) Estimate(field uint32) (time.Duration, bool) {
+	if field == noEstimate {
+		return 0, false
+	}
+
+	return time.Duration(field) * time.Second, true
+}
+
+// GetSanitizeStatus retrieves the Sanitize Status log page into v. The sanitize operation completed
+// asynchronous event is cleared unless retain is true.
+func GetSanitizeStatus(file *os.File, retain bool, v interface{}) error {
+	return GetLogPage(file, logPageSanitizeStatus, &LogOptions{RAE: retain}, v)
+}
+
+// ParseSanitizeStatus parses the Sanitize Status log page from raw data. If the size of raw data is
+// not 512B, this function raises an error.
+func ParseSanitizeStatus(raw []byte) (*SanitizeStatus, error) {
+	if len(raw) != int(unsafe.Sizeof(SanitizeStatus{})) {
+		return nil, fmt.Errorf("unexpected sanitize status raw data size: %d", len(raw))
+	}
+
+	s := SanitizeStatus{}
+	if err := binary.Read(bytes.NewReader(raw), utils.SystemEndian, &s); err != nil {
+		return nil, err
+	}
+
+	return &s, nil
+}
@@ -0,0 +1,59 @@
+package getlog
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/sungup/go-nvmecli/pkg/utils"
+	"testing"
+	"time"
+	"unsafe"
+)
+
+func TestSanitizeStatusSize(t *testing.T) {
+	a := assert.New(t)
+
+	a.Equal(uintptr(512), unsafe.Sizeof(SanitizeStatus{}))
+}
+
+func TestSanitizeState_String(t *testing.T) {
+	a := assert.New(t)
+
+	a.Equal("In Progress", SanitizeInProgress.String())
+	a.Equal("Completed without Deallocation", SanitizeCompletedNDA.String())
+	a.Equal("Reserved (7h)", sanitizeState(7).String())
+}
+
+func TestParseSanitizeStatus(t *testing.T) {
+	a := assert.New(t)
+
+	raw := make([]byte, 512)
+	utils.SystemEndian.PutUint16(raw[0:], 0x8000)
+	utils.SystemEndian.PutUint16(raw[2:], 1<<8|3<<3|uint16(SanitizeInProgress))
+	utils.SystemEndian.PutUint32(raw[4:], 0x33)
+	utils.SystemEndian.PutUint32(raw[8:], 120)
+	utils.SystemEndian.PutUint32(raw[12:], noEstimate)
+
+	tested, err := ParseSanitizeStatus(raw)
+	a.NoError(err)
+	a.NotNil(tested)
+
+	a.Equal(SanitizeInProgress, tested.State())
+	a.Equal(0.5, tested.Progress())
+	a.Equal(uint8(3), tested.OverwritePasses())
+	a.True(tested.GlobalDataErased())
+	a.Equal(uint32(0x33), tested.SCDW10)
+
+	estimated, ok := tested.Estimate(tested.EstOverwrite)
+	a.True(ok)
+	a.Equal(2*time.Minute, estimated)
+
+	_, ok = tested.Estimate(tested.EstBlockErase)
+	a.False(ok)
+
+	// no sanitize operation in progress
+	tested.SSTAT = uint16(SanitizeCompleted)
+	a.Equal(1.0, tested.Progress())
+	a.False(tested.GlobalDataErased())
+
+	_, err = ParseSanitizeStatus(raw[:511])
+	a.Error(err)
+}
@@ -0,0 +1,21 @@
+// +build with_phys_device
+
+package getlog
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"testing"
+)
+
+func TestGetSanitizeStatus(t *testing.T) {
+	a := assert.New(t)
+
+	dev, _ := os.Open(targetDevice)
+
+	// the controller which doesn't support sanitize may not support the log page
+	status := SanitizeStatus{}
+	if err := GetSanitizeStatus(dev, true, &status); err == nil {
+		a.LessOrEqual(uint8(status.State()), uint8(SanitizeCompletedNDA))
+	}
+}
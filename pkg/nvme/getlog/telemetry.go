@@ -3,10 +3,14 @@ package getlog
 import (
 	"bytes"
 	"encoding/binary"
+	"encoding/json"
+	"errors"
 	"fmt"
-	"github.com/sungup/go-nvmecli/pkg/nvme"
+	"github.com/sungup/go-nvmecli/pkg/nvme/identify"
+	"github.com/sungup/go-nvmecli/pkg/nvme/spec"
 	"github.com/sungup/go-nvmecli/pkg/nvme/types"
 	"github.com/sungup/go-nvmecli/pkg/utils"
+	"io"
 	"os"
 )
 
@@ -20,69 +24,255 @@ const (
 	DataBlock1 = telemetryDataBlk(1)
 	DataBlock2 = telemetryDataBlk(2)
 	DataBlock3 = telemetryDataBlk(3)
-
-	maxTelemetryPageSz = uint32(4096)
+	DataBlock4 = telemetryDataBlk(4)
 
 	telemetryHeaderSz   = uint32(512)
 	telemetryBlkSzShift = 9
+
+	// telemetryCopySz is the size of the telemetry data read at once while streaming. The generation
+	// number is checked after each read.
+	telemetryCopySz = 1 << 20
+
+	// lpaTelemetryDA4 is the LPA bit which indicates the Telemetry Data Area 4 is supported.
+	lpaTelemetryDA4 = uint8(1 << 6)
 )
 
+// ErrTelemetryChanged is raised when the telemetry data has been changed while reading.
+var ErrTelemetryChanged = errors.New("telemetry data generation changed while reading")
+
 // index function change the telemetryDataBlk macro to index of Telemetry.DataBlockLast's index
 func (b telemetryDataBlk) index() int {
 	return int(b) - 1
 }
 
-// getLogTelemetry retrieve telemetry data from NVMe device. Host-initiated and Ctrl-initiated
-// telemetry has same format except lsp field, so this function receive the lid to determine the
-// get-log Log Identifier and the lsp to create telemetry data for the Host-initiated telemetry.
-func getLogTelemetry(file *os.File, block telemetryDataBlk, lid, lsp uint8) ([]byte, error) {
-	var (
-		header *Telemetry
-		err    error
-	)
+// valid returns true if the data block is in Data Area 1 to 4.
+func (b telemetryDataBlk) valid() bool {
+	return DataBlock1 <= b && b <= DataBlock4
+}
+
+// TelemetryArea is the boundary of a data area in the captured telemetry log.
+type TelemetryArea struct {
+	Area   int   `json:"area"`
+	Offset int64 `json:"offset"`
+	Size   int64 `json:"size"`
+}
+
+// TelemetryManifest describes the captured telemetry log with the generation number and the data
+// area boundaries. The captured size includes the 512B header.
+type TelemetryManifest struct {
+	LID        spec.LogID      `json:"lid"`
+	Generation uint8           `json:"generation"`
+	Size       int64           `json:"size"`
+	Areas      []TelemetryArea `json:"areas"`
+}
+
+// Write writes the manifest as a JSON document.
+func (m *TelemetryManifest) Write(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(m)
+}
 
-	buffer := make([]byte, maxTelemetryPageSz)
-	cmd, _ := newGetLogCmd(0, 0, lid, lsp, 0, buffer)
+// Sections splits the captured telemetry log into the io.SectionReader of each data area.
+//goland:noinspection GoExportedFuncWithUnexportedType
+func (m *TelemetryManifest) Sections(r io.ReaderAt) map[telemetryDataBlk]*io.SectionReader {
+	sections := make(map[telemetryDataBlk]*io.SectionReader, len(m.Areas))
 
+	for _, area := range m.Areas {
+		sections[telemetryDataBlk(area.Area)] = io.NewSectionReader(r, area.Offset, area.Size)
+	}
+
+	return sections
+}
+
+// ReadTelemetryManifest reads the manifest JSON document written by TelemetryManifest.Write.
+func ReadTelemetryManifest(r io.Reader) (*TelemetryManifest, error) {
+	m := TelemetryManifest{}
+	if err := json.NewDecoder(r).Decode(&m); err != nil {
+		return nil, err
+	}
+
+	return &m, nil
+}
+
+// readTelemetryHeader reads the 512B telemetry header with the lsp into buffer.
+func readTelemetryHeader(file *os.File, lid spec.LogID, lsp uint8, buffer []byte) (*Telemetry, error) {
+	if err := GetLogPage(file, lid, &LogOptions{LSP: lsp}, buffer[:telemetryHeaderSz]); err != nil {
+		return nil, err
+	}
+
+	return ParseTelemetryHeader(buffer)
+}
+
+// streamTelemetry writes the telemetry log up to the data block into w. The header is retrieved
+// with the lsp, and the data areas are read through the LogReader. Before writing each read data,
+// the generation number is checked with the header again, so the data in w is consistent with the
+// header unless ErrTelemetryChanged is raised.
+func streamTelemetry(file *os.File, ctrl *identify.CtrlIdentify, block telemetryDataBlk, lid spec.LogID, lsp uint8, w io.Writer) (*TelemetryManifest, error) {
 	// 1. get Telemetry header logs with lsp value
-	cmd.DWords(telemetryHeaderSz >> 2)
+	buffer := make([]byte, telemetryCopySz)
+
+	header, err := readTelemetryHeader(file, lid, lsp, buffer)
+	if err != nil {
+		return nil, err
+	}
 
-	if err = nvme.IOCtlAdminCmd(file, &cmd.AdminCmd); err != nil {
+	m := header.Manifest(block)
+	if _, err = w.Write(buffer[:telemetryHeaderSz]); err != nil {
 		return nil, err
 	}
 
-	if header, err = ParseTelemetryHeader(buffer); err != nil {
+	if m.Size == int64(telemetryHeaderSz) {
+		return m, nil
+	}
+
+	// 2. retrieving Telemetry log after the header through the LogReader
+	reader, err := newLogReader(file, lid, m.Size, nil, ctrl)
+	if err != nil {
 		return nil, err
 	}
 
-	// 2. calculate retrieving data size, create return data and resize buffer
-	dataSz := header.BlockSize(block)
-	fetchSz := maxTelemetryPageSz
+	current := Telemetry{}
+	check := make([]byte, telemetryHeaderSz)
+
+	for offset := int64(telemetryHeaderSz); offset < m.Size; {
+		n, err := reader.ReadAt(buffer, offset)
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+
+		// 3. the data is valid only if the generation number is not changed after reading
+		if err = GetLogPage(file, lid, nil, check); err != nil {
+			return nil, err
+		} else if err = binary.Read(bytes.NewReader(check), utils.SystemEndian, &current); err != nil {
+			return nil, err
+		} else if current.Generation() != m.Generation {
+			return nil, fmt.Errorf("%w: %d -> %d", ErrTelemetryChanged, m.Generation, current.Generation())
+		}
+
+		if _, err = w.Write(buffer[:n]); err != nil {
+			return nil, err
+		}
+
+		offset += int64(n)
+	}
+
+	return m, nil
+}
 
-	cmd.DWords(fetchSz >> 2)
-	cmd.LSP(0x0)
+// captureTelemetry streams the telemetry log into w. If the telemetry data has been changed while
+// reading and rewind is not nil, captureTelemetry rewinds w and retries up to the retry limit.
+func captureTelemetry(file *os.File, block telemetryDataBlk, lid spec.LogID, lsp uint8, w io.Writer, rewind func() error) (*TelemetryManifest, error) {
+	if !block.valid() {
+		return nil, fmt.Errorf("invalid telemetry data area: %d", block)
+	}
 
-	data := make([]byte, 0, telemetryHeaderSz+dataSz)
-	data = append(data, buffer[:telemetryHeaderSz]...)
+	idCtrl := identify.CtrlIdentify{}
+	if err := identify.GetCtrlIdentify(file, &idCtrl); err != nil {
+		return nil, err
+	} else if block == DataBlock4 && uint8(idCtrl.LPA)&lpaTelemetryDA4 == 0 {
+		return nil, fmt.Errorf("telemetry data area 4 is not supported by the controller")
+	}
 
-	// 3. retrieving Telemetry log by basic page size
-	for offset := telemetryHeaderSz; offset < dataSz; offset += fetchSz {
-		// 3-1. update offset and dwords of getLogCmd
-		if dataSz < offset+fetchSz {
-			fetchSz = dataSz - offset
-			cmd.DWords(fetchSz >> 2)
+	for retry := 0; ; retry++ {
+		m, err := streamTelemetry(file, &idCtrl, block, lid, lsp, w)
+		if err == nil || !errors.Is(err, ErrTelemetryChanged) || rewind == nil || retry == maxLogRetry {
+			return m, err
 		}
-		cmd.Offset(uint64(offset))
 
-		// 3-2. resend ioctl to device
-		if err := nvme.IOCtlAdminCmd(file, &cmd.AdminCmd); err != nil {
+		if err = rewind(); err != nil {
 			return nil, err
 		}
+	}
+}
+
+// writerRewinder returns the function to rewind w to the current position if w is an io.Seeker.
+// After rewinding, the longer data of the previous try remains in w, so the truncate function
+// truncates w at the end of the capture if w has Truncate like os.File.
+func writerRewinder(w io.Writer) (rewind func() error, truncate func(size int64) error, err error) {
+	type truncater interface {
+		Truncate(size int64) error
+	}
+
+	seeker, ok := w.(io.Seeker)
+	if !ok {
+		return nil, func(int64) error { return nil }, nil
+	}
+
+	start, err := seeker.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rewound := false
+	rewind = func() error {
+		rewound = true
+		_, err := seeker.Seek(start, io.SeekStart)
+		return err
+	}
+
+	truncate = func(size int64) error {
+		if t, ok := w.(truncater); ok && rewound {
+			return t.Truncate(start + size)
+		}
+
+		return nil
+	}
+
+	return rewind, truncate, nil
+}
+
+// streamTelemetryTo captures the telemetry log into w with rewinding if w is an io.Seeker.
+func streamTelemetryTo(file *os.File, block telemetryDataBlk, lid spec.LogID, lsp uint8, w io.Writer) (*TelemetryManifest, error) {
+	rewind, truncate, err := writerRewinder(w)
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := captureTelemetry(file, block, lid, lsp, w, rewind)
+	if err != nil {
+		return nil, err
+	}
+
+	return m, truncate(m.Size)
+}
+
+// CaptureTelemetryHostInit streams the host-initiated telemetry log up to the data block into w
+// without loading the whole log into the memory, and returns the manifest of the captured log. If
+// the telemetry data has been changed while reading, the capture is retried only when w is an
+// io.Seeker. Otherwise, ErrTelemetryChanged is returned and the data written in w is invalid.
+func CaptureTelemetryHostInit(file *os.File, block telemetryDataBlk, create bool, w io.Writer) (*TelemetryManifest, error) {
+	var lsp uint8 = 0x00
+	if create {
+		lsp = 0x01
+	}
+
+	return streamTelemetryTo(file, block, logPageTelemetryHost, lsp, w)
+}
+
+// CaptureTelemetryCtrlInit streams the controller-initiated telemetry log up to the data block into
+// w like CaptureTelemetryHostInit.
+func CaptureTelemetryCtrlInit(file *os.File, block telemetryDataBlk, w io.Writer) (*TelemetryManifest, error) {
+	return streamTelemetryTo(file, block, logPageTelemetryCtrl, 0x0, w)
+}
+
+// getLogTelemetry retrieve telemetry data from NVMe device. Host-initiated and Ctrl-initiated
+// telemetry has same format except lsp field, so this function receive the lid to determine the
+// get-log Log Identifier and the lsp to create telemetry data for the Host-initiated telemetry.
+func getLogTelemetry(file *os.File, block telemetryDataBlk, lid spec.LogID, lsp uint8) ([]byte, error) {
+	buffer := new(bytes.Buffer)
+
+	rewind := func() error {
+		buffer.Reset()
+		return nil
+	}
 
-		data = append(data, buffer[:fetchSz]...)
+	if _, err := captureTelemetry(file, block, lid, lsp, buffer, rewind); err != nil {
+		return nil, err
 	}
 
-	return data, nil
+	return buffer.Bytes(), nil
 }
 
 // GetTelemetryHostInit retrieves the host-initiated telemetry data from NVMe device. If host sw
@@ -107,22 +297,101 @@ func GetTelemetryCtrlInit(file *os.File, block telemetryDataBlk) ([]byte, error)
 // retrieve the telemetry log with one more ioctl command. Host-initiated log and Controller-
 // initiated log have same format.
 type Telemetry struct {
-	Identifier byte       // [00]
+	Identifier spec.LogID // [00]
 	_          [4]byte    // [04:01] reserved
 	IEEE       types.IEEE // [07:05]
 
-	DataAreaLastBlock [3]uint16 // [13:08]
+	DataAreaLastBlock  [3]uint16 // [13:08]
+	_                  [2]byte   // [15:14] reserved
+	DataArea4LastBlock uint32    // [19:16] valid only if ETDAS of Host Behavior Support is set
 
-	_ [368]byte // [381:14] reserved
+	_ [361]byte // [380:20] reserved
 
+	HostInitiativeGenerationNo uint8     // [381] reserved on the controller-initiated log
 	CtrlInitiativeAvailable    uint8     // [382]
 	CtrlInitiativeGenerationNo uint8     // [383]
 	ReasonIdentifier           [128]byte // [511:384]
 }
 
+// lastBlock returns the last block of the data block.
+func (t *Telemetry) lastBlock(block telemetryDataBlk) uint32 {
+	if block == DataBlock4 {
+		return t.DataArea4LastBlock
+	}
+
+	return uint32(t.DataAreaLastBlock[block.index()])
+}
+
 // BlockSize returns the Byte unit each data block size calculating the DataAreaLastBlock.
-func (t *Telemetry) BlockSize(block telemetryDataBlk) uint32 {
-	return uint32(t.DataAreaLastBlock[block.index()]) << telemetryBlkSzShift
+func (t *Telemetry) BlockSize(block telemetryDataBlk) uint64 {
+	return uint64(t.lastBlock(block)) << telemetryBlkSzShift
+}
+
+// Generation returns the data generation number of the log page. The host-initiated log uses the
+// host-initiated generation number and the controller-initiated log uses the controller-initiated
+// generation number.
+func (t *Telemetry) Generation() uint8 {
+	if t.Identifier == logPageTelemetryHost {
+		return t.HostInitiativeGenerationNo
+	}
+
+	return t.CtrlInitiativeGenerationNo
+}
+
+// Manifest returns the manifest of the telemetry log up to the data block. Data Area n starts
+// after the last block of Data Area n-1, and the header is the block 0.
+func (t *Telemetry) Manifest(block telemetryDataBlk) *TelemetryManifest {
+	m := TelemetryManifest{
+		LID:        t.Identifier,
+		Generation: t.Generation(),
+		Areas:      make([]TelemetryArea, 0, int(block)),
+	}
+
+	prev := int64(0)
+	for area := DataBlock1; area <= block; area++ {
+		last := int64(t.BlockSize(area))
+		if last < prev {
+			last = prev
+		}
+
+		m.Areas = append(m.Areas, TelemetryArea{
+			Area:   int(area),
+			Offset: int64(telemetryHeaderSz) + prev,
+			Size:   last - prev,
+		})
+
+		prev = last
+	}
+
+	// the data area 4 is reported as 0h if ETDAS is not set, so the size is from the last valid area
+	m.Size = int64(telemetryHeaderSz) + prev
+
+	return &m
+}
+
+// SplitTelemetry splits the captured telemetry log into the io.SectionReader of each data area.
+// The data areas not fully captured in size are omitted.
+//goland:noinspection GoExportedFuncWithUnexportedType
+func SplitTelemetry(r io.ReaderAt, size int64) (map[telemetryDataBlk]*io.SectionReader, error) {
+	buffer := make([]byte, telemetryHeaderSz)
+	if _, err := r.ReadAt(buffer, 0); err != nil {
+		return nil, err
+	}
+
+	header, err := ParseTelemetryHeader(buffer)
+	if err != nil {
+		return nil, err
+	}
+
+	m := header.Manifest(DataBlock4)
+	for i, area := range m.Areas {
+		if area.Offset+area.Size > size {
+			m.Areas = m.Areas[:i]
+			break
+		}
+	}
+
+	return m.Sections(r), nil
 }
 
 // ParseTelemetryHeader parses the Telemetry's header information from raw data. If the size of raw
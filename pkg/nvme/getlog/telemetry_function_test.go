@@ -1,7 +1,10 @@
 package getlog
 
 import (
+	"bytes"
 	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
 	"testing"
 	"unsafe"
 )
@@ -12,6 +15,18 @@ func TestTelemetryDataBlk_index(t *testing.T) {
 	a.Equal(0, DataBlock1.index())
 	a.Equal(1, DataBlock2.index())
 	a.Equal(2, DataBlock3.index())
+	a.Equal(3, DataBlock4.index())
+}
+
+func TestTelemetryDataBlk_valid(t *testing.T) {
+	a := assert.New(t)
+
+	for _, block := range []telemetryDataBlk{DataBlock1, DataBlock2, DataBlock3, DataBlock4} {
+		a.True(block.valid())
+	}
+
+	a.False(telemetryDataBlk(0).valid())
+	a.False(telemetryDataBlk(5).valid())
 }
 
 func TestTelemetrySize(t *testing.T) {
@@ -23,15 +38,132 @@ func TestTelemetrySize(t *testing.T) {
 func TestTelemetry_BlockSize(t *testing.T) {
 	a := assert.New(t)
 
-	expectedBlk1 := uint32(2 * 512)
-	expectedBlk2 := uint32(4 * 512)
-	expectedBlk3 := uint32(8 * 512)
+	expectedBlk1 := uint64(2 * 512)
+	expectedBlk2 := uint64(4 * 512)
+	expectedBlk3 := uint64(8 * 512)
+	expectedBlk4 := uint64(0x10000000) * 512
 
 	tested := Telemetry{
-		DataAreaLastBlock: [3]uint16{2, 4, 8},
+		DataAreaLastBlock:  [3]uint16{2, 4, 8},
+		DataArea4LastBlock: 0x10000000,
 	}
 
 	a.Equal(expectedBlk1, tested.BlockSize(DataBlock1))
 	a.Equal(expectedBlk2, tested.BlockSize(DataBlock2))
 	a.Equal(expectedBlk3, tested.BlockSize(DataBlock3))
+	a.Equal(expectedBlk4, tested.BlockSize(DataBlock4))
+}
+
+func TestTelemetry_Generation(t *testing.T) {
+	a := assert.New(t)
+
+	tested := Telemetry{HostInitiativeGenerationNo: 3, CtrlInitiativeGenerationNo: 7}
+
+	tested.Identifier = logPageTelemetryHost
+	a.Equal(uint8(3), tested.Generation())
+
+	tested.Identifier = logPageTelemetryCtrl
+	a.Equal(uint8(7), tested.Generation())
+}
+
+func TestTelemetry_Manifest(t *testing.T) {
+	a := assert.New(t)
+
+	tested := Telemetry{
+		Identifier:                 logPageTelemetryCtrl,
+		CtrlInitiativeGenerationNo: 2,
+		DataAreaLastBlock:          [3]uint16{2, 2, 8},
+	}
+
+	m := tested.Manifest(DataBlock3)
+	a.Equal(logPageTelemetryCtrl, m.LID)
+	a.Equal(uint8(2), m.Generation)
+	a.Equal(int64(9*512), m.Size)
+	a.Equal([]TelemetryArea{
+		{Area: 1, Offset: 512, Size: 2 * 512},
+		{Area: 2, Offset: 3 * 512, Size: 0},
+		{Area: 3, Offset: 3 * 512, Size: 6 * 512},
+	}, m.Areas)
+
+	// data area 4 is 0h if ETDAS is not set, so the size is not shrunk.
+	m = tested.Manifest(DataBlock4)
+	a.Equal(int64(9*512), m.Size)
+	a.Equal(TelemetryArea{Area: 4, Offset: 9 * 512, Size: 0}, m.Areas[3])
+
+	// write and read manifest
+	buffer := new(bytes.Buffer)
+	a.NoError(m.Write(buffer))
+
+	read, err := ReadTelemetryManifest(buffer)
+	a.NoError(err)
+	a.Equal(m, read)
+}
+
+func TestSplitTelemetry(t *testing.T) {
+	a := assert.New(t)
+
+	raw := make([]byte, 7*512)
+	raw[0] = uint8(logPageTelemetryHost)
+	raw[8], raw[10], raw[12] = 2, 4, 8
+
+	for i := 512; i < len(raw); i++ {
+		raw[i] = byte(i / 512)
+	}
+
+	// check too short header
+	tested, err := SplitTelemetry(bytes.NewReader(raw[:511]), 511)
+	a.Error(err)
+	a.Nil(tested)
+
+	// data area 3 is not captured fully
+	tested, err = SplitTelemetry(bytes.NewReader(raw), int64(len(raw)))
+	a.NoError(err)
+	a.Len(tested, 2)
+
+	for block, expected := range map[telemetryDataBlk][]byte{
+		DataBlock1: raw[512 : 3*512],
+		DataBlock2: raw[3*512 : 5*512],
+	} {
+		section, ok := tested[block]
+		a.True(ok)
+
+		data, err := ioutil.ReadAll(section)
+		a.NoError(err)
+		a.Equal(expected, data)
+	}
+}
+
+func TestWriterRewinder(t *testing.T) {
+	a := assert.New(t)
+
+	// not seekable writer can't be rewound
+	rewind, truncate, err := writerRewinder(new(bytes.Buffer))
+	a.NoError(err)
+	a.Nil(rewind)
+	a.NoError(truncate(0))
+
+	file, err := ioutil.TempFile("", "telemetry")
+	a.NoError(err)
+	defer func() { _ = os.Remove(file.Name()) }()
+	defer func() { _ = file.Close() }()
+
+	_, _ = file.Write([]byte("prefix"))
+
+	rewind, truncate, err = writerRewinder(file)
+	a.NoError(err)
+
+	// without rewinding, truncate doesn't change the file
+	_, _ = file.Write([]byte("first try"))
+	a.NoError(truncate(5))
+
+	info, _ := file.Stat()
+	a.Equal(int64(15), info.Size())
+
+	// after rewinding, the remained data of the previous try is truncated
+	a.NoError(rewind())
+	_, _ = file.Write([]byte("retry"))
+	a.NoError(truncate(5))
+
+	data, _ := ioutil.ReadFile(file.Name())
+	a.Equal("prefixretry", string(data))
 }
@@ -9,6 +9,7 @@ import (
 	"github.com/sungup/go-nvmecli/pkg/utils"
 	"math"
 	"os"
+	"sort"
 	"unsafe"
 )
 
@@ -23,6 +24,11 @@ const (
 	cnsActiveNSList = uint16(0x02)
 	cnsNSDescList   = uint16(0x03)
 	cnsNVMSetList   = uint16(0x04)
+
+	cnsAllocatedNSList = uint16(0x10)
+	cnsAllocatedNS     = uint16(0x11)
+	cnsNSCtrlList      = uint16(0x12)
+	cnsCtrlList        = uint16(0x13)
 )
 
 // newIdentifyCmd generates an AdminCmd structure to retrieve the NVMe's identify related structure.
@@ -211,6 +217,154 @@ func GetNamespaceIdentify(file *os.File, nsid uint32, v interface{}) error {
 	}
 }
 
+// NamespaceList is a list of up to 1024 namespace identifiers in increasing order. Unused entries
+// are cleared to 0h.
+type NamespaceList [1024]uint32
+
+// IDs returns the namespace identifiers until the first unused entry.
+func (l *NamespaceList) IDs() []uint32 {
+	ids := make([]uint32, 0, len(l))
+
+	for _, nsid := range l {
+		if nsid == 0 {
+			break
+		}
+
+		ids = append(ids, nsid)
+	}
+
+	return ids
+}
+
+// GetActiveNsList fills v interface with the active namespace list. The list contains the active
+// namespace identifiers greater than nsid.
+func GetActiveNsList(file *os.File, nsid uint32, v interface{}) error {
+	if cmd, err := newIdentifyCmd(nsid, 0, cnsActiveNSList, 0, v); err != nil {
+		return err
+	} else {
+		return nvme.IOCtlAdminCmd(file, cmd)
+	}
+}
+
+// GetAllocatedNsList fills v interface with the allocated namespace list. The list contains the
+// allocated namespace identifiers greater than nsid including the namespaces which are not attached
+// to any controller.
+func GetAllocatedNsList(file *os.File, nsid uint32, v interface{}) error {
+	if cmd, err := newIdentifyCmd(nsid, 0, cnsAllocatedNSList, 0, v); err != nil {
+		return err
+	} else {
+		return nvme.IOCtlAdminCmd(file, cmd)
+	}
+}
+
+// GetAllocatedNamespaceIdentify fills v interface with the namespace identify data of an allocated
+// namespace. Different from GetNamespaceIdentify, the namespace doesn't need to be attached to the
+// controller.
+func GetAllocatedNamespaceIdentify(file *os.File, nsid uint32, v interface{}) error {
+	if cmd, err := newIdentifyCmd(nsid, 0, cnsAllocatedNS, 0, v); err != nil {
+		return err
+	} else {
+		return nvme.IOCtlAdminCmd(file, cmd)
+	}
+}
+
+// listNamespaces returns all namespace identifiers retrieved by the get function. If there are
+// more than 1024 namespaces, listNamespaces retrieves the next list from the last namespace
+// identifier of the previous list.
+func listNamespaces(file *os.File, get func(*os.File, uint32, interface{}) error) ([]uint32, error) {
+	const maxNSID = uint32(0xFFFFFFFD)
+
+	list := NamespaceList{}
+	found := make([]uint32, 0)
+
+	for nsid := uint32(0); nsid < maxNSID; nsid = found[len(found)-1] {
+		if err := get(file, nsid, &list); err != nil {
+			return nil, err
+		}
+
+		ids := list.IDs()
+		if found = append(found, ids...); len(ids) < len(list) {
+			break
+		}
+	}
+
+	return found, nil
+}
+
+// ListActiveNamespaces returns all active namespace identifiers of the controller. If there are more
+// than 1024 active namespaces, ListActiveNamespaces retrieves the next list from the last namespace
+// identifier of the previous list.
+func ListActiveNamespaces(file *os.File) ([]uint32, error) {
+	return listNamespaces(file, GetActiveNsList)
+}
+
+// ListAllocatedNamespaces returns all allocated namespace identifiers of the NVM subsystem.
+func ListAllocatedNamespaces(file *os.File) ([]uint32, error) {
+	return listNamespaces(file, GetAllocatedNsList)
+}
+
+// maxCtrlListEntries is the number of controller identifiers in a ControllerList.
+const maxCtrlListEntries = 2047
+
+// ControllerList is a list of up to 2047 controller identifiers in increasing order. It is used
+// for the Identify controller lists and the data of the Namespace Attachment command.
+type ControllerList struct {
+	NumIDs uint16
+	ID     [maxCtrlListEntries]uint16
+}
+
+// NewControllerList creates a ControllerList from the controller identifiers. The identifiers are
+// sorted in increasing order, and the duplicated identifiers raise an error.
+func NewControllerList(ids []uint16) (*ControllerList, error) {
+	if len(ids) > maxCtrlListEntries {
+		return nil, fmt.Errorf("too many controller identifiers: %d", len(ids))
+	}
+
+	sorted := append([]uint16{}, ids...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	l := ControllerList{NumIDs: uint16(len(sorted))}
+	for i, id := range sorted {
+		if i > 0 && sorted[i-1] == id {
+			return nil, fmt.Errorf("duplicated controller identifier: %d", id)
+		}
+
+		l.ID[i] = id
+	}
+
+	return &l, nil
+}
+
+// IDs returns the valid controller identifiers of the list.
+func (l *ControllerList) IDs() []uint16 {
+	n := int(l.NumIDs)
+	if n > len(l.ID) {
+		n = len(l.ID)
+	}
+
+	return append([]uint16{}, l.ID[:n]...)
+}
+
+// GetNsCtrlList fills v interface with the list of controllers attached to the namespace. The list
+// contains the controller identifiers greater than or equal to cntid.
+func GetNsCtrlList(file *os.File, nsid uint32, cntid uint16, v interface{}) error {
+	if cmd, err := newIdentifyCmd(nsid, cntid, cnsNSCtrlList, 0, v); err != nil {
+		return err
+	} else {
+		return nvme.IOCtlAdminCmd(file, cmd)
+	}
+}
+
+// GetCtrlList fills v interface with the list of controllers in the NVM subsystem. The list
+// contains the controller identifiers greater than or equal to cntid.
+func GetCtrlList(file *os.File, cntid uint16, v interface{}) error {
+	if cmd, err := newIdentifyCmd(0, cntid, cnsCtrlList, 0, v); err != nil {
+		return err
+	} else {
+		return nvme.IOCtlAdminCmd(file, cmd)
+	}
+}
+
 // relativePerf is the relative performance of the LBA format indicated relative to other LBA
 // formats supported by the controller.
 type relativePerf uint8
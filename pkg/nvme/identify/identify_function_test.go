@@ -70,3 +70,53 @@ func TestLbaFormat_LBADataSize(t *testing.T) {
 func TestLbaFormat_RelativePerformance(t *testing.T) {
 	// TODO implementing here
 }
+
+func TestNamespaceListSize(t *testing.T) {
+	a := assert.New(t)
+	a.Equal(uintptr(4096), unsafe.Sizeof(NamespaceList{}))
+}
+
+func TestNamespaceList_IDs(t *testing.T) {
+	a := assert.New(t)
+
+	tested := NamespaceList{}
+	a.Empty(tested.IDs())
+
+	tested[0], tested[1], tested[2] = 1, 3, 7
+	a.Equal([]uint32{1, 3, 7}, tested.IDs())
+
+	// entries after the first unused entry are ignored
+	tested[4] = 9
+	a.Equal([]uint32{1, 3, 7}, tested.IDs())
+
+	for i := range tested {
+		tested[i] = uint32(i + 1)
+	}
+	a.Len(tested.IDs(), len(tested))
+}
+
+func TestControllerListSize(t *testing.T) {
+	a := assert.New(t)
+	a.Equal(uintptr(4096), unsafe.Sizeof(ControllerList{}))
+}
+
+func TestNewControllerList(t *testing.T) {
+	a := assert.New(t)
+
+	tested, err := NewControllerList([]uint16{7, 1, 3})
+	a.NoError(err)
+	a.Equal(uint16(3), tested.NumIDs)
+	a.Equal([]uint16{1, 3, 7}, tested.IDs())
+
+	tested, err = NewControllerList(nil)
+	a.NoError(err)
+	a.Empty(tested.IDs())
+
+	// duplicated identifiers
+	_, err = NewControllerList([]uint16{1, 3, 1})
+	a.Error(err)
+
+	// over the list size
+	_, err = NewControllerList(make([]uint16, maxCtrlListEntries+1))
+	a.Error(err)
+}
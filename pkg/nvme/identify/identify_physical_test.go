@@ -86,3 +86,74 @@ func TestParseNamespaceIdentify(t *testing.T) {
 	a.NotEmpty(identify.EUI64.String())
 	a.NotEmpty(identify.NGUID.String())
 }
+
+func TestGetActiveNsList(t *testing.T) {
+	a := assert.New(t)
+
+	dev, _ := os.Open(targetDevice)
+
+	tested := NamespaceList{}
+	a.NoError(GetActiveNsList(dev, 0, &tested))
+	a.Contains(tested.IDs(), uint32(expectedNSId))
+
+	// the list only contains namespaces greater than nsid
+	a.NoError(GetActiveNsList(dev, expectedNSId, &tested))
+	a.NotContains(tested.IDs(), uint32(expectedNSId))
+}
+
+func TestListActiveNamespaces(t *testing.T) {
+	a := assert.New(t)
+
+	dev, _ := os.Open(targetDevice)
+
+	tested, err := ListActiveNamespaces(dev)
+	a.NoError(err)
+	a.Contains(tested, uint32(expectedNSId))
+}
+
+func TestListAllocatedNamespaces(t *testing.T) {
+	a := assert.New(t)
+
+	dev, _ := os.Open(targetDevice)
+
+	active, _ := ListActiveNamespaces(dev)
+
+	// the allocated namespaces include all active namespaces
+	if tested, err := ListAllocatedNamespaces(dev); err == nil {
+		a.Subset(tested, active)
+	}
+}
+
+func TestGetCtrlList(t *testing.T) {
+	a := assert.New(t)
+
+	dev, _ := os.Open(targetDevice)
+
+	idCtrl := CtrlIdentify{}
+	a.NoError(GetCtrlIdentify(dev, &idCtrl))
+
+	tested := ControllerList{}
+	if err := GetCtrlList(dev, 0, &tested); err == nil {
+		a.Contains(tested.IDs(), idCtrl.CNTLID)
+	}
+
+	if err := GetNsCtrlList(dev, expectedNSId, 0, &tested); err == nil {
+		a.Contains(tested.IDs(), idCtrl.CNTLID)
+	}
+}
+
+func TestGetAllocatedNamespaceIdentify(t *testing.T) {
+	a := assert.New(t)
+
+	dev, _ := os.Open(targetDevice)
+
+	expected := NamespaceIdentify{}
+	a.NoError(GetNamespaceIdentify(dev, expectedNSId, &expected))
+
+	// the attached namespace has the same identify data with the allocated namespace identify
+	tested := NamespaceIdentify{}
+	if err := GetAllocatedNamespaceIdentify(dev, expectedNSId, &tested); err == nil {
+		a.Equal(expected.NSZE, tested.NSZE)
+		a.Equal(expected.FLBAS, tested.FLBAS)
+	}
+}
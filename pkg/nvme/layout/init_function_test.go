@@ -0,0 +1,5 @@
+package layout
+
+const (
+	expectedNSId = 1
+)
@@ -0,0 +1,7 @@
+// +build with_phys_device
+
+package layout
+
+const (
+	targetDevice = "/dev/nvme0"
+)
@@ -0,0 +1,109 @@
+package layout
+
+import (
+	"github.com/sungup/go-nvmecli/pkg/nvme/identify"
+	"github.com/sungup/go-nvmecli/pkg/nvme/namespace"
+	"github.com/sungup/go-nvmecli/pkg/nvme/types"
+	"math"
+	"os"
+)
+
+// nmicShared is the NMIC bit of the namespace which can be attached to two or more controllers.
+const nmicShared = uint8(1 << 0)
+
+// Namespace is the current state of an allocated namespace.
+type Namespace struct {
+	NSID         uint32
+	NSZE         uint64
+	NCAP         uint64
+	LBAF         uint8
+	LBASize      int
+	MetadataSize int
+	Shared       bool
+	Controllers  []uint16
+}
+
+// Bytes returns the namespace capacity in bytes.
+func (n *Namespace) Bytes() uint64 {
+	return n.NCAP * uint64(n.LBASize)
+}
+
+// Layout is the current namespace layout of an NVM subsystem retrieved through a controller.
+type Layout struct {
+	CNTLID      uint16 // controller which the layout is retrieved through
+	Capacity    uint64 // total NVM capacity in bytes
+	Unallocated uint64 // unallocated NVM capacity in bytes
+	NN          uint32 // maximum NSID of the controller
+	Namespaces  []Namespace
+
+	// common is the common namespace capabilities to select the LBA format of new namespaces.
+	common identify.NamespaceIdentify
+}
+
+// capBytes converts the 128bit capacity to uint64. The capacity over 16EiB is saturated.
+func capBytes(c types.Uint128) uint64 {
+	if c[1].Uint() != 0 {
+		return math.MaxUint64
+	}
+
+	return c[0].Uint()
+}
+
+// newNamespace converts the namespace identify data to a Namespace.
+func newNamespace(nsid uint32, idNs *identify.NamespaceIdentify, ctrls []uint16) Namespace {
+	lbaf := idNs.FLBAS & 0x0F
+	format := idNs.LBAF[lbaf]
+
+	return Namespace{
+		NSID:         nsid,
+		NSZE:         idNs.NSZE,
+		NCAP:         idNs.NCAP,
+		LBAF:         lbaf,
+		LBASize:      1 << format.LBADataSize(),
+		MetadataSize: format.MetadataSize(),
+		Shared:       idNs.NMIC&nmicShared != 0,
+		Controllers:  ctrls,
+	}
+}
+
+// ReadLayout retrieves the current namespace layout including the namespaces which are not
+// attached to any controller.
+func ReadLayout(file *os.File) (*Layout, error) {
+	idCtrl := identify.CtrlIdentify{}
+	if err := identify.GetCtrlIdentify(file, &idCtrl); err != nil {
+		return nil, err
+	}
+
+	l := Layout{
+		CNTLID:      idCtrl.CNTLID,
+		Capacity:    capBytes(idCtrl.TNVMCAP),
+		Unallocated: capBytes(idCtrl.UNVMCAP),
+		NN:          idCtrl.NN,
+		Namespaces:  make([]Namespace, 0),
+	}
+
+	if err := identify.GetNamespaceIdentify(file, namespace.AllNamespaces, &l.common); err != nil {
+		return nil, err
+	}
+
+	allocated, err := identify.ListAllocatedNamespaces(file)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, nsid := range allocated {
+		idNs := identify.NamespaceIdentify{}
+		if err = identify.GetAllocatedNamespaceIdentify(file, nsid, &idNs); err != nil {
+			return nil, err
+		}
+
+		ctrls := identify.ControllerList{}
+		if err = identify.GetNsCtrlList(file, nsid, 0, &ctrls); err != nil {
+			return nil, err
+		}
+
+		l.Namespaces = append(l.Namespaces, newNamespace(nsid, &idNs, ctrls.IDs()))
+	}
+
+	return &l, nil
+}
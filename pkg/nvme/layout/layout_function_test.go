@@ -0,0 +1,38 @@
+package layout
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/sungup/go-nvmecli/pkg/nvme/identify"
+	"github.com/sungup/go-nvmecli/pkg/nvme/types"
+	"math"
+	"testing"
+)
+
+func TestCapBytes(t *testing.T) {
+	a := assert.New(t)
+
+	a.Zero(capBytes(types.Uint128{}))
+	a.Equal(uint64(1<<32), capBytes(types.Uint128{{0, 0, 0, 0, 1}}))
+	a.Equal(uint64(math.MaxUint64), capBytes(types.Uint128{{}, {1}}))
+}
+
+func TestNewNamespace(t *testing.T) {
+	a := assert.New(t)
+
+	idNs := identify.NamespaceIdentify{NSZE: 100, NCAP: 90, FLBAS: 1, NMIC: nmicShared}
+	idNs.LBAF[0] = 9 << 16
+	idNs.LBAF[1] = 12<<16 | 8
+
+	tested := newNamespace(expectedNSId, &idNs, []uint16{1, 2})
+	a.Equal(Namespace{
+		NSID:         expectedNSId,
+		NSZE:         100,
+		NCAP:         90,
+		LBAF:         1,
+		LBASize:      4096,
+		MetadataSize: 8,
+		Shared:       true,
+		Controllers:  []uint16{1, 2},
+	}, tested)
+	a.Equal(uint64(90*4096), tested.Bytes())
+}
@@ -0,0 +1,25 @@
+// +build with_phys_device
+
+package layout
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/sungup/go-nvmecli/pkg/nvme/identify"
+	"os"
+	"testing"
+)
+
+func TestReadLayout(t *testing.T) {
+	a := assert.New(t)
+
+	dev, _ := os.Open(targetDevice)
+
+	idCtrl := identify.CtrlIdentify{}
+	a.NoError(identify.GetCtrlIdentify(dev, &idCtrl))
+
+	// the namespace management unsupported device can't list the allocated namespaces
+	if tested, err := ReadLayout(dev); err == nil {
+		a.Equal(idCtrl.CNTLID, tested.CNTLID)
+		a.NotEmpty(tested.Namespaces)
+	}
+}
@@ -0,0 +1,430 @@
+package layout
+
+import (
+	"fmt"
+	"github.com/sungup/go-nvmecli/pkg/nvme/format"
+	"github.com/sungup/go-nvmecli/pkg/nvme/namespace"
+	"os"
+	"sort"
+	"strings"
+)
+
+// opKind is the kind of the operation in a Plan. The operations are applied in the order of the
+// kind, so the capacity is released by Detach and Delete before Create.
+type opKind uint8
+
+const (
+	OpDetach = opKind(iota)
+	OpDelete
+	OpCreate
+	OpAttach
+	OpFormat
+)
+
+// String returns the name of the operation kind.
+func (k opKind) String() string {
+	switch k {
+	case OpDetach:
+		return "detach"
+	case OpDelete:
+		return "delete"
+	case OpCreate:
+		return "create"
+	case OpAttach:
+		return "attach"
+	case OpFormat:
+		return "format"
+	default:
+		return fmt.Sprintf("unknown(%d)", uint8(k))
+	}
+}
+
+// Operation is a step of a Plan.
+type Operation struct {
+	Kind opKind
+
+	// NSID is the target namespace. For OpCreate, NSID is the expected identifier of the created
+	// namespace, and 0 means any identifier assigned by the controller.
+	NSID uint32
+
+	// Attributes is the created namespace's attributes of OpCreate.
+	Attributes namespace.Attributes
+
+	// LBAF is the LBA format index of OpFormat.
+	LBAF uint8
+
+	// Controllers is the controller list of OpAttach and OpDetach, and the controllers to attach
+	// the created namespace of OpCreate.
+	Controllers []uint16
+}
+
+// String returns the description of the operation like "attach ns 1 to controllers [1 2]".
+func (o Operation) String() string {
+	switch o.Kind {
+	case OpDetach:
+		return fmt.Sprintf("detach ns %d from controllers %v", o.NSID, o.Controllers)
+	case OpDelete:
+		return fmt.Sprintf("delete ns %d", o.NSID)
+	case OpCreate:
+		return fmt.Sprintf("create ns %d: nsze %d, flbas %d, nmic %d, attach to controllers %v",
+			o.NSID, o.Attributes.NSZE, o.Attributes.FLBAS, o.Attributes.NMIC, o.Controllers)
+	case OpAttach:
+		return fmt.Sprintf("attach ns %d to controllers %v", o.NSID, o.Controllers)
+	case OpFormat:
+		return fmt.Sprintf("format ns %d with LBA format %d", o.NSID, o.LBAF)
+	default:
+		return o.Kind.String()
+	}
+}
+
+// Plan is the list of operations to reconcile the current layout with the spec. An empty plan
+// means the layout already satisfies the spec.
+type Plan []Operation
+
+// String returns the operations line by line.
+func (p Plan) String() string {
+	lines := make([]string, len(p))
+	for i, o := range p {
+		lines[i] = o.String()
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// sizeTolerance is the allowed ratio of the namespace size over the desired size. The controller
+// may round up the namespace size to its allocation granularity.
+const sizeTolerance = 100
+
+// desired is the resolved state of a NamespaceSpec with the device information.
+type desired struct {
+	spec  NamespaceSpec
+	lbaf  uint8
+	nsze  uint64
+	ctrls []uint16
+}
+
+// resolve converts the namespace spec into the LBA format index, the size in logical blocks and
+// the controller list. The existing namespace keeps its LBA format if the spec doesn't select one.
+func (l *Layout) resolve(spec NamespaceSpec, current *Namespace) (*desired, error) {
+	d := desired{spec: spec, ctrls: spec.Controllers}
+
+	if len(d.ctrls) == 0 {
+		d.ctrls = []uint16{l.CNTLID}
+	}
+
+	if current != nil && (spec.LBASize == 0 || spec.LBASize == current.LBASize && spec.MetadataSize == current.MetadataSize) {
+		d.lbaf = current.LBAF
+	} else if spec.LBASize != 0 {
+		if lbaf, err := format.FindLBAF(&l.common, spec.LBASize, spec.MetadataSize); err != nil {
+			return nil, err
+		} else {
+			d.lbaf = lbaf
+		}
+	} else {
+		d.lbaf = l.common.FLBAS & 0x0F
+	}
+
+	shift := l.common.LBAF[d.lbaf].LBADataSize()
+	if current != nil && d.lbaf == current.LBAF {
+		// the LBA format of the common capabilities may be unreported, so use the namespace's one
+		shift = 0
+		for 1<<shift < current.LBASize {
+			shift++
+		}
+	}
+
+	if shift == 0 {
+		return nil, fmt.Errorf("LBA format %d is not supported", d.lbaf)
+	}
+
+	if bytes, err := spec.Size.Bytes(l.Capacity); err != nil {
+		return nil, err
+	} else if d.nsze = bytes >> uint(shift); d.nsze == 0 {
+		return nil, fmt.Errorf("size %q is smaller than a logical block", spec.Size)
+	}
+
+	return &d, nil
+}
+
+// match pairs the namespace specs with the existing namespaces. The spec with NSID matches the
+// namespace of the same NSID, and the other specs match the remained namespaces in increasing NSID
+// order. The unmatched namespaces are returned separately.
+func (l *Layout) match(spec *Spec) ([]*Namespace, []*Namespace) {
+	byNSID := make(map[uint32]*Namespace)
+	for i := range l.Namespaces {
+		byNSID[l.Namespaces[i].NSID] = &l.Namespaces[i]
+	}
+
+	matched := make([]*Namespace, len(spec.Namespaces))
+	claimed := make(map[uint32]bool)
+
+	for i, ns := range spec.Namespaces {
+		if ns.NSID != 0 {
+			claimed[ns.NSID] = true
+			matched[i] = byNSID[ns.NSID]
+		}
+	}
+
+	remained := make([]*Namespace, 0)
+	for i := range l.Namespaces {
+		if !claimed[l.Namespaces[i].NSID] {
+			remained = append(remained, &l.Namespaces[i])
+		}
+	}
+
+	sort.Slice(remained, func(i, j int) bool { return remained[i].NSID < remained[j].NSID })
+
+	for i, ns := range spec.Namespaces {
+		if ns.NSID == 0 && len(remained) > 0 {
+			matched[i], remained = remained[0], remained[1:]
+		}
+	}
+
+	return matched, remained
+}
+
+// diffControllers returns the controllers to attach and to detach.
+func diffControllers(current, desired []uint16) (attach, detach []uint16) {
+	in := func(list []uint16, id uint16) bool {
+		for _, v := range list {
+			if v == id {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	for _, id := range desired {
+		if !in(current, id) {
+			attach = append(attach, id)
+		}
+	}
+
+	for _, id := range current {
+		if !in(desired, id) {
+			detach = append(detach, id)
+		}
+	}
+
+	return attach, detach
+}
+
+// orderCreates orders the create operations to get the namespaces of the spec NSIDs. The controller
+// assigns the lowest free NSID to the created namespace, so the namespace with NSID is created when
+// its NSID is the lowest free one, and the namespaces without NSID fill the free NSIDs below it. If
+// a free NSID below the spec NSID can't be filled, the spec NSID can't be guaranteed.
+func orderCreates(used map[uint32]bool, creates []Operation) ([]Operation, error) {
+	pinned := make(map[uint32]Operation)
+	unpinned := make([]Operation, 0)
+
+	for _, o := range creates {
+		if o.NSID != 0 {
+			pinned[o.NSID] = o
+		} else {
+			unpinned = append(unpinned, o)
+		}
+	}
+
+	ordered := make([]Operation, 0, len(creates))
+
+	for nsid := uint32(1); len(pinned) > 0; nsid++ {
+		if used[nsid] {
+			continue
+		}
+
+		if o, ok := pinned[nsid]; ok {
+			ordered = append(ordered, o)
+			delete(pinned, nsid)
+		} else if len(unpinned) > 0 {
+			ordered, unpinned = append(ordered, unpinned[0]), unpinned[1:]
+		} else {
+			next := uint32(0)
+			for id := range pinned {
+				if next == 0 || id < next {
+					next = id
+				}
+			}
+
+			return nil, fmt.Errorf("ns %d can't be created because the controller assigns the free NSID %d first", next, nsid)
+		}
+	}
+
+	return append(ordered, unpinned...), nil
+}
+
+// NewPlan computes the operations to reconcile the current layout with the spec. The namespace of
+// which size or sharing is different from the spec is deleted and created again, and the one of
+// which LBA format is different is formatted. The plan is rejected if the created namespaces need
+// more capacity than the unallocated capacity after the deletions, or if the NSID of a created
+// namespace is over NN or can't be guaranteed.
+func NewPlan(l *Layout, spec *Spec) (Plan, error) {
+	if err := spec.validate(); err != nil {
+		return nil, err
+	}
+
+	for i, ns := range spec.Namespaces {
+		if ns.NSID > l.NN {
+			return nil, fmt.Errorf("namespace %d: NSID %d is over the maximum NSID %d of the controller", i, ns.NSID, l.NN)
+		}
+	}
+
+	plan := make(Plan, 0)
+	creates := make([]Operation, 0)
+	available := l.Unallocated
+
+	used := make(map[uint32]bool)
+	for _, ns := range l.Namespaces {
+		used[ns.NSID] = true
+	}
+
+	create := func(d *desired) {
+		nmic := uint8(0)
+		if d.spec.Shared {
+			nmic = nmicShared
+		}
+
+		creates = append(creates, Operation{
+			Kind: OpCreate,
+			NSID: d.spec.NSID,
+			Attributes: namespace.Attributes{
+				NSZE:  d.nsze,
+				NCAP:  d.nsze,
+				FLBAS: d.lbaf,
+				NMIC:  nmic,
+			},
+			Controllers: d.ctrls,
+		})
+	}
+
+	remove := func(ns *Namespace) {
+		plan = append(plan, Operation{Kind: OpDelete, NSID: ns.NSID})
+		available += ns.Bytes()
+		delete(used, ns.NSID)
+	}
+
+	matched, unmatched := l.match(spec)
+
+	for _, ns := range unmatched {
+		remove(ns)
+	}
+
+	required := uint64(0)
+
+	for i, ns := range spec.Namespaces {
+		current := matched[i]
+
+		d, err := l.resolve(ns, current)
+		if err != nil {
+			return nil, fmt.Errorf("namespace %d: %v", i, err)
+		}
+
+		if current == nil {
+			create(d)
+			required += d.nsze << uint(l.common.LBAF[d.lbaf].LBADataSize())
+			continue
+		}
+
+		// the size in the current LBA format is compared if the namespace will be formatted
+		nsze := d.nsze
+		if d.lbaf != current.LBAF {
+			nsze = d.nsze << uint(l.common.LBAF[d.lbaf].LBADataSize()) / uint64(current.LBASize)
+		}
+
+		if current.NSZE < nsze || current.NSZE > nsze+nsze/sizeTolerance || current.Shared != ns.Shared {
+			remove(current)
+			create(d)
+			required += d.nsze << uint(l.common.LBAF[d.lbaf].LBADataSize())
+			continue
+		}
+
+		if attach, detach := diffControllers(current.Controllers, d.ctrls); len(detach) > 0 || len(attach) > 0 {
+			if len(detach) > 0 {
+				plan = append(plan, Operation{Kind: OpDetach, NSID: current.NSID, Controllers: detach})
+			}
+
+			if len(attach) > 0 {
+				plan = append(plan, Operation{Kind: OpAttach, NSID: current.NSID, Controllers: attach})
+			}
+		}
+
+		if d.lbaf != current.LBAF {
+			if _, detach := diffControllers([]uint16{l.CNTLID}, d.ctrls); len(detach) > 0 {
+				return nil, fmt.Errorf("namespace %d: ns %d should be attached to controller %d to be formatted", i, current.NSID, l.CNTLID)
+			}
+
+			plan = append(plan, Operation{Kind: OpFormat, NSID: current.NSID, LBAF: d.lbaf})
+		}
+	}
+
+	if required > available {
+		return nil, fmt.Errorf("not enough capacity: %dB required but %dB available", required, available)
+	}
+
+	if ordered, err := orderCreates(used, creates); err != nil {
+		return nil, err
+	} else {
+		plan = append(plan, ordered...)
+	}
+
+	sort.SliceStable(plan, func(i, j int) bool { return plan[i].Kind < plan[j].Kind })
+
+	return plan, nil
+}
+
+// Apply applies the operations of the plan in order. It stops at the first failed operation, and
+// the layout can be reconciled again from the state at the failure. If the controller assigns
+// another NSID than the plan expects, the created namespace is deleted before returning the error.
+func Apply(file *os.File, plan Plan) error {
+	for _, o := range plan {
+		var err error
+
+		switch o.Kind {
+		case OpDetach:
+			err = namespace.Detach(file, o.NSID, o.Controllers)
+		case OpDelete:
+			err = namespace.DeleteNamespace(file, o.NSID)
+		case OpAttach:
+			err = namespace.Attach(file, o.NSID, o.Controllers)
+		case OpFormat:
+			err = format.Format(file, o.NSID, o.LBAF, format.Options{})
+		case OpCreate:
+			var nsid uint32
+			if nsid, err = namespace.CreateNamespace(file, o.Attributes); err != nil {
+				break
+			} else if o.NSID != 0 && nsid != o.NSID {
+				err = fmt.Errorf("namespace %d is created instead of %d", nsid, o.NSID)
+				if derr := namespace.DeleteNamespace(file, nsid); derr != nil {
+					err = fmt.Errorf("%v, and deleting it failed: %v", err, derr)
+				}
+			} else if len(o.Controllers) > 0 {
+				err = namespace.Attach(file, nsid, o.Controllers)
+			}
+		default:
+			err = fmt.Errorf("unknown operation: %v", o.Kind)
+		}
+
+		if err != nil {
+			return fmt.Errorf("%v: %v", o, err)
+		}
+	}
+
+	return nil
+}
+
+// Reconcile reads the current layout, computes the plan to satisfy the spec and applies it. If
+// dryRun is true, the plan is returned without applying it. Reconcile is idempotent, so the plan is
+// empty if the layout already satisfies the spec.
+func Reconcile(file *os.File, spec *Spec, dryRun bool) (Plan, error) {
+	l, err := ReadLayout(file)
+	if err != nil {
+		return nil, err
+	}
+
+	plan, err := NewPlan(l, spec)
+	if err != nil || dryRun {
+		return plan, err
+	}
+
+	return plan, Apply(file, plan)
+}
@@ -0,0 +1,185 @@
+package layout
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/sungup/go-nvmecli/pkg/nvme/namespace"
+	"testing"
+)
+
+const (
+	testCNTLID   = uint16(1)
+	testCapacity = uint64(1 << 40) // 1TiB
+)
+
+// testLayout returns a layout which has a 512B LBA namespace of 25% capacity and a 4KiB LBA
+// namespace of 50% capacity.
+func testLayout() *Layout {
+	l := Layout{
+		CNTLID:      testCNTLID,
+		Capacity:    testCapacity,
+		Unallocated: testCapacity / 4,
+		NN:          16,
+		Namespaces: []Namespace{
+			{NSID: 1, NSZE: 1 << 29, NCAP: 1 << 29, LBAF: 0, LBASize: 512, Controllers: []uint16{testCNTLID}},
+			{NSID: 2, NSZE: 1 << 27, NCAP: 1 << 27, LBAF: 1, LBASize: 4096, Shared: true, Controllers: []uint16{testCNTLID, 2}},
+		},
+	}
+
+	l.common.NLBAF = 1
+	l.common.LBAF[0] = 9 << 16
+	l.common.LBAF[1] = 12 << 16
+
+	return &l
+}
+
+func TestOperation_String(t *testing.T) {
+	a := assert.New(t)
+
+	plan := Plan{
+		{Kind: OpDetach, NSID: 1, Controllers: []uint16{2}},
+		{Kind: OpDelete, NSID: 1},
+		{Kind: OpCreate, Attributes: namespace.Attributes{NSZE: 8, NCAP: 8, FLBAS: 1}, Controllers: []uint16{1}},
+		{Kind: OpAttach, NSID: 1, Controllers: []uint16{1, 2}},
+		{Kind: OpFormat, NSID: 1, LBAF: 1},
+	}
+
+	a.Equal(`detach ns 1 from controllers [2]
+delete ns 1
+create ns 0: nsze 8, flbas 1, nmic 0, attach to controllers [1]
+attach ns 1 to controllers [1 2]
+format ns 1 with LBA format 1`, plan.String())
+
+	a.Equal("unknown(9)", Operation{Kind: opKind(9)}.String())
+	a.Empty(Plan{}.String())
+}
+
+func TestNewPlan(t *testing.T) {
+	a := assert.New(t)
+
+	// 1. the current layout makes an empty plan
+	spec := &Spec{Namespaces: []NamespaceSpec{
+		{Size: "25%"},
+		{Size: "50%", LBASize: 4096, Shared: true, Controllers: []uint16{2, testCNTLID}},
+	}}
+
+	tested, err := NewPlan(testLayout(), spec)
+	a.NoError(err)
+	a.Empty(tested)
+
+	// 2. attach, detach and format
+	spec = &Spec{Namespaces: []NamespaceSpec{
+		{NSID: 2, Size: "512GiB", Shared: true, Controllers: []uint16{testCNTLID, 3}},
+		{Size: "256GiB", LBASize: 4096},
+	}}
+
+	tested, err = NewPlan(testLayout(), spec)
+	a.NoError(err)
+	a.Equal(Plan{
+		{Kind: OpDetach, NSID: 2, Controllers: []uint16{2}},
+		{Kind: OpAttach, NSID: 2, Controllers: []uint16{3}},
+		{Kind: OpFormat, NSID: 1, LBAF: 1},
+	}, tested)
+
+	// 3. delete the unmatched and the resized namespaces, and create new namespaces
+	spec = &Spec{Namespaces: []NamespaceSpec{
+		{Size: "50%"},
+		{Size: "10GiB", LBASize: 4096},
+	}}
+
+	tested, err = NewPlan(testLayout(), spec)
+	a.NoError(err)
+	a.Equal(Plan{
+		{Kind: OpDelete, NSID: 1},
+		{Kind: OpDelete, NSID: 2},
+		{Kind: OpCreate, Attributes: namespace.Attributes{NSZE: 1 << 30, NCAP: 1 << 30}, Controllers: []uint16{testCNTLID}},
+		{Kind: OpCreate, Attributes: namespace.Attributes{NSZE: 10 << 18, NCAP: 10 << 18, FLBAS: 1}, Controllers: []uint16{testCNTLID}},
+	}, tested)
+
+	// 4. the sharing is changed
+	spec = &Spec{Namespaces: []NamespaceSpec{
+		{Size: "25%", Shared: true},
+		{NSID: 2, Size: "50%", Shared: true, Controllers: []uint16{testCNTLID, 2}},
+	}}
+
+	tested, err = NewPlan(testLayout(), spec)
+	a.NoError(err)
+	a.Equal(Plan{
+		{Kind: OpDelete, NSID: 1},
+		{Kind: OpCreate, Attributes: namespace.Attributes{NSZE: 1 << 29, NCAP: 1 << 29, NMIC: nmicShared}, Controllers: []uint16{testCNTLID}},
+	}, tested)
+
+	// 5. the namespace rounded up by the controller is kept
+	l := testLayout()
+	l.Namespaces[0].NSZE += 1 << 20
+	l.Namespaces[0].NCAP += 1 << 20
+
+	tested, err = NewPlan(l, &Spec{Namespaces: []NamespaceSpec{{NSID: 1, Size: "25%"}, {NSID: 2, Size: "50%", Shared: true, Controllers: []uint16{2, 1}}}})
+	a.NoError(err)
+	a.Empty(tested)
+}
+
+func TestNewPlan_PinnedNSID(t *testing.T) {
+	a := assert.New(t)
+
+	shared := NamespaceSpec{NSID: 2, Size: "50%", LBASize: 4096, Shared: true, Controllers: []uint16{testCNTLID, 2}}
+
+	// 1. ns 1 is freed by the deletion, so the controller assigns ns 1 instead of ns 3
+	_, err := NewPlan(testLayout(), &Spec{Namespaces: []NamespaceSpec{shared, {NSID: 3, Size: "10GiB"}}})
+	a.Error(err)
+
+	// 2. the namespace without NSID is created first to fill ns 1
+	tested, err := NewPlan(testLayout(), &Spec{Namespaces: []NamespaceSpec{shared, {NSID: 3, Size: "10GiB"}, {Size: "10GiB"}}})
+	a.NoError(err)
+	a.Equal(Plan{
+		{Kind: OpDelete, NSID: 1},
+		{Kind: OpCreate, Attributes: namespace.Attributes{NSZE: 10 << 21, NCAP: 10 << 21}, Controllers: []uint16{testCNTLID}},
+		{Kind: OpCreate, NSID: 3, Attributes: namespace.Attributes{NSZE: 10 << 21, NCAP: 10 << 21}, Controllers: []uint16{testCNTLID}},
+	}, tested)
+
+	// 3. the NSID over NN is rejected before creating any namespace
+	_, err = NewPlan(testLayout(), &Spec{Namespaces: []NamespaceSpec{shared, {NSID: 17, Size: "10GiB"}, {Size: "10GiB"}}})
+	a.EqualError(err, "namespace 1: NSID 17 is over the maximum NSID 16 of the controller")
+
+	// 4. the recreated namespace gets its NSID back
+	tested, err = NewPlan(testLayout(), &Spec{Namespaces: []NamespaceSpec{{NSID: 1, Size: "10GiB"}, shared}})
+	a.NoError(err)
+	a.Equal(Plan{
+		{Kind: OpDelete, NSID: 1},
+		{Kind: OpCreate, NSID: 1, Attributes: namespace.Attributes{NSZE: 10 << 21, NCAP: 10 << 21}, Controllers: []uint16{testCNTLID}},
+	}, tested)
+}
+
+func TestOrderCreates(t *testing.T) {
+	a := assert.New(t)
+
+	used := map[uint32]bool{2: true}
+	creates := []Operation{{Kind: OpCreate, NSID: 4}, {Kind: OpCreate, LBAF: 1}, {Kind: OpCreate, NSID: 1}, {Kind: OpCreate, LBAF: 2}}
+
+	tested, err := orderCreates(used, creates)
+	a.NoError(err)
+	a.Equal([]Operation{creates[2], creates[1], creates[0], creates[3]}, tested)
+
+	// ns 5 is assigned before ns 6
+	_, err = orderCreates(used, []Operation{{Kind: OpCreate, NSID: 1}, {Kind: OpCreate}, {Kind: OpCreate}, {Kind: OpCreate, NSID: 6}})
+	a.EqualError(err, "ns 6 can't be created because the controller assigns the free NSID 5 first")
+}
+
+func TestNewPlanErrors(t *testing.T) {
+	a := assert.New(t)
+
+	for _, spec := range []*Spec{
+		// not enough capacity
+		{Namespaces: []NamespaceSpec{{Size: "25%"}, {Size: "50%", LBASize: 4096, Shared: true, Controllers: []uint16{1, 2}}, {Size: "30%"}}},
+		// unsupported LBA format
+		{Namespaces: []NamespaceSpec{{Size: "25%", LBASize: 8192}}},
+		// smaller than a logical block
+		{Namespaces: []NamespaceSpec{{Size: "100B"}}},
+		// format without the attachment to the controller
+		{Namespaces: []NamespaceSpec{{Size: "25%", LBASize: 4096, Controllers: []uint16{2}}}},
+		// invalid spec
+		{Namespaces: []NamespaceSpec{{Size: "abc"}}},
+	} {
+		_, err := NewPlan(testLayout(), spec)
+		a.Error(err)
+	}
+}
@@ -0,0 +1,38 @@
+// +build with_phys_device
+
+package layout
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"strconv"
+	"testing"
+)
+
+func TestReconcile(t *testing.T) {
+	a := assert.New(t)
+
+	dev, _ := os.Open(targetDevice)
+
+	l, err := ReadLayout(dev)
+	if err != nil {
+		return
+	}
+
+	// the spec of the current layout makes an empty plan
+	spec := Spec{}
+	for _, ns := range l.Namespaces {
+		spec.Namespaces = append(spec.Namespaces, NamespaceSpec{
+			NSID:         ns.NSID,
+			Size:         Size(strconv.FormatUint(ns.NSZE*uint64(ns.LBASize), 10)),
+			LBASize:      ns.LBASize,
+			MetadataSize: ns.MetadataSize,
+			Shared:       ns.Shared,
+			Controllers:  ns.Controllers,
+		})
+	}
+
+	tested, err := Reconcile(dev, &spec, true)
+	a.NoError(err)
+	a.Empty(tested)
+}
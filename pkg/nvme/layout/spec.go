@@ -0,0 +1,163 @@
+package layout
+
+import (
+	"fmt"
+	"github.com/sungup/go-nvmecli/pkg/nvme/identify"
+	"github.com/sungup/go-nvmecli/pkg/nvme/namespace"
+	"gopkg.in/yaml.v3"
+	"io/ioutil"
+	"strconv"
+	"strings"
+)
+
+// sizeUnits is the multiplier of the size suffixes. The decimal and the binary units are supported.
+var sizeUnits = []struct {
+	suffix string
+	unit   uint64
+}{
+	// longer suffixes should be checked before the shorter ones
+	{"KiB", 1 << 10}, {"MiB", 1 << 20}, {"GiB", 1 << 30}, {"TiB", 1 << 40}, {"PiB", 1 << 50},
+	{"KB", 1e3}, {"MB", 1e6}, {"GB", 1e9}, {"TB", 1e12}, {"PB", 1e15},
+	{"B", 1},
+}
+
+// Size is the namespace size in the spec like "100GiB", "1.5TB", "4096" in bytes or "25%" of the
+// total NVM capacity.
+type Size string
+
+// parse returns the size in bytes or the percentage of the total NVM capacity.
+func (s Size) parse() (bytes uint64, percent float64, err error) {
+	str := strings.TrimSpace(string(s))
+
+	if strings.HasSuffix(str, "%") {
+		if percent, err = strconv.ParseFloat(strings.TrimSpace(strings.TrimSuffix(str, "%")), 64); err != nil {
+			return 0, 0, fmt.Errorf("invalid size %q: %v", s, err)
+		} else if percent <= 0 || percent > 100 {
+			return 0, 0, fmt.Errorf("invalid size %q: percentage should be in (0, 100]", s)
+		}
+
+		return 0, percent, nil
+	}
+
+	unit := uint64(1)
+	for _, u := range sizeUnits {
+		if strings.HasSuffix(str, u.suffix) {
+			str, unit = strings.TrimSpace(strings.TrimSuffix(str, u.suffix)), u.unit
+			break
+		}
+	}
+
+	if value, err := strconv.ParseFloat(str, 64); err != nil {
+		return 0, 0, fmt.Errorf("invalid size %q: %v", s, err)
+	} else if value <= 0 {
+		return 0, 0, fmt.Errorf("invalid size %q: size should be larger than 0", s)
+	} else {
+		return uint64(value * float64(unit)), 0, nil
+	}
+}
+
+// Bytes returns the size in bytes. The percentage is converted with the total NVM capacity.
+func (s Size) Bytes(capacity uint64) (uint64, error) {
+	bytes, percent, err := s.parse()
+	if err != nil {
+		return 0, err
+	} else if percent == 0 {
+		return bytes, nil
+	} else if capacity == 0 {
+		return 0, fmt.Errorf("size %q needs the total NVM capacity, but it is not reported", s)
+	}
+
+	return uint64(float64(capacity) * percent / 100), nil
+}
+
+// NamespaceSpec is the desired state of a namespace.
+type NamespaceSpec struct {
+	// NSID is the namespace identifier to match with the existing namespace. The namespace without
+	// NSID is matched with the remained namespaces in increasing NSID order.
+	NSID uint32 `json:"nsid,omitempty" yaml:"nsid,omitempty"`
+
+	// Size is the namespace size and capacity.
+	Size Size `json:"size" yaml:"size"`
+
+	// LBASize and MetadataSize select the LBA format. If LBASize is 0, the existing namespace keeps
+	// its LBA format, and the new namespace is created with the default LBA format.
+	LBASize      int `json:"lba_size,omitempty" yaml:"lba_size,omitempty"`
+	MetadataSize int `json:"metadata_size,omitempty" yaml:"metadata_size,omitempty"`
+
+	// Shared creates the namespace which can be attached to two or more controllers.
+	Shared bool `json:"shared,omitempty" yaml:"shared,omitempty"`
+
+	// Controllers is the list of the controllers to attach the namespace. If it is empty, the
+	// namespace is attached to the controller which the spec is applied through.
+	Controllers []uint16 `json:"controllers,omitempty" yaml:"controllers,omitempty"`
+}
+
+// Spec is the desired namespace layout of an NVM subsystem. The namespaces not in the spec are
+// deleted by the reconciliation.
+type Spec struct {
+	Namespaces []NamespaceSpec `json:"namespaces" yaml:"namespaces"`
+}
+
+// validate checks the spec without the device information.
+func (s *Spec) validate() error {
+	nsids := make(map[uint32]bool)
+	percent := float64(0)
+
+	for i, ns := range s.Namespaces {
+		if ns.NSID == namespace.AllNamespaces {
+			return fmt.Errorf("namespace %d: broadcast NSID %Xh can't be created", i, ns.NSID)
+		} else if ns.NSID != 0 {
+			if nsids[ns.NSID] {
+				return fmt.Errorf("namespace %d: duplicated NSID %d", i, ns.NSID)
+			}
+
+			nsids[ns.NSID] = true
+		}
+
+		if _, p, err := ns.Size.parse(); err != nil {
+			return fmt.Errorf("namespace %d: %v", i, err)
+		} else {
+			percent += p
+		}
+
+		if ns.LBASize != 0 && (ns.LBASize < 512 || ns.LBASize&(ns.LBASize-1) != 0) {
+			return fmt.Errorf("namespace %d: invalid LBA size %d", i, ns.LBASize)
+		} else if ns.MetadataSize < 0 || (ns.LBASize == 0 && ns.MetadataSize != 0) {
+			return fmt.Errorf("namespace %d: invalid metadata size %d", i, ns.MetadataSize)
+		} else if len(ns.Controllers) > 1 && !ns.Shared {
+			return fmt.Errorf("namespace %d: private namespace can't be attached to %d controllers", i, len(ns.Controllers))
+		}
+
+		if _, err := identify.NewControllerList(ns.Controllers); err != nil {
+			return fmt.Errorf("namespace %d: %v", i, err)
+		}
+	}
+
+	if percent > 100 {
+		return fmt.Errorf("total namespace size is over 100%% of the capacity: %g%%", percent)
+	}
+
+	return nil
+}
+
+// ParseSpec parses the YAML or JSON formatted spec. JSON is a subset of YAML, so both formats are
+// parsed by the same decoder.
+func ParseSpec(raw []byte) (*Spec, error) {
+	spec := Spec{}
+	if err := yaml.Unmarshal(raw, &spec); err != nil {
+		return nil, err
+	} else if err = spec.validate(); err != nil {
+		return nil, err
+	}
+
+	return &spec, nil
+}
+
+// LoadSpec reads and parses the YAML or JSON formatted spec file.
+func LoadSpec(path string) (*Spec, error) {
+	if raw, err := ioutil.ReadFile(path); err != nil {
+		return nil, err
+	} else {
+		return ParseSpec(raw)
+	}
+}
@@ -0,0 +1,102 @@
+package layout
+
+import (
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestSize_Bytes(t *testing.T) {
+	a := assert.New(t)
+
+	for size, expected := range map[Size]uint64{
+		"4096":     4096,
+		"512B":     512,
+		"100GiB":   100 << 30,
+		"1.5 TiB":  3 << 39,
+		"1TB":      1e12,
+		"20MB":     20e6,
+		"25%":      250,
+		"12.5 %":   125,
+		" 100% ":   1000,
+		"0.5KiB":   512,
+		"1 PiB":    1 << 50,
+		"0.001 PB": 1e12,
+	} {
+		tested, err := size.Bytes(1000)
+		a.NoError(err, size)
+		a.Equal(expected, tested, size)
+	}
+
+	for _, size := range []Size{"", "abc", "0", "-1GiB", "0%", "101%", "10XB", "%"} {
+		_, err := size.Bytes(1000)
+		a.Error(err, size)
+	}
+
+	// the percentage needs the total capacity
+	_, err := Size("10%").Bytes(0)
+	a.Error(err)
+}
+
+func TestParseSpec(t *testing.T) {
+	a := assert.New(t)
+
+	yamlSpec := `
+namespaces:
+  - nsid: 1
+    size: 100GiB
+    lba_size: 4096
+  - size: 25%
+    shared: true
+    controllers: [1, 2]
+`
+
+	tested, err := ParseSpec([]byte(yamlSpec))
+	a.NoError(err)
+	a.Len(tested.Namespaces, 2)
+	a.Equal(NamespaceSpec{NSID: 1, Size: "100GiB", LBASize: 4096}, tested.Namespaces[0])
+	a.Equal(NamespaceSpec{Size: "25%", Shared: true, Controllers: []uint16{1, 2}}, tested.Namespaces[1])
+
+	// JSON is parsed with the same decoder
+	jsonSpec := `{"namespaces": [{"nsid": 1, "size": "100GiB", "lba_size": 4096}, {"size": "25%", "shared": true, "controllers": [1, 2]}]}`
+
+	fromJSON, err := ParseSpec([]byte(jsonSpec))
+	a.NoError(err)
+	a.Equal(tested, fromJSON)
+
+	// invalid specs
+	for _, spec := range []string{
+		`namespaces: [{size: 1GiB}, {nsid: 1, size: 1GiB}, {nsid: 1, size: 2GiB}]`,
+		`namespaces: [{size: abc}]`,
+		`namespaces: [{nsid: 4294967295, size: 1GiB}]`,
+		`namespaces: [{size: 60%}, {size: 50%}]`,
+		`namespaces: [{size: 1GiB, lba_size: 1000}]`,
+		`namespaces: [{size: 1GiB, lba_size: 256}]`,
+		`namespaces: [{size: 1GiB, metadata_size: 8}]`,
+		`namespaces: [{size: 1GiB, lba_size: 512, metadata_size: -8}]`,
+		`namespaces: [{size: 1GiB, controllers: [1, 2]}]`,
+		`namespaces: [{size: 1GiB, shared: true, controllers: [1, 1]}]`,
+		`namespaces: {size: 1GiB}`,
+	} {
+		_, err = ParseSpec([]byte(spec))
+		a.Error(err, spec)
+	}
+}
+
+func TestLoadSpec(t *testing.T) {
+	a := assert.New(t)
+
+	file, _ := ioutil.TempFile("", "layout")
+	defer func() { _ = os.Remove(file.Name()) }()
+
+	_, _ = file.WriteString("namespaces:\n  - size: 100%\n")
+	_ = file.Close()
+
+	tested, err := LoadSpec(file.Name())
+	a.NoError(err)
+	a.Equal([]NamespaceSpec{{Size: "100%"}}, tested.Namespaces)
+
+	_, err = LoadSpec(file.Name() + ".notexist")
+	a.Error(err)
+}
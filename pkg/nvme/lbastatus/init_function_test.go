@@ -0,0 +1,5 @@
+package lbastatus
+
+const (
+	expectedNSId = 1
+)
@@ -0,0 +1,230 @@
+package lbastatus
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"github.com/sungup/go-nvmecli/pkg/nvme"
+	"github.com/sungup/go-nvmecli/pkg/nvme/getlog"
+	"github.com/sungup/go-nvmecli/pkg/nvme/identify"
+	"github.com/sungup/go-nvmecli/pkg/utils"
+	"math"
+	"os"
+	"sort"
+)
+
+// actionType is the Action Type (ATYPE) field of the Get LBA Status command.
+type actionType uint8
+
+const (
+	ScanAndReturn = actionType(0x10) // scan the range and return the untracked and tracked LBAs
+	ReturnTracked = actionType(0x11) // return the tracked LBAs without scanning
+
+	// oacsGetLBAStatus is the OACS bit which indicates the Get LBA Status command is supported.
+	oacsGetLBAStatus = uint16(1 << 9)
+
+	// maxStatusDescs is the number of LBA Status Descriptors retrieved by a Get LBA Status command.
+	maxStatusDescs = 255
+)
+
+// cmpcMNDWReached is the Completion Condition which indicates the descriptor list is full and the
+// additional descriptors may be available in the range.
+const cmpcMNDWReached = uint8(0x1)
+
+// LBARange is a range of logical blocks.
+type LBARange struct {
+	SLBA uint64
+	NLB  uint64
+}
+
+// End returns the next LBA of the last LBA in the range.
+func (r LBARange) End() uint64 {
+	return r.SLBA + r.NLB
+}
+
+// MergeRanges sorts the ranges and merges the overlapped or adjacent ranges.
+func MergeRanges(ranges []LBARange) []LBARange {
+	sorted := make([]LBARange, 0, len(ranges))
+	for _, r := range ranges {
+		if r.NLB > 0 {
+			sorted = append(sorted, r)
+		}
+	}
+
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].SLBA < sorted[j].SLBA })
+
+	merged := make([]LBARange, 0, len(sorted))
+	for _, r := range sorted {
+		if last := len(merged) - 1; last >= 0 && r.SLBA <= merged[last].End() {
+			if r.End() > merged[last].End() {
+				merged[last].NLB = r.End() - merged[last].SLBA
+			}
+		} else {
+			merged = append(merged, r)
+		}
+	}
+
+	return merged
+}
+
+// lbaStatusDesc is an LBA Status Descriptor returned by the Get LBA Status command.
+type lbaStatusDesc struct {
+	DSLBA uint64  // [07:00] Descriptor Starting LBA
+	NLB   uint32  // [11:08] Number of Logical Blocks
+	_     [4]byte // [15:12] reserved
+}
+
+// LBAStatus is the LBA Status Descriptor List returned by the Get LBA Status command.
+type LBAStatus struct {
+	NLSD  uint32  // [03:00] Number of LBA Status Descriptors
+	CMPC  uint8   // [04] Completion Condition
+	_     [3]byte // [07:05] reserved
+	Descs [maxStatusDescs]lbaStatusDesc
+}
+
+// Ranges returns the LBA ranges of the valid descriptors.
+func (s *LBAStatus) Ranges() []LBARange {
+	count := int(s.NLSD)
+	if count > len(s.Descs) {
+		count = len(s.Descs)
+	}
+
+	ranges := make([]LBARange, count)
+	for i := range ranges {
+		ranges[i] = LBARange{SLBA: s.Descs[i].DSLBA, NLB: uint64(s.Descs[i].NLB)}
+	}
+
+	return ranges
+}
+
+// ParseLBAStatus parses the LBA Status Descriptor List from raw data. If the raw data is shorter
+// than the reported descriptors, this function raises an error.
+func ParseLBAStatus(raw []byte) (*LBAStatus, error) {
+	const (
+		headerSz = 8
+		descSz   = 16
+	)
+
+	if len(raw) < headerSz {
+		return nil, fmt.Errorf("unexpected LBA status data size: %d", len(raw))
+	}
+
+	s := LBAStatus{NLSD: utils.SystemEndian.Uint32(raw), CMPC: raw[4]}
+	if s.NLSD > maxStatusDescs || int(s.NLSD) > (len(raw)-headerSz)/descSz {
+		return nil, fmt.Errorf("LBA status has %d descriptors over %dB", s.NLSD, len(raw))
+	}
+
+	if err := binary.Read(bytes.NewReader(raw[headerSz:]), utils.SystemEndian, s.Descs[:s.NLSD]); err != nil {
+		return nil, err
+	}
+
+	return &s, nil
+}
+
+// newGetLBAStatusCmd generates an AdminCmd structure to retrieve the LBA status of the range which
+// starts from slba with rl logical blocks. MNDW is calculated from the size of v.
+func newGetLBAStatusCmd(nsid uint32, slba uint64, rl uint16, atype actionType, v interface{}) (*nvme.AdminCmd, error) {
+	cmd := nvme.AdminCmd{
+		PassthruCmd: nvme.PassthruCmd{
+			OpCode: nvme.AdminGetLBAStatus,
+			NSId:   nsid,
+			CDW10:  uint32(slba & math.MaxUint32),
+			CDW11:  uint32(slba >> 32),
+			CDW13:  uint32(atype)<<24 | uint32(rl),
+		},
+		TimeoutMSec: 0,
+		Result:      0,
+	}
+
+	if err := cmd.SetData(v); err != nil {
+		return nil, err
+	} else if cmd.DataLength < 8 || cmd.DataLength%4 != 0 {
+		return nil, fmt.Errorf("invalid LBA status buffer size: %d", cmd.DataLength)
+	}
+
+	// MNDW is a 0's based value
+	cmd.CDW12 = cmd.DataLength/4 - 1
+
+	return &cmd, nil
+}
+
+// GetLBAStatus retrieves the LBA Status Descriptor List of the range into v. The range length rl
+// is the number of logical blocks to examine from slba.
+func GetLBAStatus(file *os.File, nsid uint32, slba uint64, rl uint16, atype actionType, v interface{}) error {
+	if atype != ScanAndReturn && atype != ReturnTracked {
+		return fmt.Errorf("invalid LBA status action type: %02Xh", uint8(atype))
+	}
+
+	if cmd, err := newGetLBAStatusCmd(nsid, slba, rl, atype, v); err != nil {
+		return err
+	} else {
+		return nvme.IOCtlAdminCmd(file, cmd)
+	}
+}
+
+// ScanLBAStatus retrieves all LBA Status Descriptors in the range with the action type. The range
+// is split into Get LBA Status commands by the maximum range length and the number of descriptors,
+// and the returned ranges are merged.
+func ScanLBAStatus(file *os.File, nsid uint32, atype actionType, target LBARange) ([]LBARange, error) {
+	ranges := make([]LBARange, 0)
+	status := LBAStatus{}
+
+	for slba := target.SLBA; slba < target.End(); {
+		rl := uint16(math.MaxUint16)
+		if remain := target.End() - slba; remain < uint64(rl) {
+			rl = uint16(remain)
+		}
+
+		if err := GetLBAStatus(file, nsid, slba, rl, atype, &status); err != nil {
+			return nil, err
+		}
+
+		found := status.Ranges()
+		ranges = append(ranges, found...)
+
+		// if the descriptor list is full, continue from the end of the last descriptor
+		if status.CMPC == cmpcMNDWReached && len(found) > 0 && found[len(found)-1].End() > slba {
+			slba = found[len(found)-1].End()
+		} else {
+			slba += uint64(rl)
+		}
+	}
+
+	return MergeRanges(ranges), nil
+}
+
+// CollectLBARanges reads the LBA Status Information log page and examines each tracked range with
+// the recommended action type of the namespace. The result is the merged LBA ranges keyed by NSID
+// which host software should rewrite or relocate before the media fails.
+func CollectLBARanges(file *os.File) (map[uint32][]LBARange, error) {
+	idCtrl := identify.CtrlIdentify{}
+	if err := identify.GetCtrlIdentify(file, &idCtrl); err != nil {
+		return nil, err
+	} else if idCtrl.OACS&oacsGetLBAStatus == 0 {
+		return nil, fmt.Errorf("get LBA status is not supported by the controller")
+	}
+
+	info, err := getlog.GetLBAStatusInfo(file, false)
+	if err != nil {
+		return nil, err
+	}
+
+	collected := make(map[uint32][]LBARange)
+	for _, ns := range info.Namespaces {
+		ranges := make([]LBARange, 0)
+
+		for _, tracked := range ns.Ranges {
+			target := LBARange{SLBA: tracked.RSLBA, NLB: uint64(tracked.RNLB)}
+
+			if found, err := ScanLBAStatus(file, ns.NSID, actionType(ns.RATYPE), target); err != nil {
+				return nil, err
+			} else {
+				ranges = append(ranges, found...)
+			}
+		}
+
+		collected[ns.NSID] = MergeRanges(ranges)
+	}
+
+	return collected, nil
+}
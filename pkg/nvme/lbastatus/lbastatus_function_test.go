@@ -0,0 +1,94 @@
+package lbastatus
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/sungup/go-nvmecli/pkg/nvme"
+	"github.com/sungup/go-nvmecli/pkg/utils"
+	"testing"
+	"unsafe"
+)
+
+func TestMergeRanges(t *testing.T) {
+	a := assert.New(t)
+
+	tested := MergeRanges([]LBARange{
+		{SLBA: 100, NLB: 10},
+		{SLBA: 0, NLB: 8},
+		{SLBA: 105, NLB: 10}, // overlapped
+		{SLBA: 8, NLB: 2},    // adjacent
+		{SLBA: 50, NLB: 0},   // empty
+		{SLBA: 102, NLB: 2},  // included
+		{SLBA: 200, NLB: 1},
+	})
+
+	a.Equal([]LBARange{{SLBA: 0, NLB: 10}, {SLBA: 100, NLB: 15}, {SLBA: 200, NLB: 1}}, tested)
+	a.Empty(MergeRanges(nil))
+}
+
+func TestLBAStatusSize(t *testing.T) {
+	a := assert.New(t)
+
+	a.Equal(uintptr(16), unsafe.Sizeof(lbaStatusDesc{}))
+	a.Equal(uintptr(8+16*maxStatusDescs), unsafe.Sizeof(LBAStatus{}))
+}
+
+func TestParseLBAStatus(t *testing.T) {
+	a := assert.New(t)
+
+	raw := make([]byte, 8+16*2)
+	utils.SystemEndian.PutUint32(raw, 2)
+	raw[4] = cmpcMNDWReached
+	utils.SystemEndian.PutUint64(raw[8:], 0x100)
+	utils.SystemEndian.PutUint32(raw[16:], 4)
+	utils.SystemEndian.PutUint64(raw[24:], 0x200)
+	utils.SystemEndian.PutUint32(raw[32:], 8)
+
+	// check too short header
+	tested, err := ParseLBAStatus(raw[:7])
+	a.Error(err)
+	a.Nil(tested)
+
+	// check truncated descriptors
+	tested, err = ParseLBAStatus(raw[:len(raw)-1])
+	a.Error(err)
+	a.Nil(tested)
+
+	// check normal parsing
+	tested, err = ParseLBAStatus(raw)
+	a.NoError(err)
+	a.Equal(cmpcMNDWReached, tested.CMPC)
+	a.Equal([]LBARange{{SLBA: 0x100, NLB: 4}, {SLBA: 0x200, NLB: 8}}, tested.Ranges())
+}
+
+func TestNewGetLBAStatusCmd(t *testing.T) {
+	a := assert.New(t)
+
+	const (
+		expectedSLBA = uint64(0x0123456789ABCDEF)
+		expectedRL   = uint16(0xFFFF)
+	)
+
+	status := LBAStatus{}
+
+	tested, err := newGetLBAStatusCmd(expectedNSId, expectedSLBA, expectedRL, ReturnTracked, &status)
+	a.NoError(err)
+	a.Equal(nvme.AdminGetLBAStatus, tested.OpCode)
+	a.Equal(uint32(expectedNSId), tested.NSId)
+	a.Equal(uint32(0x89ABCDEF), tested.CDW10)
+	a.Equal(uint32(0x01234567), tested.CDW11)
+	a.Equal(uint32(unsafe.Sizeof(status)/4-1), tested.CDW12)
+	a.Equal(uint32(0x1100FFFF), tested.CDW13)
+
+	// buffer should be able to contain the header and be dword aligned
+	for _, buffer := range [][]byte{make([]byte, 4), make([]byte, 10)} {
+		tested, err = newGetLBAStatusCmd(expectedNSId, expectedSLBA, expectedRL, ReturnTracked, buffer)
+		a.Error(err)
+		a.Nil(tested)
+	}
+}
+
+func TestGetLBAStatus_InvalidActionType(t *testing.T) {
+	a := assert.New(t)
+
+	a.Error(GetLBAStatus(nil, expectedNSId, 0, 1, 0x02, &LBAStatus{}))
+}
@@ -0,0 +1,34 @@
+// +build with_phys_device
+
+package lbastatus
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"testing"
+)
+
+func TestScanLBAStatus(t *testing.T) {
+	// TODO re-verify this test code using the get LBA status support NVMe device
+	/*
+		a := assert.New(t)
+
+		dev, _ := os.Open(targetDevice)
+
+		tested, err := ScanLBAStatus(dev, expectedNSId, ReturnTracked, LBARange{SLBA: 0, NLB: 0x100000})
+		a.NoError(err)
+		a.Equal(MergeRanges(tested), tested)
+	*/
+}
+
+func TestCollectLBARanges(t *testing.T) {
+	a := assert.New(t)
+
+	dev, _ := os.Open(targetDevice)
+
+	if tested, err := CollectLBARanges(dev); err == nil {
+		for _, ranges := range tested {
+			a.Equal(MergeRanges(ranges), ranges)
+		}
+	}
+}
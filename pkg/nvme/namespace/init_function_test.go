@@ -0,0 +1,5 @@
+package namespace
+
+const (
+	expectedNSId = 1
+)
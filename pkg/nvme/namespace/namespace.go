@@ -0,0 +1,259 @@
+package namespace
+
+import (
+	"fmt"
+	"github.com/sungup/go-nvmecli/pkg/nvme"
+	"github.com/sungup/go-nvmecli/pkg/nvme/identify"
+	"math"
+	"os"
+)
+
+// sel is the Select field of the Namespace Management and the Namespace Attachment commands.
+type sel uint8
+
+const (
+	selCreate = sel(0x0)
+	selDelete = sel(0x1)
+
+	selAttach = sel(0x0)
+	selDetach = sel(0x1)
+)
+
+const (
+	// AllNamespaces is the broadcast NSID to delete all namespaces in the NVM subsystem.
+	AllNamespaces = uint32(math.MaxUint32)
+
+	// oacsNsMgmt is the OACS bit of the Namespace Management and Attachment commands support.
+	oacsNsMgmt = uint16(1 << 3)
+)
+
+// Attributes is the host specified fields of a namespace to be created.
+type Attributes struct {
+	NSZE     uint64 // Namespace Size in logical blocks
+	NCAP     uint64 // Namespace Capacity in logical blocks
+	FLBAS    uint8  // Formatted LBA Size, bit[3:0] is the LBA format index
+	DPS      uint8  // End-to-end Data Protection Type Settings
+	NMIC     uint8  // Namespace Multi-path I/O and Namespace Sharing Capabilities
+	ANAGRPID uint32 // ANA Group Identifier
+	NVMSETID uint16 // NVM Set Identifier
+	ENDGID   uint16 // Endurance Group Identifier
+	CSI      uint8  // Command Set Identifier
+}
+
+// newNsMgmtCmd generates an AdminCmd structure for the Namespace Management command. The created
+// namespace's attributes are transferred only for the create operation.
+func newNsMgmtCmd(nsid uint32, s sel, csi uint8, v interface{}) (*nvme.AdminCmd, error) {
+	cmd := nvme.AdminCmd{
+		PassthruCmd: nvme.PassthruCmd{
+			OpCode: nvme.AdminNsMgmt,
+			NSId:   nsid,
+			CDW10:  uint32(s) & 0x0F,
+			CDW11:  uint32(csi) << 24,
+		},
+		TimeoutMSec: 0,
+		Result:      0,
+	}
+
+	if v == nil {
+		return &cmd, nil
+	} else if err := cmd.SetData(v); err != nil {
+		return nil, err
+	} else {
+		return &cmd, nil
+	}
+}
+
+// newNsAttachCmd generates an AdminCmd structure for the Namespace Attachment command.
+func newNsAttachCmd(nsid uint32, s sel, list *identify.ControllerList) (*nvme.AdminCmd, error) {
+	cmd := nvme.AdminCmd{
+		PassthruCmd: nvme.PassthruCmd{
+			OpCode: nvme.AdminNsAttach,
+			NSId:   nsid,
+			CDW10:  uint32(s) & 0x0F,
+		},
+		TimeoutMSec: 0,
+		Result:      0,
+	}
+
+	if err := cmd.SetData(list); err != nil {
+		return nil, err
+	} else {
+		return &cmd, nil
+	}
+}
+
+// checkNsMgmt returns the Identify Controller data structure if the controller supports the
+// Namespace Management and Attachment commands.
+func checkNsMgmt(file *os.File) (*identify.CtrlIdentify, error) {
+	idCtrl := identify.CtrlIdentify{}
+	if err := identify.GetCtrlIdentify(file, &idCtrl); err != nil {
+		return nil, err
+	} else if idCtrl.OACS&oacsNsMgmt == 0 {
+		return nil, fmt.Errorf("namespace management is not supported by the controller")
+	}
+
+	return &idCtrl, nil
+}
+
+// validateCreate checks the attributes with the controller capabilities, the common namespace
+// capabilities and the number of the allocated namespaces.
+func validateCreate(idCtrl *identify.CtrlIdentify, idNs *identify.NamespaceIdentify, allocated int, attr Attributes) error {
+	// 1. the number of namespaces: MNAN is 0h if the controller doesn't report it, then NN is
+	// the maximum number of namespaces
+	maxNs := idCtrl.MNAN
+	if maxNs == 0 {
+		maxNs = idCtrl.NN
+	}
+
+	if uint32(allocated) >= maxNs {
+		return fmt.Errorf("no more namespace can be created: %d allocated in %d", allocated, maxNs)
+	}
+
+	// 2. size and capacity
+	lbaf := attr.FLBAS & 0x0F
+	if attr.NSZE == 0 {
+		return fmt.Errorf("namespace size should be larger than 0")
+	} else if attr.NCAP == 0 || attr.NCAP > attr.NSZE {
+		return fmt.Errorf("namespace capacity should be in (0, %d]: %d", attr.NSZE, attr.NCAP)
+	} else if lbaf > idNs.NLBAF || idNs.LBAF[lbaf].LBADataSize() == 0 {
+		return fmt.Errorf("LBA format %d is not supported by the controller", lbaf)
+	}
+
+	// 3. unallocated capacity: UNVMCAP is a 128bit byte count, and the upper 64bit is not 0
+	// only if the capacity is over 16EiB
+	shift := uint(idNs.LBAF[lbaf].LBADataSize())
+	unallocated := idCtrl.UNVMCAP[0].Uint()
+	if attr.NCAP > math.MaxUint64>>shift {
+		return fmt.Errorf("namespace capacity is too large: %d", attr.NCAP)
+	} else if required := attr.NCAP << shift; idCtrl.UNVMCAP[1].Uint() == 0 && required > unallocated {
+		return fmt.Errorf("not enough unallocated capacity: %dB required but %dB remained", required, unallocated)
+	}
+
+	// 4. identifiers
+	if attr.NVMSETID > idCtrl.NSETIDMAX {
+		return fmt.Errorf("NVM set %d is over the maximum identifier %d", attr.NVMSETID, idCtrl.NSETIDMAX)
+	} else if attr.ENDGID > idCtrl.ENDGIDMAX {
+		return fmt.Errorf("endurance group %d is over the maximum identifier %d", attr.ENDGID, idCtrl.ENDGIDMAX)
+	} else if attr.ANAGRPID > idCtrl.ANAGRPMAX {
+		return fmt.Errorf("ANA group %d is over the maximum identifier %d", attr.ANAGRPID, idCtrl.ANAGRPMAX)
+	}
+
+	return nil
+}
+
+// validateAttach checks the namespace identifier and the controller list of the attachment.
+func validateAttach(nsid uint32, ctrls []uint16) error {
+	if nsid == 0 || nsid == AllNamespaces {
+		return fmt.Errorf("invalid namespace identifier: %Xh", nsid)
+	} else if len(ctrls) == 0 {
+		return fmt.Errorf("empty controller list")
+	}
+
+	return nil
+}
+
+// rescan requests the kernel to rescan the namespaces after the namespace changes.
+func rescan(file *os.File) error {
+	if err := nvme.IOCtlRescan(file); err != nil {
+		return fmt.Errorf("namespace rescan failed: %v", err)
+	}
+
+	return nil
+}
+
+// CreateNamespace creates a namespace with the attributes and returns the created namespace
+// identifier. The attributes are validated with the controller capabilities before issuing the
+// command. The created namespace is not attached to any controller, so it should be attached by
+// Attach to be used.
+func CreateNamespace(file *os.File, attr Attributes) (uint32, error) {
+	idCtrl, err := checkNsMgmt(file)
+	if err != nil {
+		return 0, err
+	}
+
+	// the common namespace capabilities are reported with the broadcast NSID
+	common := identify.NamespaceIdentify{}
+	if err = identify.GetNamespaceIdentify(file, AllNamespaces, &common); err != nil {
+		return 0, err
+	}
+
+	allocated, err := identify.ListAllocatedNamespaces(file)
+	if err != nil {
+		return 0, err
+	}
+
+	if err = validateCreate(idCtrl, &common, len(allocated), attr); err != nil {
+		return 0, err
+	}
+
+	idNs := identify.NamespaceIdentify{
+		NSZE:     attr.NSZE,
+		NCAP:     attr.NCAP,
+		FLBAS:    attr.FLBAS,
+		DPS:      attr.DPS,
+		NMIC:     attr.NMIC,
+		ANAGRPID: attr.ANAGRPID,
+		NVMSETID: attr.NVMSETID,
+		ENDGID:   attr.ENDGID,
+	}
+
+	cmd, err := newNsMgmtCmd(0, selCreate, attr.CSI, &idNs)
+	if err != nil {
+		return 0, err
+	} else if err = nvme.IOCtlAdminCmd(file, cmd); err != nil {
+		return 0, err
+	}
+
+	// the created namespace identifier is returned in CDW0
+	return cmd.Result, rescan(file)
+}
+
+// DeleteNamespace deletes the namespace. If nsid is AllNamespaces, all namespaces in the NVM
+// subsystem are deleted.
+func DeleteNamespace(file *os.File, nsid uint32) error {
+	if _, err := checkNsMgmt(file); err != nil {
+		return err
+	} else if nsid == 0 {
+		return fmt.Errorf("invalid namespace identifier: %Xh", nsid)
+	}
+
+	if cmd, err := newNsMgmtCmd(nsid, selDelete, 0, nil); err != nil {
+		return err
+	} else if err = nvme.IOCtlAdminCmd(file, cmd); err != nil {
+		return err
+	}
+
+	return rescan(file)
+}
+
+// attach issues the Namespace Attachment command with the controller list.
+func attach(file *os.File, nsid uint32, s sel, ctrls []uint16) error {
+	if _, err := checkNsMgmt(file); err != nil {
+		return err
+	} else if err = validateAttach(nsid, ctrls); err != nil {
+		return err
+	}
+
+	list, err := identify.NewControllerList(ctrls)
+	if err != nil {
+		return err
+	}
+
+	if cmd, err := newNsAttachCmd(nsid, s, list); err != nil {
+		return err
+	} else if err = nvme.IOCtlAdminCmd(file, cmd); err != nil {
+		return err
+	}
+
+	return rescan(file)
+}
+
+// Attach attaches the namespace to the controllers.
+func Attach(file *os.File, nsid uint32, ctrls []uint16) error {
+	return attach(file, nsid, selAttach, ctrls)
+}
+
+// Detach detaches the namespace from the controllers.
+func Detach(file *os.File, nsid uint32, ctrls []uint16) error {
+	return attach(file, nsid, selDetach, ctrls)
+}
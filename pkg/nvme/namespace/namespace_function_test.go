@@ -0,0 +1,94 @@
+package namespace
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/sungup/go-nvmecli/pkg/nvme"
+	"github.com/sungup/go-nvmecli/pkg/nvme/identify"
+	"github.com/sungup/go-nvmecli/pkg/nvme/types"
+	"testing"
+	"unsafe"
+)
+
+func TestNewNsMgmtCmd(t *testing.T) {
+	a := assert.New(t)
+
+	idNs := identify.NamespaceIdentify{}
+
+	tested, err := newNsMgmtCmd(0, selCreate, 0x2, &idNs)
+	a.NoError(err)
+	a.Equal(nvme.AdminNsMgmt, tested.OpCode)
+	a.Zero(tested.NSId)
+	a.Equal(uint32(selCreate), tested.CDW10)
+	a.Equal(uint32(0x2<<24), tested.CDW11)
+	a.Equal(uint32(unsafe.Sizeof(idNs)), tested.DataLength)
+
+	tested, err = newNsMgmtCmd(expectedNSId, selDelete, 0, nil)
+	a.NoError(err)
+	a.Equal(uint32(expectedNSId), tested.NSId)
+	a.Equal(uint32(selDelete), tested.CDW10)
+	a.Zero(tested.DataLength)
+}
+
+func TestNewNsAttachCmd(t *testing.T) {
+	a := assert.New(t)
+
+	list, _ := identify.NewControllerList([]uint16{1})
+
+	tested, err := newNsAttachCmd(expectedNSId, selDetach, list)
+	a.NoError(err)
+	a.Equal(nvme.AdminNsAttach, tested.OpCode)
+	a.Equal(uint32(expectedNSId), tested.NSId)
+	a.Equal(uint32(selDetach), tested.CDW10)
+	a.Equal(uint32(4096), tested.DataLength)
+}
+
+func TestValidateCreate(t *testing.T) {
+	a := assert.New(t)
+
+	idCtrl := identify.CtrlIdentify{NN: 32, MNAN: 4, NSETIDMAX: 1, ENDGIDMAX: 1, ANAGRPMAX: 1}
+	idCtrl.UNVMCAP[0] = types.Uint64{0, 0, 0, 0, 0x01} // 4GiB
+
+	idNs := identify.NamespaceIdentify{NLBAF: 1}
+	idNs.LBAF[0] = 9 << 16
+	idNs.LBAF[1] = 12 << 16
+	idNs.LBAF[2] = 12 << 16
+
+	attr := Attributes{NSZE: 1 << 20, NCAP: 1 << 20, FLBAS: 1} // 4GiB in 4KiB LBA
+
+	a.NoError(validateCreate(&idCtrl, &idNs, 0, attr))
+	a.NoError(validateCreate(&idCtrl, &idNs, 3, Attributes{NSZE: 8, NCAP: 4, NVMSETID: 1, ENDGID: 1, ANAGRPID: 1}))
+
+	// the number of namespaces
+	a.Error(validateCreate(&idCtrl, &idNs, 4, attr))
+
+	idCtrl.MNAN = 0
+	a.NoError(validateCreate(&idCtrl, &idNs, 31, attr))
+	a.Error(validateCreate(&idCtrl, &idNs, 32, attr))
+
+	// size and capacity
+	a.Error(validateCreate(&idCtrl, &idNs, 0, Attributes{NCAP: 1}))
+	a.Error(validateCreate(&idCtrl, &idNs, 0, Attributes{NSZE: 1}))
+	a.Error(validateCreate(&idCtrl, &idNs, 0, Attributes{NSZE: 1, NCAP: 2}))
+	a.Error(validateCreate(&idCtrl, &idNs, 0, Attributes{NSZE: 1, NCAP: 1, FLBAS: 2}))
+
+	// unallocated capacity
+	a.Error(validateCreate(&idCtrl, &idNs, 0, Attributes{NSZE: 1<<20 + 1, NCAP: 1<<20 + 1, FLBAS: 1}))
+	a.Error(validateCreate(&idCtrl, &idNs, 0, Attributes{NSZE: 1 << 60, NCAP: 1 << 60, FLBAS: 1}))
+
+	idCtrl.UNVMCAP[1] = types.Uint64{0x01}
+	a.NoError(validateCreate(&idCtrl, &idNs, 0, Attributes{NSZE: 1 << 40, NCAP: 1 << 40, FLBAS: 1}))
+
+	// identifiers
+	a.Error(validateCreate(&idCtrl, &idNs, 0, Attributes{NSZE: 1, NCAP: 1, NVMSETID: 2}))
+	a.Error(validateCreate(&idCtrl, &idNs, 0, Attributes{NSZE: 1, NCAP: 1, ENDGID: 2}))
+	a.Error(validateCreate(&idCtrl, &idNs, 0, Attributes{NSZE: 1, NCAP: 1, ANAGRPID: 2}))
+}
+
+func TestValidateAttach(t *testing.T) {
+	a := assert.New(t)
+
+	a.NoError(validateAttach(expectedNSId, []uint16{1}))
+	a.Error(validateAttach(0, []uint16{1}))
+	a.Error(validateAttach(AllNamespaces, []uint16{1}))
+	a.Error(validateAttach(expectedNSId, nil))
+}
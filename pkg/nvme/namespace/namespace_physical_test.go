@@ -0,0 +1,50 @@
+// +build with_phys_device
+
+package namespace
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/sungup/go-nvmecli/pkg/nvme/identify"
+	"os"
+	"testing"
+)
+
+func TestCheckNsMgmt(t *testing.T) {
+	a := assert.New(t)
+
+	dev, _ := os.Open(targetDevice)
+
+	idCtrl := identify.CtrlIdentify{}
+	a.NoError(identify.GetCtrlIdentify(dev, &idCtrl))
+
+	tested, err := checkNsMgmt(dev)
+	if idCtrl.OACS&oacsNsMgmt != 0 {
+		a.NoError(err)
+		a.Equal(idCtrl.CNTLID, tested.CNTLID)
+	} else {
+		a.Error(err)
+		a.Nil(tested)
+	}
+}
+
+func TestCreateNamespace(t *testing.T) {
+	// TODO re-verify this test code using the namespace management support NVMe device
+	// Creating and deleting namespaces changes the namespace layout of the device, so this test
+	// should run only on the device prepared for it.
+	/*
+		a := assert.New(t)
+
+		dev, _ := os.Open(targetDevice)
+
+		idCtrl := identify.CtrlIdentify{}
+		a.NoError(identify.GetCtrlIdentify(dev, &idCtrl))
+
+		nsid, err := CreateNamespace(dev, Attributes{NSZE: 1 << 20, NCAP: 1 << 20})
+		a.NoError(err)
+		a.NotZero(nsid)
+
+		a.NoError(Attach(dev, nsid, []uint16{idCtrl.CNTLID}))
+		a.NoError(Detach(dev, nsid, []uint16{idCtrl.CNTLID}))
+		a.NoError(DeleteNamespace(dev, nsid))
+	*/
+}
@@ -3,11 +3,16 @@ package nvme
 import (
 	"fmt"
 	"github.com/sungup/go-nvmecli/pkg/ioctl"
+	"github.com/sungup/go-nvmecli/pkg/nvme/spec"
 	"os"
+	"path/filepath"
 	"reflect"
+	"regexp"
+	"syscall"
 	"unsafe"
 )
 
+// opcode is an admin command opcode.
 type opcode uint8
 
 const (
@@ -40,6 +45,36 @@ const (
 	AdminGetLBAStatus  = opcode(0x86)
 )
 
+// String returns the name of the admin command.
+func (o opcode) String() string {
+	return spec.AdminOpcodeName(uint8(o))
+}
+
+// ioOpcode is a NVM command set I/O command opcode. The I/O commands share the opcode values with
+// the admin commands, so they have their own type to be named correctly.
+type ioOpcode uint8
+
+const (
+	IOFlush        = ioOpcode(0x00)
+	IOWrite        = ioOpcode(0x01)
+	IORead         = ioOpcode(0x02)
+	IOWriteUncor   = ioOpcode(0x04)
+	IOCompare      = ioOpcode(0x05)
+	IOWriteZeroes  = ioOpcode(0x08)
+	IODatasetMgmt  = ioOpcode(0x09)
+	IOVerify       = ioOpcode(0x0c)
+	IOResvRegister = ioOpcode(0x0d)
+	IOResvReport   = ioOpcode(0x0e)
+	IOResvAcquire  = ioOpcode(0x11)
+	IOResvRelease  = ioOpcode(0x15)
+	IOCopy         = ioOpcode(0x19)
+)
+
+// String returns the name of the I/O command.
+func (o ioOpcode) String() string {
+	return spec.IOOpcodeName(uint8(o))
+}
+
 // nvmeCmd interface has two function to set the metadata pointer and data block pointer. To reduce
 // code duplication to set the pointer through object -> interface -> reflection (for type checking)
 // and pointer assign, each command structure should serve these interface function.
@@ -68,7 +103,7 @@ func getPtr(data interface{}) (uintptr, uint32, error) {
 
 // UserIO is a structure to send normal io command to a NVMe device.
 type UserIO struct {
-	OpCode  opcode
+	OpCode  ioOpcode
 	Flags   uint8
 	Control uint16
 	NBlocks uint16
@@ -191,7 +226,96 @@ const (
 	iocIOCmd64     = ioctl.IOCInOut | iocNVMeType | (0x48 << ioctl.NrShift) | uint64(unsafe.Sizeof(PassthruCmd64{})<<ioctl.SizeShift)
 )
 
-// IOCtlAdminCmd issues an received admin command.
+// IOCtlAdminCmd issues an received admin command. If the command has been completed with non-zero
+// status field, IOCtlAdminCmd returns the CompletionError.
 func IOCtlAdminCmd(file *os.File, cmd *AdminCmd) error {
-	return ioctl.Submit(file, uintptr(iocAdminCmd), uintptr(unsafe.Pointer(cmd)))
+	if status, err := ioctl.Submit(file, uintptr(iocAdminCmd), uintptr(unsafe.Pointer(cmd))); err != nil {
+		return err
+	} else if status != 0 {
+		return &CompletionError{OpCode: cmd.OpCode, Status: uint16(status)}
+	}
+
+	return nil
+}
+
+// IOCtlReset requests the kernel to reset the controller. The file should be the controller
+// character device like /dev/nvme0.
+func IOCtlReset(file *os.File) error {
+	_, err := ioctl.Submit(file, uintptr(iocReset), 0)
+	return err
+}
+
+// IOCtlSubsysReset requests the kernel to reset the NVM subsystem which the controller belongs to.
+func IOCtlSubsysReset(file *os.File) error {
+	_, err := ioctl.Submit(file, uintptr(iocSubSysReset), 0)
+	return err
+}
+
+// IOCtlRescan requests the kernel to rescan the namespaces of the controller. The file should be
+// the controller character device like /dev/nvme0.
+func IOCtlRescan(file *os.File) error {
+	_, err := ioctl.Submit(file, uintptr(iocRescan), 0)
+	return err
+}
+
+// capNSSRS is the NVM Subsystem Reset Supported bit of the Controller Capabilities register.
+const capNSSRS = uint64(1) << 36
+
+// sysClassNVMe is the sysfs directory of the NVMe controllers.
+var sysClassNVMe = "/sys/class/nvme"
+
+// ctrlDevice matches the controller character device name like nvme0.
+var ctrlDevice = regexp.MustCompile(`^nvme[0-9]+$`)
+
+// resourcePath returns the sysfs path of the PCI BAR0 which has the controller registers.
+func resourcePath(device string) (string, error) {
+	name := filepath.Base(device)
+	if !ctrlDevice.MatchString(name) {
+		return "", fmt.Errorf("%s is not a controller character device", device)
+	}
+
+	return filepath.Join(sysClassNVMe, name, "device", "resource0"), nil
+}
+
+// ReadCAP reads the Controller Capabilities register of the PCIe controller through the BAR0
+// resource in sysfs. The file should be the controller character device like /dev/nvme0.
+func ReadCAP(file *os.File) (uint64, error) {
+	return readCAP(file.Name())
+}
+
+// readCAP reads the Controller Capabilities register of the controller device.
+func readCAP(device string) (uint64, error) {
+	path, err := resourcePath(device)
+	if err != nil {
+		return 0, err
+	}
+
+	res, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+
+	defer func() { _ = res.Close() }()
+
+	regs, err := syscall.Mmap(int(res.Fd()), 0, os.Getpagesize(), syscall.PROT_READ, syscall.MAP_SHARED)
+	if err != nil {
+		return 0, err
+	}
+
+	defer func() { _ = syscall.Munmap(regs) }()
+
+	// the 64bit register is read by two 32bit accesses like nvme-cli
+	lo := *(*uint32)(unsafe.Pointer(&regs[0]))
+	hi := *(*uint32)(unsafe.Pointer(&regs[4]))
+
+	return uint64(hi)<<32 | uint64(lo), nil
+}
+
+// SubsysResetSupported returns true if the controller supports the NVM subsystem reset (CAP.NSSRS).
+func SubsysResetSupported(file *os.File) (bool, error) {
+	if regCAP, err := ReadCAP(file); err != nil {
+		return false, err
+	} else {
+		return regCAP&capNSSRS != 0, nil
+	}
 }
@@ -2,6 +2,9 @@ package nvme
 
 import (
 	"github.com/sungup/go-nvmecli/pkg/ioctl"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 	"unsafe"
 
@@ -159,3 +162,65 @@ func TestPassthruCmd_SetMeta(t *testing.T) {
 		a.Equal(tc.size, tested.MetaLength)
 	}
 }
+
+func TestOpcode_String(t *testing.T) {
+	a := assert.New(t)
+
+	a.Equal("Get Log Page", AdminGetLogPage.String())
+	a.Equal("Sanitize", AdminSanitizeNVM.String())
+	a.Equal("Vendor Specific (C0h)", opcode(0xC0).String())
+	a.Equal("Unknown (03h)", opcode(0x03).String())
+}
+
+func TestIOOpcode_String(t *testing.T) {
+	a := assert.New(t)
+
+	a.Equal("Read", IORead.String())
+	a.Equal("Dataset Management", IODatasetMgmt.String())
+	a.Equal("Vendor Specific (C0h)", ioOpcode(0xC0).String())
+	a.Equal("Unknown (03h)", ioOpcode(0x03).String())
+
+	// same opcode value is named by the command type
+	a.Equal("Identify", AdminIdentify.String())
+	a.NotEqual(AdminIdentify.String(), ioOpcode(AdminIdentify).String())
+}
+
+func TestResourcePath(t *testing.T) {
+	a := assert.New(t)
+
+	tested, err := resourcePath("/dev/nvme12")
+	a.NoError(err)
+	a.Equal("/sys/class/nvme/nvme12/device/resource0", tested)
+
+	for _, name := range []string{"/dev/nvme0n1", "/dev/sda", "nvme"} {
+		_, err = resourcePath(name)
+		a.Error(err, name)
+	}
+}
+
+func TestReadCAP(t *testing.T) {
+	a := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "sysfs")
+	a.NoError(err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	origin := sysClassNVMe
+	sysClassNVMe = dir
+	defer func() { sysClassNVMe = origin }()
+
+	// CAP with NSSRS at the beginning of BAR0
+	a.NoError(os.MkdirAll(filepath.Join(dir, "nvme0", "device"), 0755))
+	regs := make([]byte, os.Getpagesize())
+	copy(regs, []byte{0xFF, 0x07, 0x00, 0x00, 0x30, 0x00, 0x00, 0x00})
+	a.NoError(ioutil.WriteFile(filepath.Join(dir, "nvme0", "device", "resource0"), regs, 0644))
+
+	tested, err := readCAP("/dev/nvme0")
+	a.NoError(err)
+	a.Equal(uint64(0x30000007FF), tested)
+	a.NotZero(tested & capNSSRS)
+
+	// no resource
+	_, err = readCAP("/dev/nvme1")
+	a.Error(err)
+}
@@ -0,0 +1,123 @@
+package ocp
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"github.com/sungup/go-nvmecli/pkg/nvme/getlog"
+	"github.com/sungup/go-nvmecli/pkg/nvme/types"
+	"github.com/sungup/go-nvmecli/pkg/utils"
+	"os"
+)
+
+// --------------------------- //
+// LID C1h: Error Recovery Log //
+// --------------------------- //
+
+// errorRecoveryGUID is the Log Page GUID of the Error Recovery log page.
+var errorRecoveryGUID = newGUID("5A1983BA3DFD4DABAE3430FE2131D944")
+
+// Log page versions of the Error Recovery log page which ParseErrorRecovery can parse. The fields
+// added by a newer version are zero in the older versions.
+const (
+	errorRecoveryMinLPV = uint16(0x0001)
+	errorRecoveryMaxLPV = uint16(0x0003)
+)
+
+// Panic Reset Action bits which the host should take to recover the device from the panic.
+const (
+	PanicResetCtrl      = uint8(1 << 0) // NVMe Controller Reset
+	PanicResetSubsystem = uint8(1 << 1) // NVM Subsystem Reset
+	PanicResetFLR       = uint8(1 << 2) // PCIe Function Level Reset
+	PanicResetPERST     = uint8(1 << 3) // PERST#
+	PanicResetPowerCyc  = uint8(1 << 4) // Main Power Cycle
+	PanicResetHotReset  = uint8(1 << 5) // PCIe Conventional Hot Reset
+)
+
+// recoveryAction is the Device Recovery Action 1 after the panic reset action.
+type recoveryAction uint8
+
+const (
+	RecoveryNoAction          = recoveryAction(0x00)
+	RecoveryFormatNVM         = recoveryAction(0x01)
+	RecoveryVendorCommand     = recoveryAction(0x02)
+	RecoveryVendorAnalysis    = recoveryAction(0x03)
+	RecoveryDeviceReplacement = recoveryAction(0x04)
+	RecoverySanitize          = recoveryAction(0x05)
+)
+
+// String returns the name of the device recovery action.
+func (a recoveryAction) String() string {
+	switch a {
+	case RecoveryNoAction:
+		return "No Action Required"
+	case RecoveryFormatNVM:
+		return "Format NVM Required"
+	case RecoveryVendorCommand:
+		return "Vendor Specific Command Required"
+	case RecoveryVendorAnalysis:
+		return "Vendor Analysis Required"
+	case RecoveryDeviceReplacement:
+		return "Device Replacement Required"
+	case RecoverySanitize:
+		return "Sanitize Required"
+	default:
+		return fmt.Sprintf("Reserved (%02Xh)", uint8(a))
+	}
+}
+
+// ErrorRecovery is the Error Recovery log page of the OCP Datacenter NVMe SSD specification.
+type ErrorRecovery struct {
+	PanicResetWaitTime    uint16       // [01:00] in msec
+	PanicResetAction      uint8        // [02]
+	DeviceRecoveryAction1 uint8        // [03]
+	PanicID               types.Uint64 // [11:04]
+	DeviceCapabilities    uint32       // [15:12] supported panic reset actions
+	VendorRecoveryOpcode  uint8        // [16] Vendor Specific Recovery Opcode
+	_                     [3]byte      // [19:17] reserved
+	VendorCmdCDW12        uint32       // [23:20]
+	VendorCmdCDW13        uint32       // [27:24]
+	VendorCmdTimeout      uint8        // [28] in seconds
+	DeviceRecoveryAction2 uint8        // [29]
+	DeviceRecoveryTimeout uint8        // [30] Device Recovery Action 2 Timeout in seconds
+	_                     [463]byte    // [493:31] reserved
+	LPV                   uint16       // [495:494] Log Page Version
+	LPG                   guid         // [511:496] Log Page GUID
+}
+
+// Panicked returns true if the device reports a panic. The Panic ID is 0h if no panic occurred.
+func (e *ErrorRecovery) Panicked() bool {
+	return e.PanicID.Uint() != 0
+}
+
+// RecoveryAction returns the Device Recovery Action 1 after the panic reset.
+//goland:noinspection GoExportedFuncWithUnexportedType
+func (e *ErrorRecovery) RecoveryAction() recoveryAction {
+	return recoveryAction(e.DeviceRecoveryAction1)
+}
+
+// GetErrorRecovery retrieves the Error Recovery log page (C1h) into v.
+func GetErrorRecovery(file *os.File, v interface{}) error {
+	return getlog.GetVendorCMD(file, 0, LIDErrorRecovery, 0, 0, v)
+}
+
+// ParseErrorRecovery parses the Error Recovery log page from raw data and checks the log page GUID
+// and version.
+func ParseErrorRecovery(raw []byte) (*ErrorRecovery, error) {
+	if len(raw) != ocpLogSz {
+		return nil, fmt.Errorf("unexpected error recovery raw data size: %d", len(raw))
+	}
+
+	e := ErrorRecovery{}
+	if err := binary.Read(bytes.NewReader(raw), utils.SystemEndian, &e); err != nil {
+		return nil, err
+	}
+
+	if err := checkGUID(LIDErrorRecovery, errorRecoveryGUID, e.LPG); err != nil {
+		return nil, err
+	} else if err = checkVersion(LIDErrorRecovery, errorRecoveryMinLPV, errorRecoveryMaxLPV, e.LPV); err != nil {
+		return nil, err
+	}
+
+	return &e, nil
+}
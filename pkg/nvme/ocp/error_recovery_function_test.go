@@ -0,0 +1,63 @@
+package ocp
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/sungup/go-nvmecli/pkg/utils"
+	"testing"
+	"unsafe"
+)
+
+func TestErrorRecoverySize(t *testing.T) {
+	a := assert.New(t)
+
+	a.Equal(uintptr(ocpLogSz), unsafe.Sizeof(ErrorRecovery{}))
+}
+
+func TestRecoveryAction_String(t *testing.T) {
+	a := assert.New(t)
+
+	a.Equal("No Action Required", RecoveryNoAction.String())
+	a.Equal("Sanitize Required", RecoverySanitize.String())
+	a.Equal("Reserved (10h)", recoveryAction(0x10).String())
+}
+
+func TestParseErrorRecovery(t *testing.T) {
+	a := assert.New(t)
+
+	raw := make([]byte, ocpLogSz)
+	utils.SystemEndian.PutUint16(raw[0:], 100)
+	raw[2] = PanicResetCtrl | PanicResetPowerCyc
+	raw[3] = uint8(RecoveryFormatNVM)
+	utils.SystemEndian.PutUint64(raw[4:], 0xDEAD)
+	utils.SystemEndian.PutUint32(raw[12:], 0x3F)
+	utils.SystemEndian.PutUint16(raw[494:], 2)
+	copy(raw[496:], errorRecoveryGUID[:])
+
+	tested, err := ParseErrorRecovery(raw)
+	a.NoError(err)
+	a.NotNil(tested)
+
+	a.Equal(uint16(100), tested.PanicResetWaitTime)
+	a.NotZero(tested.PanicResetAction & PanicResetPowerCyc)
+	a.Zero(tested.PanicResetAction & PanicResetSubsystem)
+	a.True(tested.Panicked())
+	a.Equal(uint64(0xDEAD), tested.PanicID.Uint())
+	a.Equal(RecoveryFormatNVM, tested.RecoveryAction())
+	a.Equal(uint32(0x3F), tested.DeviceCapabilities)
+
+	// wrong size
+	_, err = ParseErrorRecovery(raw[:ocpLogSz-1])
+	a.Error(err)
+
+	// unsupported version
+	for _, lpv := range []uint16{0, errorRecoveryMaxLPV + 1} {
+		utils.SystemEndian.PutUint16(raw[494:], lpv)
+		_, err = ParseErrorRecovery(raw)
+		a.ErrorIs(err, ErrUnsupportedVersion, "LPV %d", lpv)
+	}
+
+	// wrong GUID
+	copy(raw[496:], smartExtendedGUID[:])
+	_, err = ParseErrorRecovery(raw)
+	a.Error(err)
+}
@@ -0,0 +1,5 @@
+package ocp
+
+const (
+	expectedNSId = 1
+)
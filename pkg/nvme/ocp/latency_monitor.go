@@ -0,0 +1,134 @@
+package ocp
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"github.com/sungup/go-nvmecli/pkg/nvme/getlog"
+	"github.com/sungup/go-nvmecli/pkg/nvme/types"
+	"github.com/sungup/go-nvmecli/pkg/utils"
+	"os"
+)
+
+// ---------------------------- //
+// LID C3h: Latency Monitor Log //
+// ---------------------------- //
+
+// latencyMonitorGUID is the Log Page GUID of the Latency Monitor log page.
+var latencyMonitorGUID = newGUID("85D45E58D4E643709C6C84D08CC07A92")
+
+const (
+	latencyBuckets = 4
+	latencyOps     = 3
+)
+
+// latencyOp is the operation index of the latency timestamps and the measured latencies. The
+// operations are stored from Deallocate/TRIM to Read in each bucket.
+type latencyOp int
+
+const (
+	LatencyDeallocate = latencyOp(0)
+	LatencyWrite      = latencyOp(1)
+	LatencyRead       = latencyOp(2)
+)
+
+// String returns the operation name.
+func (o latencyOp) String() string {
+	switch o {
+	case LatencyRead:
+		return "Read"
+	case LatencyWrite:
+		return "Write"
+	case LatencyDeallocate:
+		return "Deallocate/TRIM"
+	default:
+		return fmt.Sprintf("Unknown (%d)", int(o))
+	}
+}
+
+// Feature Status bits of the Latency Monitor log page.
+const (
+	LatencyStatusMonitorEnabled = uint8(1 << 0) // Latency Monitor Feature Enabled
+	LatencyStatusActiveMeasure  = uint8(1 << 1) // Active Latency Measurement enabled
+	LatencyStatusStaticMeasure  = uint8(1 << 2) // Static Latency Measurement enabled
+)
+
+// LatencyWindow is the latency counters of the active or the static window. The bucket counters are
+// stored from the bucket 0, and the first dword of each bucket is reserved. The latency timestamps
+// and the measured latencies are the maximum or minimum latency command of each bucket and
+// operation configured by the latency config, and they are stored from the bucket 3.
+type LatencyWindow struct {
+	BucketCounter     [latencyBuckets][latencyOps + 1]uint32 // bucket counters after a reserved dword
+	LatencyTimestamp  [latencyBuckets][latencyOps]uint64     // timestamps of the measured latency
+	MeasuredLatency   [latencyBuckets][latencyOps]uint16     // measured latencies in 1ms unit
+	LatencyStampUnits uint16                                 // bit is set if the timestamp is not from Set Features Timestamp
+	_                 [22]byte                               // reserved
+}
+
+// index converts the bucket number to the index of the latency timestamps and measured latencies.
+func (w *LatencyWindow) index(bucket int) int {
+	return latencyBuckets - 1 - bucket
+}
+
+// Count returns the bucket counter of the operation.
+func (w *LatencyWindow) Count(bucket int, op latencyOp) uint32 {
+	return w.BucketCounter[bucket][op+1]
+}
+
+// Latency returns the measured latency and its timestamp of the bucket and the operation.
+func (w *LatencyWindow) Latency(bucket int, op latencyOp) (latency uint16, timestamp uint64) {
+	i := w.index(bucket)
+	return w.MeasuredLatency[i][op], w.LatencyTimestamp[i][op]
+}
+
+// LatencyMonitor is the Latency Monitor log page of the OCP Datacenter NVMe SSD specification.
+type LatencyMonitor struct {
+	FeatureStatus              uint8    // [00]
+	_                          uint8    // [01] reserved
+	ActiveBucketTimer          uint16   // [03:02] elapsed time of the active window in 5 minutes unit
+	ActiveBucketTimerThreshold uint16   // [05:04] active window in 5 minutes unit
+	ActiveThresholdA           uint8    // [06] in 5ms unit
+	ActiveThresholdB           uint8    // [07] in 5ms unit
+	ActiveThresholdC           uint8    // [08] in 5ms unit
+	ActiveThresholdD           uint8    // [09] in 5ms unit
+	ActiveLatencyConfig        uint16   // [11:10]
+	ActiveLatencyMinWindow     uint8    // [12] in 100ms unit
+	_                          [19]byte // [31:13] reserved
+
+	Active LatencyWindow // [239:32]
+	Static LatencyWindow // [447:240]
+
+	DebugLogTriggerEnable     uint16       // [449:448]
+	DebugLogMeasuredLatency   uint16       // [451:450]
+	DebugLogLatencyStamp      types.Uint64 // [459:452]
+	DebugLogPointer           uint16       // [461:460]
+	DebugCounterTriggerSource uint16       // [463:462]
+	DebugLogStampUnits        uint8        // [464]
+	_                         [29]byte     // [493:465] reserved
+	LPV                       uint16       // [495:494] Log Page Version
+	LPG                       guid         // [511:496] Log Page GUID
+}
+
+// GetLatencyMonitor retrieves the Latency Monitor log page (C3h) into v.
+func GetLatencyMonitor(file *os.File, v interface{}) error {
+	return getlog.GetVendorCMD(file, 0, LIDLatencyMonitor, 0, 0, v)
+}
+
+// ParseLatencyMonitor parses the Latency Monitor log page from raw data and checks the log page
+// GUID.
+func ParseLatencyMonitor(raw []byte) (*LatencyMonitor, error) {
+	if len(raw) != ocpLogSz {
+		return nil, fmt.Errorf("unexpected latency monitor raw data size: %d", len(raw))
+	}
+
+	l := LatencyMonitor{}
+	if err := binary.Read(bytes.NewReader(raw), utils.SystemEndian, &l); err != nil {
+		return nil, err
+	}
+
+	if err := checkGUID(LIDLatencyMonitor, latencyMonitorGUID, l.LPG); err != nil {
+		return nil, err
+	}
+
+	return &l, nil
+}
@@ -0,0 +1,83 @@
+package ocp
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/sungup/go-nvmecli/pkg/utils"
+	"testing"
+	"unsafe"
+)
+
+func TestLatencyMonitorSize(t *testing.T) {
+	a := assert.New(t)
+
+	a.Equal(uintptr(ocpLogSz), unsafe.Sizeof(LatencyMonitor{}))
+	a.Equal(uintptr(32), unsafe.Offsetof(LatencyMonitor{}.Active))
+	a.Equal(uintptr(240), unsafe.Offsetof(LatencyMonitor{}.Static))
+	a.Equal(uintptr(448), unsafe.Offsetof(LatencyMonitor{}.DebugLogTriggerEnable))
+}
+
+func TestLatencyOp_String(t *testing.T) {
+	a := assert.New(t)
+
+	a.Equal("Read", LatencyRead.String())
+	a.Equal("Write", LatencyWrite.String())
+	a.Equal("Deallocate/TRIM", LatencyDeallocate.String())
+	a.Equal("Unknown (3)", latencyOp(3).String())
+}
+
+func TestParseLatencyMonitor(t *testing.T) {
+	a := assert.New(t)
+
+	raw := make([]byte, ocpLogSz)
+	raw[0] = LatencyStatusMonitorEnabled | LatencyStatusActiveMeasure
+	utils.SystemEndian.PutUint16(raw[2:], 3)
+	raw[6] = 1
+
+	// Active Bucket Counter 1 [63:48]: Write at [59:56]
+	utils.SystemEndian.PutUint32(raw[56:], 100)
+	// Active Latency Timestamp [191:96]: bucket 2 Deallocate at [127:120]
+	utils.SystemEndian.PutUint64(raw[120:], 0x123456789)
+	// Active Measured Latency [215:192]: bucket 2 Deallocate at [199:198]
+	utils.SystemEndian.PutUint16(raw[198:], 25)
+	// Active Measured Latency: bucket 0 Read at [215:214]
+	utils.SystemEndian.PutUint16(raw[214:], 30)
+
+	// Static Bucket Counter 0 [255:240]: Read at [255:252], and [243:240] is reserved
+	utils.SystemEndian.PutUint32(raw[252:], 7)
+	utils.SystemEndian.PutUint32(raw[240:], 0xFFFF)
+
+	utils.SystemEndian.PutUint64(raw[452:], 0xABCD)
+	copy(raw[496:], latencyMonitorGUID[:])
+
+	tested, err := ParseLatencyMonitor(raw)
+	a.NoError(err)
+	a.NotNil(tested)
+
+	a.NotZero(tested.FeatureStatus & LatencyStatusActiveMeasure)
+	a.Zero(tested.FeatureStatus & LatencyStatusStaticMeasure)
+	a.Equal(uint16(3), tested.ActiveBucketTimer)
+	a.Equal(uint8(1), tested.ActiveThresholdA)
+	a.Equal(uint32(100), tested.Active.Count(1, LatencyWrite))
+	a.Equal(uint32(0), tested.Active.Count(1, LatencyRead))
+	a.Equal(uint32(7), tested.Static.Count(0, LatencyRead))
+	a.Equal(uint32(0), tested.Static.Count(0, LatencyDeallocate))
+	a.Equal(uint32(0), tested.Static.Count(3, LatencyRead))
+
+	latency, timestamp := tested.Active.Latency(2, LatencyDeallocate)
+	a.Equal(uint16(25), latency)
+	a.Equal(uint64(0x123456789), timestamp)
+
+	latency, timestamp = tested.Active.Latency(0, LatencyRead)
+	a.Equal(uint16(30), latency)
+	a.Zero(timestamp)
+	a.Equal(uint64(0xABCD), tested.DebugLogLatencyStamp.Uint())
+
+	// wrong size
+	_, err = ParseLatencyMonitor(raw[:ocpLogSz-1])
+	a.Error(err)
+
+	// wrong GUID
+	copy(raw[496:], smartExtendedGUID[:])
+	_, err = ParseLatencyMonitor(raw)
+	a.Error(err)
+}
@@ -0,0 +1,25 @@
+// +build with_phys_device
+
+package ocp
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"testing"
+)
+
+func TestGetLatencyMonitor(t *testing.T) {
+	a := assert.New(t)
+
+	dev, _ := os.Open(targetDevice)
+	defer func() { _ = dev.Close() }()
+
+	raw := make([]byte, ocpLogSz)
+
+	// the device which doesn't follow the OCP specification returns the vendor specific data
+	if err := GetLatencyMonitor(dev, raw); err == nil {
+		if tested, err := ParseLatencyMonitor(raw); err == nil {
+			a.NotNil(tested)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package ocp
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/sungup/go-nvmecli/pkg/nvme/spec"
+	"strings"
+)
+
+// Log page identifiers defined by the OCP Datacenter NVMe SSD specification.
+const (
+	LIDSMARTExtended   = spec.LogID(0xC0)
+	LIDErrorRecovery   = spec.LogID(0xC1)
+	LIDLatencyMonitor  = spec.LogID(0xC3)
+	LIDTelemetryString = spec.LogID(0xC9)
+)
+
+// guid is a log page GUID of the OCP log pages. The GUID is stored in little endian, so the first
+// byte of the log page is the last byte of the GUID string.
+type guid [16]byte
+
+// newGUID converts the GUID string in the OCP specification to the stored byte order.
+func newGUID(s string) guid {
+	raw, err := hex.DecodeString(s)
+	if err != nil || len(raw) != len(guid{}) {
+		panic(fmt.Sprintf("invalid GUID string: %s", s))
+	}
+
+	g := guid{}
+	for i := range g {
+		g[i] = raw[len(raw)-1-i]
+	}
+
+	return g
+}
+
+// String returns the GUID string in the OCP specification notation.
+func (g guid) String() string {
+	reversed := make([]byte, len(g))
+	for i := range g {
+		reversed[i] = g[len(g)-1-i]
+	}
+
+	return strings.ToUpper(hex.EncodeToString(reversed))
+}
+
+// MarshalText encodes the GUID as the GUID string to make the log pages JSON readable.
+func (g guid) MarshalText() ([]byte, error) {
+	return []byte(g.String()), nil
+}
+
+// ErrUnexpectedGUID is returned if the log page GUID is not the OCP defined GUID. The device which
+// doesn't follow the OCP specification may use the same LID for its own vendor specific log page.
+var ErrUnexpectedGUID = errors.New("unexpected log page GUID")
+
+// checkGUID checks the log page GUID.
+func checkGUID(lid spec.LogID, expected, actual guid) error {
+	if expected != actual {
+		return fmt.Errorf("%w of LID %02Xh: %v", ErrUnexpectedGUID, uint8(lid), actual)
+	}
+
+	return nil
+}
+
+// ErrUnsupportedVersion is returned if the log page version is not in the range of the versions
+// the parser knows. The later versions may change the meaning of the existing fields.
+var ErrUnsupportedVersion = errors.New("unsupported log page version")
+
+// checkVersion checks the log page version is in [min, max].
+func checkVersion(lid spec.LogID, min, max, actual uint16) error {
+	if actual < min || actual > max {
+		return fmt.Errorf("%w of LID %02Xh: %d", ErrUnsupportedVersion, uint8(lid), actual)
+	}
+
+	return nil
+}
+
+// ocpLogSz is the size of the fixed size OCP log pages which have the log page version and GUID at
+// the end of the log page.
+const ocpLogSz = 512
+
+// asciiString trims the NUL and space padding of the ASCII string field.
+func asciiString(raw []byte) string {
+	return strings.TrimRight(string(raw), "\x00 ")
+}
@@ -0,0 +1,51 @@
+package ocp
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestNewGUID(t *testing.T) {
+	a := assert.New(t)
+
+	tested := newGUID("B13A83691A8F408B9EA495940057AA44")
+	a.Equal(byte(0x44), tested[0])
+	a.Equal(byte(0xB1), tested[15])
+	a.Equal("B13A83691A8F408B9EA495940057AA44", tested.String())
+
+	a.Panics(func() { newGUID("B13A8369") })
+	a.Panics(func() { newGUID("not a hexadecimal string") })
+}
+
+func TestCheckGUID(t *testing.T) {
+	a := assert.New(t)
+
+	a.NoError(checkGUID(LIDTelemetryString, telemetryStringGUID, telemetryStringGUID))
+	a.ErrorIs(checkGUID(LIDTelemetryString, telemetryStringGUID, guid{}), ErrUnexpectedGUID)
+}
+
+func TestCheckVersion(t *testing.T) {
+	a := assert.New(t)
+
+	a.NoError(checkVersion(LIDSMARTExtended, 1, 4, 1))
+	a.NoError(checkVersion(LIDSMARTExtended, 1, 4, 4))
+	a.ErrorIs(checkVersion(LIDSMARTExtended, 1, 4, 0), ErrUnsupportedVersion)
+	a.ErrorIs(checkVersion(LIDSMARTExtended, 1, 4, 5), ErrUnsupportedVersion)
+}
+
+func TestASCIIString(t *testing.T) {
+	a := assert.New(t)
+
+	a.Equal("FIFO", asciiString([]byte("FIFO\x00\x00\x00")))
+	a.Equal("FIFO", asciiString([]byte("FIFO   ")))
+	a.Equal("", asciiString(make([]byte, 16)))
+}
+
+func TestGUID_MarshalText(t *testing.T) {
+	a := assert.New(t)
+
+	tested, err := json.Marshal(struct{ LPG guid }{LPG: telemetryStringGUID})
+	a.NoError(err)
+	a.Equal(`{"LPG":"B13A83691A8F408B9EA495940057AA44"}`, string(tested))
+}
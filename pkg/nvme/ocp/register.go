@@ -0,0 +1,62 @@
+package ocp
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"github.com/sungup/go-nvmecli/pkg/nvme/feature"
+	"github.com/sungup/go-nvmecli/pkg/nvme/registry"
+	"github.com/sungup/go-nvmecli/pkg/utils"
+	"unsafe"
+)
+
+// ------------------------------- //
+// Vendor Log/Feature Registration //
+// ------------------------------- //
+
+// decodeOCPLog wraps the OCP log page parser to let the registry try the next decoder if the log
+// page GUID is not matched or the log page version is not supported, so the raw log page is
+// returned at last.
+func decodeOCPLog(parse func(raw []byte) (interface{}, error)) func(raw []byte) (interface{}, error) {
+	return func(raw []byte) (interface{}, error) {
+		if v, err := parse(raw); errors.Is(err, ErrUnexpectedGUID) || errors.Is(err, ErrUnsupportedVersion) {
+			return nil, registry.ErrMismatch
+		} else {
+			return v, err
+		}
+	}
+}
+
+// decodeLatencyMonitorFeature decodes the Latency Monitor feature data structure.
+func decodeLatencyMonitorFeature(_ uint32, raw []byte) (interface{}, error) {
+	f := feature.LatencyMonitor{}
+	if err := binary.Read(bytes.NewReader(raw), utils.SystemEndian, &f); err != nil {
+		return nil, err
+	}
+
+	return &f, nil
+}
+
+// The OCP log pages and features are registered for any vendor, because the drives of many vendors
+// follow the OCP Datacenter NVMe SSD specification.
+func init() {
+	registry.RegisterLog(registry.Key{VID: registry.AnyVendor, ID: uint8(LIDSMARTExtended)}, registry.LogDecoder{
+		Size:   ocpLogSz,
+		Decode: decodeOCPLog(func(raw []byte) (interface{}, error) { return ParseSMARTExtended(raw) }),
+	})
+
+	registry.RegisterLog(registry.Key{VID: registry.AnyVendor, ID: uint8(LIDErrorRecovery)}, registry.LogDecoder{
+		Size:   ocpLogSz,
+		Decode: decodeOCPLog(func(raw []byte) (interface{}, error) { return ParseErrorRecovery(raw) }),
+	})
+
+	registry.RegisterLog(registry.Key{VID: registry.AnyVendor, ID: uint8(LIDLatencyMonitor)}, registry.LogDecoder{
+		Size:   ocpLogSz,
+		Decode: decodeOCPLog(func(raw []byte) (interface{}, error) { return ParseLatencyMonitor(raw) }),
+	})
+
+	registry.RegisterFeature(registry.Key{VID: registry.AnyVendor, ID: uint8(feature.FIDOCPLatencyMonitor)}, registry.FeatureDecoder{
+		Size:   int(unsafe.Sizeof(feature.LatencyMonitor{})),
+		Decode: decodeLatencyMonitorFeature,
+	})
+}
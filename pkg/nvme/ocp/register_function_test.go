@@ -0,0 +1,79 @@
+package ocp
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/sungup/go-nvmecli/pkg/nvme/feature"
+	"github.com/sungup/go-nvmecli/pkg/nvme/registry"
+	"github.com/sungup/go-nvmecli/pkg/nvme/spec"
+	"github.com/sungup/go-nvmecli/pkg/utils"
+	"testing"
+)
+
+func TestDecodeOCPLog(t *testing.T) {
+	a := assert.New(t)
+
+	decoders := registry.LookupLog(0x1234, "ANY MODEL", LIDSMARTExtended, 0)
+	a.Len(decoders, 1)
+
+	raw := make([]byte, ocpLogSz)
+
+	// GUID mismatch lets the registry try the next decoder
+	_, err := decoders[0].Decode(raw)
+	a.ErrorIs(err, registry.ErrMismatch)
+
+	// unsupported version lets the registry fall back to the raw log page
+	copy(raw[496:], smartExtendedGUID[:])
+	for _, lpv := range []uint16{0, 5} {
+		utils.SystemEndian.PutUint16(raw[494:], lpv)
+		_, err = decoders[0].Decode(raw)
+		a.ErrorIs(err, registry.ErrMismatch, "LPV %d", lpv)
+	}
+
+	utils.SystemEndian.PutUint16(raw[494:], 3)
+	decoded, err := decoders[0].Decode(raw)
+	a.NoError(err)
+	a.IsType(&SMARTExtended{}, decoded)
+
+	// size error is not a mismatch
+	_, err = decoders[0].Decode(raw[:ocpLogSz-1])
+	a.Error(err)
+	a.NotErrorIs(err, registry.ErrMismatch)
+
+	for _, lid := range []spec.LogID{LIDErrorRecovery, LIDLatencyMonitor} {
+		a.Len(registry.LookupLog(0x1234, "", lid, 0), 1)
+	}
+}
+
+func TestDecodeVendorLog_UnsupportedVersion(t *testing.T) {
+	a := assert.New(t)
+
+	raw := make([]byte, ocpLogSz)
+	utils.SystemEndian.PutUint16(raw[494:], 5)
+	copy(raw[496:], smartExtendedGUID[:])
+
+	read := func(size int64) ([]byte, error) { return raw[:size], nil }
+
+	// the newer log page version is returned as the raw log page
+	decoded, err := registry.DecodeVendorLog(0x1234, "ANY MODEL", LIDSMARTExtended, 0, read)
+	a.NoError(err)
+	a.Equal(raw, decoded)
+
+	utils.SystemEndian.PutUint16(raw[494:], 3)
+	decoded, err = registry.DecodeVendorLog(0x1234, "ANY MODEL", LIDSMARTExtended, 0, read)
+	a.NoError(err)
+	a.IsType(&SMARTExtended{}, decoded)
+}
+
+func TestDecodeLatencyMonitorFeature(t *testing.T) {
+	a := assert.New(t)
+
+	decoders := registry.LookupFeature(0x1234, "", feature.FIDOCPLatencyMonitor)
+	a.Len(decoders, 1)
+
+	raw := make([]byte, decoders[0].Size)
+	raw[12] = 1
+
+	decoded, err := decoders[0].Decode(0, raw)
+	a.NoError(err)
+	a.Equal(uint8(1), decoded.(*feature.LatencyMonitor).LatencyMonitorEnable)
+}
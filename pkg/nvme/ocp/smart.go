@@ -0,0 +1,147 @@
+package ocp
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"github.com/sungup/go-nvmecli/pkg/nvme/getlog"
+	"github.com/sungup/go-nvmecli/pkg/nvme/types"
+	"github.com/sungup/go-nvmecli/pkg/utils"
+	"os"
+)
+
+// -------------------------------------------- //
+// LID C0h: SMART / Health Information Extended //
+// -------------------------------------------- //
+
+// smartExtendedGUID is the Log Page GUID of the SMART / Health Information Extended log page.
+var smartExtendedGUID = newGUID("AFD514C97C6F4F9CA4F2BFEA2810AFC5")
+
+// Log page versions of the SMART / Health Information Extended log page which ParseSMARTExtended
+// can parse. The fields added by a newer version are zero in the older versions.
+const (
+	smartExtendedMinLPV = uint16(0x0001)
+	smartExtendedMaxLPV = uint16(0x0004)
+)
+
+// NANDBlocks is the bad NAND block count which has the 48bit raw count and the normalized value.
+type NANDBlocks struct {
+	RawCount   [6]byte // [05:00]
+	Normalized uint16  // [07:06]
+}
+
+// Raw returns the raw count of the bad NAND blocks.
+func (b NANDBlocks) Raw() uint64 {
+	buffer := make([]byte, 8)
+	copy(buffer, b.RawCount[:])
+
+	return utils.SystemEndian.Uint64(buffer)
+}
+
+// throttlingStatus is the thermal throttling status of the SMART / Health Information Extended.
+type throttlingStatus uint8
+
+const (
+	ThrottlingNone        = throttlingStatus(0x00)
+	ThrottlingFirstLevel  = throttlingStatus(0x01)
+	ThrottlingSecondLevel = throttlingStatus(0x02)
+	ThrottlingThirdLevel  = throttlingStatus(0x03)
+)
+
+// SpecVersion is the version of the OCP Datacenter NVMe SSD specification the device supports.
+type SpecVersion struct {
+	Errata uint8        // [00]
+	Point  types.Uint16 // [02:01]
+	Minor  types.Uint16 // [04:03]
+	Major  uint8        // [05]
+}
+
+// String returns the specification version string like 2.0.0a.
+func (v SpecVersion) String() string {
+	version := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor.Uint(), v.Point.Uint())
+	if v.Errata != 0 {
+		version += string(rune('a' + v.Errata - 1))
+	}
+
+	return version
+}
+
+// SMARTExtended is the SMART / Health Information Extended log page of the OCP Datacenter NVMe SSD
+// specification.
+type SMARTExtended struct {
+	PhysicalMediaUnitsWritten types.Uint128 // [15:00]
+	PhysicalMediaUnitsRead    types.Uint128 // [31:16]
+	BadUserNANDBlocks         NANDBlocks    // [39:32]
+	BadSystemNANDBlocks       NANDBlocks    // [47:40]
+	XORRecoveryCount          uint64        // [55:48]
+	UncorrectableReadErrors   uint64        // [63:56]
+	SoftECCErrors             uint64        // [71:64]
+	E2EDetectedErrors         uint32        // [75:72] End to End Correction Counts: detected
+	E2ECorrectedErrors        uint32        // [79:76] End to End Correction Counts: corrected
+	SystemDataPercentUsed     uint8         // [80]
+	RefreshCounts             [7]byte       // [87:81]
+	MaxUserDataEraseCount     uint32        // [91:88]
+	MinUserDataEraseCount     uint32        // [95:92]
+	ThermalThrottlingCount    uint8         // [96] number of thermal throttling events
+	ThermalThrottlingStatus   uint8         // [97] current throttling status
+	DSSDSpecVersion           SpecVersion   // [103:98]
+	PCIeCorrectableErrors     uint64        // [111:104]
+	IncompleteShutdowns       uint32        // [115:112]
+	_                         uint32        // [119:116] reserved
+	PercentFreeBlocks         uint8         // [120]
+	_                         [7]byte       // [127:121] reserved
+	CapacitorHealth           uint16        // [129:128]
+	NVMeBaseErrataVersion     uint8         // [130]
+	NVMeCmdSetErrataVersion   uint8         // [131]
+	_                         uint32        // [135:132] reserved
+	UnalignedIO               uint64        // [143:136]
+	SecurityVersionNumber     uint64        // [151:144]
+	TotalNUSE                 uint64        // [159:152]
+	PLPStartCount             types.Uint128 // [175:160]
+	EnduranceEstimate         types.Uint128 // [191:176]
+	PCIeLinkRetrainingCount   uint64        // [199:192]
+	PowerStateChangeCount     uint64        // [207:200]
+	_                         [286]byte     // [493:208] reserved
+	LPV                       uint16        // [495:494] Log Page Version
+	LPG                       guid          // [511:496] Log Page GUID
+}
+
+// Refreshes returns the number of the read refresh operations.
+func (s *SMARTExtended) Refreshes() uint64 {
+	buffer := make([]byte, 8)
+	copy(buffer, s.RefreshCounts[:])
+
+	return utils.SystemEndian.Uint64(buffer)
+}
+
+// Throttling returns the current thermal throttling status.
+//goland:noinspection GoExportedFuncWithUnexportedType
+func (s *SMARTExtended) Throttling() throttlingStatus {
+	return throttlingStatus(s.ThermalThrottlingStatus)
+}
+
+// GetSMARTExtended retrieves the SMART / Health Information Extended log page (C0h) into v.
+func GetSMARTExtended(file *os.File, v interface{}) error {
+	return getlog.GetVendorCMD(file, 0, LIDSMARTExtended, 0, 0, v)
+}
+
+// ParseSMARTExtended parses the SMART / Health Information Extended log page from raw data and
+// checks the log page GUID and version.
+func ParseSMARTExtended(raw []byte) (*SMARTExtended, error) {
+	if len(raw) != ocpLogSz {
+		return nil, fmt.Errorf("unexpected SMART extended raw data size: %d", len(raw))
+	}
+
+	s := SMARTExtended{}
+	if err := binary.Read(bytes.NewReader(raw), utils.SystemEndian, &s); err != nil {
+		return nil, err
+	}
+
+	if err := checkGUID(LIDSMARTExtended, smartExtendedGUID, s.LPG); err != nil {
+		return nil, err
+	} else if err = checkVersion(LIDSMARTExtended, smartExtendedMinLPV, smartExtendedMaxLPV, s.LPV); err != nil {
+		return nil, err
+	}
+
+	return &s, nil
+}
@@ -0,0 +1,86 @@
+package ocp
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/sungup/go-nvmecli/pkg/utils"
+	"testing"
+	"unsafe"
+)
+
+func TestSMARTExtendedSize(t *testing.T) {
+	a := assert.New(t)
+
+	a.Equal(uintptr(ocpLogSz), unsafe.Sizeof(SMARTExtended{}))
+}
+
+func TestNANDBlocks_Raw(t *testing.T) {
+	a := assert.New(t)
+
+	tested := NANDBlocks{RawCount: [6]byte{0x01, 0x02, 0x03, 0x04, 0x05, 0x06}, Normalized: 100}
+	a.Equal(uint64(0x060504030201), tested.Raw())
+}
+
+func TestSpecVersion_String(t *testing.T) {
+	a := assert.New(t)
+
+	tested := SpecVersion{Major: 2, Minor: [2]byte{5, 0}}
+	a.Equal("2.5.0", tested.String())
+
+	tested.Errata = 1
+	a.Equal("2.5.0a", tested.String())
+}
+
+func TestParseSMARTExtended(t *testing.T) {
+	a := assert.New(t)
+
+	raw := make([]byte, ocpLogSz)
+	utils.SystemEndian.PutUint64(raw[0:], 0x1000)
+	utils.SystemEndian.PutUint64(raw[16:], 0x2000)
+	copy(raw[32:], []byte{0x10, 0, 0, 0, 0, 0, 99, 0})
+	utils.SystemEndian.PutUint64(raw[64:], 7)
+	utils.SystemEndian.PutUint32(raw[72:], 3)
+	utils.SystemEndian.PutUint32(raw[76:], 2)
+	copy(raw[81:], []byte{0x01, 0x01})
+	raw[96] = 5
+	raw[97] = uint8(ThrottlingSecondLevel)
+	raw[103] = 2
+	utils.SystemEndian.PutUint64(raw[104:], 11)
+	utils.SystemEndian.PutUint16(raw[128:], 95)
+	utils.SystemEndian.PutUint16(raw[494:], 3)
+	copy(raw[496:], smartExtendedGUID[:])
+
+	tested, err := ParseSMARTExtended(raw)
+	a.NoError(err)
+	a.NotNil(tested)
+
+	a.Equal(uint64(0x1000), tested.PhysicalMediaUnitsWritten[0].Uint())
+	a.Equal(uint64(0x2000), tested.PhysicalMediaUnitsRead[0].Uint())
+	a.Equal(uint64(0x10), tested.BadUserNANDBlocks.Raw())
+	a.Equal(uint16(99), tested.BadUserNANDBlocks.Normalized)
+	a.Equal(uint64(7), tested.SoftECCErrors)
+	a.Equal(uint32(3), tested.E2EDetectedErrors)
+	a.Equal(uint32(2), tested.E2ECorrectedErrors)
+	a.Equal(uint64(0x0101), tested.Refreshes())
+	a.Equal(uint8(5), tested.ThermalThrottlingCount)
+	a.Equal(ThrottlingSecondLevel, tested.Throttling())
+	a.Equal(uint8(2), tested.DSSDSpecVersion.Major)
+	a.Equal(uint64(11), tested.PCIeCorrectableErrors)
+	a.Equal(uint16(95), tested.CapacitorHealth)
+	a.Equal(uint16(3), tested.LPV)
+
+	// wrong size
+	_, err = ParseSMARTExtended(raw[:ocpLogSz-1])
+	a.Error(err)
+
+	// unsupported version
+	for _, lpv := range []uint16{0, smartExtendedMaxLPV + 1} {
+		utils.SystemEndian.PutUint16(raw[494:], lpv)
+		_, err = ParseSMARTExtended(raw)
+		a.ErrorIs(err, ErrUnsupportedVersion, "LPV %d", lpv)
+	}
+
+	// wrong GUID
+	copy(raw[496:], errorRecoveryGUID[:])
+	_, err = ParseSMARTExtended(raw)
+	a.Error(err)
+}
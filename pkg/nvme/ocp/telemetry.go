@@ -0,0 +1,307 @@
+package ocp
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"github.com/sungup/go-nvmecli/pkg/nvme/getlog"
+	"github.com/sungup/go-nvmecli/pkg/utils"
+	"unsafe"
+)
+
+// -------------------------------- //
+// OCP Telemetry Data Area Decoding //
+// -------------------------------- //
+
+const (
+	telemetryHeaderSz = 512
+
+	statDescHeaderSz  = 8
+	eventDescHeaderSz = 4
+
+	// EventClassTimestamp is the debug event class of the timestamp event. The timestamp is applied
+	// to the following events in the same FIFO.
+	EventClassTimestamp = uint8(0x01)
+
+	timestampMask = uint64(1<<48 - 1)
+)
+
+// ReasonIdentifier is the OCP defined format of the Reason Identifier in the telemetry header.
+type ReasonIdentifier struct {
+	ErrorID     [64]byte // [63:00]
+	FileID      [8]byte  // [71:64]
+	LineNumber  uint16   // [73:72]
+	ValidFlags  uint8    // [74]
+	_           [21]byte // [95:75] reserved
+	VUExtension [32]byte // [127:96]
+}
+
+// LineNumberValid returns true if the LineNumber is valid.
+func (r *ReasonIdentifier) LineNumberValid() bool {
+	return r.ValidFlags&(1<<0) != 0
+}
+
+// FileIDValid returns true if the FileID is valid.
+func (r *ReasonIdentifier) FileIDValid() bool {
+	return r.ValidFlags&(1<<1) != 0
+}
+
+// ErrorIDValid returns true if the ErrorID is valid.
+func (r *ReasonIdentifier) ErrorIDValid() bool {
+	return r.ValidFlags&(1<<2) != 0
+}
+
+// VUExtensionValid returns true if the VUExtension is valid.
+func (r *ReasonIdentifier) VUExtensionValid() bool {
+	return r.ValidFlags&(1<<3) != 0
+}
+
+// ParseReasonIdentifier parses the OCP Reason Identifier of the telemetry header.
+func ParseReasonIdentifier(header *getlog.Telemetry) (*ReasonIdentifier, error) {
+	r := ReasonIdentifier{}
+	if err := binary.Read(bytes.NewReader(header.ReasonIdentifier[:]), utils.SystemEndian, &r); err != nil {
+		return nil, err
+	}
+
+	return &r, nil
+}
+
+// fifoRange is the location of an event FIFO in dword unit.
+type fifoRange struct {
+	Start uint64
+	Size  uint64
+}
+
+// TelemetryDataHeader is the OCP header at the beginning of the telemetry Data Area 1. The start
+// and size fields are in dword unit from the beginning of the data area.
+type TelemetryDataHeader struct {
+	MajorVersion     uint16                    // [01:00]
+	MinorVersion     uint16                    // [03:02]
+	_                uint32                    // [07:04] reserved
+	Timestamp        uint64                    // [15:08]
+	LPG              guid                      // [31:16] Log Page GUID
+	ProfilesSupport  uint8                     // [32] Number of Telemetry Profiles Supported
+	ProfileSelected  uint8                     // [33] Telemetry Profile Selected
+	_                [6]byte                   // [39:34] reserved
+	StringLogSize    uint64                    // [47:40] in dwords
+	_                uint64                    // [55:48] reserved
+	FirmwareRevision [8]byte                   // [63:56]
+	_                [32]byte                  // [95:64] reserved
+	DA1StatStart     uint64                    // [103:96]
+	DA1StatSize      uint64                    // [111:104]
+	DA2StatStart     uint64                    // [119:112]
+	DA2StatSize      uint64                    // [127:120]
+	_                [32]byte                  // [159:128] reserved
+	FIFODataArea     [telemetryFIFOs]uint8     // [175:160] the data area of each event FIFO
+	FIFO             [telemetryFIFOs]fifoRange // [431:176]
+	_                [80]byte                  // [511:432] reserved
+}
+
+// Statistic is a decoded statistic descriptor of the telemetry data area.
+type Statistic struct {
+	Area     int
+	ID       uint16
+	Name     string
+	Behavior uint8
+	NSID     uint8
+	NSValid  bool
+	Data     []byte
+}
+
+// Value returns the statistic data as a little endian unsigned integer. If the data is larger than
+// 8 bytes, only the lower 8 bytes are used.
+func (s *Statistic) Value() uint64 {
+	buffer := make([]byte, 8)
+	copy(buffer, s.Data)
+
+	return utils.SystemEndian.Uint64(buffer)
+}
+
+// Event is a decoded event descriptor of the telemetry event FIFO. Timestamp is the last timestamp
+// event's timestamp in the FIFO before this event, and 0 if there is no timestamp event before.
+type Event struct {
+	FIFO      int
+	FIFOName  string
+	Class     uint8
+	ID        uint16
+	Name      string
+	Timestamp uint64
+	Data      []byte
+}
+
+// TelemetryReport is the decoded OCP telemetry data.
+type TelemetryReport struct {
+	Header     TelemetryDataHeader
+	Reason     ReasonIdentifier
+	Statistics []Statistic
+	Events     []Event
+}
+
+// areaSection returns the byte range from the dword start and size in the data area.
+func areaSection(raw []byte, areas map[int]getlog.TelemetryArea, area int, start, size uint64) ([]byte, error) {
+	a, ok := areas[area]
+	if !ok {
+		return nil, fmt.Errorf("telemetry data area %d is not captured", area)
+	}
+
+	begin, end := start*4, (start+size)*4
+	if begin > end || end > uint64(a.Size) || a.Offset+int64(end) > int64(len(raw)) {
+		return nil, fmt.Errorf("range [%d, %d) is over the telemetry data area %d", begin, end, area)
+	}
+
+	return raw[a.Offset+int64(begin) : a.Offset+int64(end)], nil
+}
+
+// decodeStatistics decodes the statistic descriptors. The descriptors with statistic identifier
+// 0h are unused entries.
+func decodeStatistics(data []byte, area int, strs *TelemetryStrings) ([]Statistic, error) {
+	stats := make([]Statistic, 0)
+
+	for offset := 0; offset+statDescHeaderSz <= len(data); {
+		id := utils.SystemEndian.Uint16(data[offset:])
+		size := int(utils.SystemEndian.Uint16(data[offset+4:])) * 4
+
+		begin := offset + statDescHeaderSz
+		if begin+size > len(data) {
+			return nil, fmt.Errorf("statistic %04Xh data is over the statistic area", id)
+		}
+
+		if id != 0 {
+			s := Statistic{
+				Area:     area,
+				ID:       id,
+				Behavior: data[offset+2] & 0x0F,
+				NSID:     data[offset+3] & 0x7F,
+				NSValid:  data[offset+3]&0x80 != 0,
+				Data:     data[begin : begin+size],
+			}
+
+			if strs != nil {
+				s.Name = strs.Statistics[id]
+			}
+
+			stats = append(stats, s)
+		}
+
+		offset = begin + size
+	}
+
+	return stats, nil
+}
+
+// decodeEvents decodes the event descriptors in the event FIFO and applies the timestamp of the
+// timestamp events to the following events. The descriptors with debug event class 0h are unused
+// entries.
+func decodeEvents(data []byte, fifo int, strs *TelemetryStrings) ([]Event, error) {
+	events := make([]Event, 0)
+	timestamp := uint64(0)
+
+	for offset := 0; offset+eventDescHeaderSz <= len(data); {
+		e := Event{
+			FIFO:  fifo,
+			Class: data[offset],
+			ID:    utils.SystemEndian.Uint16(data[offset+1:]),
+		}
+
+		size := int(data[offset+3]) * 4
+		begin := offset + eventDescHeaderSz
+		if begin+size > len(data) {
+			return nil, fmt.Errorf("event %02Xh/%04Xh data is over the event FIFO %d", e.Class, e.ID, fifo)
+		}
+
+		e.Data = data[begin : begin+size]
+
+		if e.Class == EventClassTimestamp && len(e.Data) >= 8 {
+			timestamp = utils.SystemEndian.Uint64(e.Data) & timestampMask
+		}
+
+		e.Timestamp = timestamp
+
+		if strs != nil {
+			e.FIFOName = strs.FIFONames[fifo-1]
+			e.Name = strs.EventName(e.Class, e.ID)
+		}
+
+		if e.Class != 0 {
+			events = append(events, e)
+		}
+
+		offset = begin + size
+	}
+
+	return events, nil
+}
+
+// DecodeTelemetry decodes the OCP telemetry log captured by getlog.GetTelemetryHostInit or
+// getlog.GetTelemetryCtrlInit into the named statistics and the timestamped events. The names are
+// looked up in the Telemetry String log page, and strs can be nil to decode without names. The
+// statistics and events in the data area which is not captured raise an error.
+func DecodeTelemetry(raw []byte, strs *TelemetryStrings) (*TelemetryReport, error) {
+	const dataHeaderSz = int(unsafe.Sizeof(TelemetryDataHeader{}))
+
+	header, err := getlog.ParseTelemetryHeader(raw)
+	if err != nil {
+		return nil, err
+	} else if len(raw) < telemetryHeaderSz+dataHeaderSz {
+		return nil, fmt.Errorf("telemetry data area 1 header is not captured: %d", len(raw))
+	}
+
+	report := TelemetryReport{}
+
+	if err = binary.Read(bytes.NewReader(header.ReasonIdentifier[:]), utils.SystemEndian, &report.Reason); err != nil {
+		return nil, err
+	}
+
+	if err = binary.Read(bytes.NewReader(raw[telemetryHeaderSz:]), utils.SystemEndian, &report.Header); err != nil {
+		return nil, err
+	}
+
+	areas := make(map[int]getlog.TelemetryArea)
+	for _, area := range header.Manifest(getlog.DataBlock4).Areas {
+		areas[area.Area] = area
+	}
+
+	// 1. statistics in the data area 1 and 2
+	h := &report.Header
+	report.Statistics = make([]Statistic, 0)
+
+	for i, location := range [][2]uint64{{h.DA1StatStart, h.DA1StatSize}, {h.DA2StatStart, h.DA2StatSize}} {
+		area := i + 1
+		if location[1] == 0 {
+			continue
+		}
+
+		data, err := areaSection(raw, areas, area, location[0], location[1])
+		if err != nil {
+			return nil, err
+		}
+
+		if stats, err := decodeStatistics(data, area, strs); err != nil {
+			return nil, err
+		} else {
+			report.Statistics = append(report.Statistics, stats...)
+		}
+	}
+
+	// 2. event FIFOs
+	report.Events = make([]Event, 0)
+
+	for i, fifo := range h.FIFO {
+		if h.FIFODataArea[i] == 0 || fifo.Size == 0 {
+			continue
+		}
+
+		data, err := areaSection(raw, areas, int(h.FIFODataArea[i]), fifo.Start, fifo.Size)
+		if err != nil {
+			return nil, err
+		}
+
+		if events, err := decodeEvents(data, i+1, strs); err != nil {
+			return nil, err
+		} else {
+			report.Events = append(report.Events, events...)
+		}
+	}
+
+	return &report, nil
+}
@@ -0,0 +1,151 @@
+package ocp
+
+import (
+	"encoding/binary"
+	"github.com/stretchr/testify/assert"
+	"github.com/sungup/go-nvmecli/pkg/nvme/getlog"
+	"github.com/sungup/go-nvmecli/pkg/utils"
+	"testing"
+	"unsafe"
+)
+
+// buildTelemetry builds a host-initiated telemetry log which has 2 statistics in the data area 1
+// and an event FIFO in the data area 2.
+func buildTelemetry() []byte {
+	const (
+		da1 = 512
+		da2 = da1 + 2*512
+	)
+
+	raw := make([]byte, da2+512)
+
+	// telemetry header: data area 1 and 2 last blocks
+	raw[0] = 0x07
+	utils.SystemEndian.PutUint16(raw[8:], 2)
+	utils.SystemEndian.PutUint16(raw[10:], 3)
+	utils.SystemEndian.PutUint16(raw[12:], 3)
+	copy(raw[384:], "ERROR ID")
+	copy(raw[384+64:], "FILE")
+	utils.SystemEndian.PutUint16(raw[384+72:], 42)
+	raw[384+74] = 0x07
+
+	// OCP data header: statistics at dword 128 of data area 1, FIFO 1 at dword 0 of data area 2
+	header := raw[da1:]
+	utils.SystemEndian.PutUint16(header, 2)
+	utils.SystemEndian.PutUint64(header[96:], 128)
+	utils.SystemEndian.PutUint64(header[104:], 8)
+	header[160] = 2
+	utils.SystemEndian.PutUint64(header[176:], 0)
+	utils.SystemEndian.PutUint64(header[184:], 10)
+
+	// statistics: 0x1234 with 1 dword and 0x0002 with 2 dwords of namespace 1
+	stats := raw[da1+128*4:]
+	utils.SystemEndian.PutUint16(stats[0:], 0x1234)
+	stats[2] = 0x01
+	utils.SystemEndian.PutUint16(stats[4:], 1)
+	utils.SystemEndian.PutUint32(stats[8:], 0xCAFE)
+	utils.SystemEndian.PutUint16(stats[12:], 0x0002)
+	stats[15] = 0x81
+	utils.SystemEndian.PutUint16(stats[16:], 2)
+	utils.SystemEndian.PutUint64(stats[20:], 0x1122334455)
+
+	// events: 0x10/0x0101 without timestamp, timestamp, 0x80/0x0202
+	fifo := raw[da2:]
+	fifo[0] = 0x10
+	utils.SystemEndian.PutUint16(fifo[1:], 0x0101)
+	fifo[4] = EventClassTimestamp
+	fifo[7] = 2
+	utils.SystemEndian.PutUint64(fifo[8:], 0xFFFF000000001000)
+	fifo[16] = 0x80
+	utils.SystemEndian.PutUint16(fifo[17:], 0x0202)
+	fifo[19] = 1
+	fifo[20] = 0xAA
+
+	return raw
+}
+
+func TestTelemetryDataHeaderSize(t *testing.T) {
+	a := assert.New(t)
+
+	a.Equal(uintptr(512), unsafe.Sizeof(TelemetryDataHeader{}))
+	a.Equal(512, binary.Size(TelemetryDataHeader{}))
+	a.Equal(128, binary.Size(ReasonIdentifier{}))
+}
+
+func TestStatistic_Value(t *testing.T) {
+	a := assert.New(t)
+
+	a.Equal(uint64(0x0201), (&Statistic{Data: []byte{0x01, 0x02}}).Value())
+	a.Equal(uint64(0x0807060504030201), (&Statistic{Data: []byte{1, 2, 3, 4, 5, 6, 7, 8, 9}}).Value())
+	a.Equal(uint64(0), (&Statistic{}).Value())
+}
+
+func TestDecodeTelemetry(t *testing.T) {
+	a := assert.New(t)
+
+	raw := buildTelemetry()
+	strs, err := ParseTelemetryStrings(buildTelemetryStrings())
+	a.NoError(err)
+
+	tested, err := DecodeTelemetry(raw, strs)
+	a.NoError(err)
+	a.NotNil(tested)
+
+	a.Equal(uint16(2), tested.Header.MajorVersion)
+	a.Equal("ERROR ID", asciiString(tested.Reason.ErrorID[:]))
+	a.Equal(uint16(42), tested.Reason.LineNumber)
+	a.True(tested.Reason.LineNumberValid())
+	a.True(tested.Reason.FileIDValid())
+	a.True(tested.Reason.ErrorIDValid())
+	a.False(tested.Reason.VUExtensionValid())
+
+	// statistics
+	a.Len(tested.Statistics, 2)
+	a.Equal(uint16(0x1234), tested.Statistics[0].ID)
+	a.Equal("STAT", tested.Statistics[0].Name)
+	a.Equal(uint8(1), tested.Statistics[0].Behavior)
+	a.False(tested.Statistics[0].NSValid)
+	a.Equal(uint64(0xCAFE), tested.Statistics[0].Value())
+	a.Equal(uint16(0x0002), tested.Statistics[1].ID)
+	a.Equal("", tested.Statistics[1].Name)
+	a.True(tested.Statistics[1].NSValid)
+	a.Equal(uint8(expectedNSId), tested.Statistics[1].NSID)
+	a.Equal(uint64(0x1122334455), tested.Statistics[1].Value())
+
+	// events
+	a.Len(tested.Events, 3)
+	a.Equal("EVNT", tested.Events[0].Name)
+	a.Equal("FIFO1", tested.Events[0].FIFOName)
+	a.Equal(uint64(0), tested.Events[0].Timestamp)
+	a.Equal(EventClassTimestamp, tested.Events[1].Class)
+	a.Equal(uint64(0x1000), tested.Events[1].Timestamp)
+	a.Equal("VUEVT", tested.Events[2].Name)
+	a.Equal(uint64(0x1000), tested.Events[2].Timestamp)
+	a.Equal([]byte{0xAA, 0, 0, 0}, tested.Events[2].Data)
+
+	// without strings
+	tested, err = DecodeTelemetry(raw, nil)
+	a.NoError(err)
+	a.Equal("", tested.Statistics[0].Name)
+	a.Equal("", tested.Events[0].FIFOName)
+
+	// data area 2 is not captured
+	_, err = DecodeTelemetry(raw[:512+2*512], nil)
+	a.Error(err)
+
+	// too short
+	_, err = DecodeTelemetry(raw[:600], nil)
+	a.Error(err)
+}
+
+func TestParseReasonIdentifier(t *testing.T) {
+	a := assert.New(t)
+
+	header, err := getlog.ParseTelemetryHeader(buildTelemetry())
+	a.NoError(err)
+
+	tested, err := ParseReasonIdentifier(header)
+	a.NoError(err)
+	a.Equal("FILE", asciiString(tested.FileID[:]))
+	a.Equal(uint16(42), tested.LineNumber)
+}
@@ -0,0 +1,30 @@
+// +build with_phys_device
+
+package ocp
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/sungup/go-nvmecli/pkg/nvme/getlog"
+	"os"
+	"testing"
+)
+
+func TestDecodeTelemetry_Device(t *testing.T) {
+	a := assert.New(t)
+
+	dev, _ := os.Open(targetDevice)
+	defer func() { _ = dev.Close() }()
+
+	// the device which doesn't follow the OCP specification can't return the telemetry strings
+	strs, err := GetTelemetryStrings(dev)
+	if err != nil {
+		return
+	}
+
+	raw, err := getlog.GetTelemetryHostInit(dev, getlog.DataBlock2, true)
+	a.NoError(err)
+
+	tested, err := DecodeTelemetry(raw, strs)
+	a.NoError(err)
+	a.NotNil(tested)
+}
@@ -0,0 +1,215 @@
+package ocp
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"github.com/sungup/go-nvmecli/pkg/nvme/getlog"
+	"github.com/sungup/go-nvmecli/pkg/utils"
+	"os"
+)
+
+// ----------------------------- //
+// LID C9h: Telemetry String Log //
+// ----------------------------- //
+
+const (
+	telemetryStringHeaderSz = 432
+	telemetryFIFOs          = 16
+)
+
+// telemetryStringGUID is the Log Page GUID of the Telemetry String log page.
+var telemetryStringGUID = newGUID("B13A83691A8F408B9EA495940057AA44")
+
+// TelemetryStringHeader is the header of the Telemetry String log page. The table start and size
+// fields are in dword unit from the beginning of the log page.
+type TelemetryStringHeader struct {
+	LPV     uint8                    // [00] Log Page Version
+	_       [15]byte                 // [15:01] reserved
+	LPG     guid                     // [31:16] Log Page GUID
+	SLS     uint64                   // [39:32] String Log Size
+	_       [24]byte                 // [63:40] reserved
+	SITS    uint64                   // [71:64] Statistics Identifier String Table Start
+	SITSZ   uint64                   // [79:72] Statistics Identifier String Table Size
+	ESTS    uint64                   // [87:80] Event String Table Start
+	ESTSZ   uint64                   // [95:88] Event String Table Size
+	VUESTS  uint64                   // [103:96] VU Event String Table Start
+	VUESTSZ uint64                   // [111:104] VU Event String Table Size
+	ASCTS   uint64                   // [119:112] ASCII Table Start
+	ASCTSZ  uint64                   // [127:120] ASCII Table Size
+	FIFO    [telemetryFIFOs][16]byte // [383:128] FIFO 1~16 ASCII String
+	_       [48]byte                 // [431:384] reserved
+}
+
+// statStringEntry is an entry of the Statistics Identifier String Table.
+type statStringEntry struct {
+	ID     uint16 // Vendor Specific Statistic Identifier
+	_      uint8
+	Length uint8  // ASCII ID Length, 0's based
+	Offset uint64 // ASCII ID Offset in dwords from the ASCII table start
+	_      uint32
+}
+
+// eventStringEntry is an entry of the Event String Table and the VU Event String Table.
+type eventStringEntry struct {
+	Class  uint8  // Debug Event Class
+	ID     uint16 // Event Identifier
+	Length uint8  // ASCII ID Length, 0's based
+	Offset uint64 // ASCII ID Offset in dwords from the ASCII table start
+	_      uint32
+}
+
+// EventKey identifies an event string by the debug event class and the event identifier.
+type EventKey struct {
+	Class uint8
+	ID    uint16
+}
+
+// TelemetryStrings is the parsed Telemetry String log page which gives the names of the statistic
+// identifiers and the events in the OCP telemetry data areas.
+type TelemetryStrings struct {
+	Header     TelemetryStringHeader
+	Statistics map[uint16]string
+	Events     map[EventKey]string
+	VUEvents   map[EventKey]string
+	FIFONames  [telemetryFIFOs]string
+}
+
+// EventName returns the name of the event. The VU event string table is looked up if the event is
+// not in the event string table. If there is no string, EventName returns an empty string.
+func (s *TelemetryStrings) EventName(class uint8, id uint16) string {
+	key := EventKey{Class: class, ID: id}
+
+	if name, ok := s.Events[key]; ok {
+		return name
+	}
+
+	return s.VUEvents[key]
+}
+
+// tableSection returns the byte range of the table from the dword start and size.
+func tableSection(raw []byte, start, size uint64, name string) ([]byte, error) {
+	begin, end := start*4, (start+size)*4
+
+	if begin > end || end > uint64(len(raw)) {
+		return nil, fmt.Errorf("%s table [%d, %d) is over the string log size %d", name, begin, end, len(raw))
+	}
+
+	return raw[begin:end], nil
+}
+
+// asciiID returns the ASCII string of the entry from the ASCII table.
+func asciiID(ascii []byte, offset uint64, length uint8) (string, error) {
+	begin := offset * 4
+	end := begin + uint64(length) + 1
+
+	if end > uint64(len(ascii)) {
+		return "", fmt.Errorf("ASCII ID [%d, %d) is over the ASCII table size %d", begin, end, len(ascii))
+	}
+
+	return asciiString(ascii[begin:end]), nil
+}
+
+// readEventStrings reads the event string table entries into the map.
+func readEventStrings(table, ascii []byte) (map[EventKey]string, error) {
+	entries := make([]eventStringEntry, len(table)/binary.Size(eventStringEntry{}))
+	if err := binary.Read(bytes.NewReader(table), utils.SystemEndian, entries); err != nil {
+		return nil, err
+	}
+
+	events := make(map[EventKey]string, len(entries))
+	for _, entry := range entries {
+		if name, err := asciiID(ascii, entry.Offset, entry.Length); err != nil {
+			return nil, err
+		} else {
+			events[EventKey{Class: entry.Class, ID: entry.ID}] = name
+		}
+	}
+
+	return events, nil
+}
+
+// ParseTelemetryStrings parses the Telemetry String log page from raw data. If the raw data is
+// shorter than the string log size or the tables, this function raises an error.
+func ParseTelemetryStrings(raw []byte) (*TelemetryStrings, error) {
+	if len(raw) < telemetryStringHeaderSz {
+		return nil, fmt.Errorf("unexpected telemetry string log size: %d", len(raw))
+	}
+
+	s := TelemetryStrings{}
+	if err := binary.Read(bytes.NewReader(raw), utils.SystemEndian, &s.Header); err != nil {
+		return nil, err
+	}
+
+	if err := checkGUID(LIDTelemetryString, telemetryStringGUID, s.Header.LPG); err != nil {
+		return nil, err
+	}
+
+	h := &s.Header
+
+	ascii, err := tableSection(raw, h.ASCTS, h.ASCTSZ, "ASCII")
+	if err != nil {
+		return nil, err
+	}
+
+	// 1. statistics identifier string table
+	table, err := tableSection(raw, h.SITS, h.SITSZ, "statistics identifier")
+	if err != nil {
+		return nil, err
+	}
+
+	stats := make([]statStringEntry, len(table)/binary.Size(statStringEntry{}))
+	if err = binary.Read(bytes.NewReader(table), utils.SystemEndian, stats); err != nil {
+		return nil, err
+	}
+
+	s.Statistics = make(map[uint16]string, len(stats))
+	for _, entry := range stats {
+		if name, err := asciiID(ascii, entry.Offset, entry.Length); err != nil {
+			return nil, err
+		} else {
+			s.Statistics[entry.ID] = name
+		}
+	}
+
+	// 2. event and VU event string tables
+	if table, err = tableSection(raw, h.ESTS, h.ESTSZ, "event"); err != nil {
+		return nil, err
+	} else if s.Events, err = readEventStrings(table, ascii); err != nil {
+		return nil, err
+	}
+
+	if table, err = tableSection(raw, h.VUESTS, h.VUESTSZ, "VU event"); err != nil {
+		return nil, err
+	} else if s.VUEvents, err = readEventStrings(table, ascii); err != nil {
+		return nil, err
+	}
+
+	// 3. FIFO names
+	for i, name := range h.FIFO {
+		s.FIFONames[i] = asciiString(name[:])
+	}
+
+	return &s, nil
+}
+
+// GetTelemetryStrings retrieves the Telemetry String log page (C9h) through GetVendorCMD. The header
+// is read first to get the string log size, and then the whole log page is read.
+func GetTelemetryStrings(file *os.File) (*TelemetryStrings, error) {
+	header := make([]byte, telemetryStringHeaderSz)
+	if err := getlog.GetVendorCMD(file, 0, LIDTelemetryString, 0, 0, header); err != nil {
+		return nil, err
+	}
+
+	size := utils.SystemEndian.Uint64(header[32:]) * 4
+	if size < telemetryStringHeaderSz || size > 1<<32 {
+		return nil, fmt.Errorf("unexpected telemetry string log size: %d", size)
+	}
+
+	raw := make([]byte, size)
+	if err := getlog.GetVendorCMD(file, 0, LIDTelemetryString, 0, 0, raw); err != nil {
+		return nil, err
+	}
+
+	return ParseTelemetryStrings(raw)
+}
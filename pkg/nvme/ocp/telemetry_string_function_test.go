@@ -0,0 +1,101 @@
+package ocp
+
+import (
+	"encoding/binary"
+	"github.com/stretchr/testify/assert"
+	"github.com/sungup/go-nvmecli/pkg/utils"
+	"testing"
+)
+
+// buildTelemetryStrings builds a Telemetry String log page which has a statistic string, an event
+// string and a VU event string.
+func buildTelemetryStrings() []byte {
+	// header 432B(108 dwords), tables 16B(4 dwords) each, ASCII table 16B(4 dwords)
+	const (
+		sits   = 108
+		ests   = sits + 4
+		vuests = ests + 4
+		ascts  = vuests + 4
+		sls    = ascts + 4
+	)
+
+	raw := make([]byte, sls*4)
+
+	copy(raw[16:], telemetryStringGUID[:])
+	utils.SystemEndian.PutUint64(raw[32:], sls)
+	for i, v := range []uint64{sits, 4, ests, 4, vuests, 4, ascts, 4} {
+		utils.SystemEndian.PutUint64(raw[64+i*8:], v)
+	}
+	copy(raw[128:], "FIFO1")
+	copy(raw[128+16:], "FIFO2")
+
+	// statistic 0x1234: "STAT" at dword 0
+	table := raw[sits*4:]
+	utils.SystemEndian.PutUint16(table, 0x1234)
+	table[3] = 3
+
+	// event 0x10/0x0101: "EVNT" at dword 1
+	table = raw[ests*4:]
+	table[0] = 0x10
+	utils.SystemEndian.PutUint16(table[1:], 0x0101)
+	table[3] = 3
+	utils.SystemEndian.PutUint64(table[4:], 1)
+
+	// VU event 0x80/0x0202: "VUEVT" at dword 2
+	table = raw[vuests*4:]
+	table[0] = 0x80
+	utils.SystemEndian.PutUint16(table[1:], 0x0202)
+	table[3] = 4
+	utils.SystemEndian.PutUint64(table[4:], 2)
+
+	copy(raw[ascts*4:], "STATEVNTVUEVT")
+
+	return raw
+}
+
+func TestTelemetryStringHeaderSize(t *testing.T) {
+	a := assert.New(t)
+
+	a.Equal(telemetryStringHeaderSz, binary.Size(TelemetryStringHeader{}))
+	a.Equal(16, binary.Size(statStringEntry{}))
+	a.Equal(16, binary.Size(eventStringEntry{}))
+}
+
+func TestParseTelemetryStrings(t *testing.T) {
+	a := assert.New(t)
+
+	raw := buildTelemetryStrings()
+
+	tested, err := ParseTelemetryStrings(raw)
+	a.NoError(err)
+	a.NotNil(tested)
+
+	a.Equal(uint64(len(raw)/4), tested.Header.SLS)
+	a.Equal("STAT", tested.Statistics[0x1234])
+	a.Equal("EVNT", tested.EventName(0x10, 0x0101))
+	a.Equal("VUEVT", tested.EventName(0x80, 0x0202))
+	a.Equal("", tested.EventName(0x10, 0x0202))
+	a.Equal("FIFO1", tested.FIFONames[0])
+	a.Equal("FIFO2", tested.FIFONames[1])
+	a.Equal("", tested.FIFONames[2])
+
+	// short buffer
+	_, err = ParseTelemetryStrings(raw[:telemetryStringHeaderSz-1])
+	a.Error(err)
+
+	// table over the log size
+	_, err = ParseTelemetryStrings(raw[:len(raw)-4])
+	a.Error(err)
+
+	// wrong GUID
+	broken := append([]byte{}, raw...)
+	broken[16] ^= 0xFF
+	_, err = ParseTelemetryStrings(broken)
+	a.Error(err)
+
+	// ASCII ID over the ASCII table
+	broken = append([]byte{}, raw...)
+	broken[108*4+3] = 0xFF
+	_, err = ParseTelemetryStrings(broken)
+	a.Error(err)
+}
@@ -0,0 +1,22 @@
+// +build with_phys_device
+
+package ocp
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"testing"
+)
+
+func TestGetTelemetryStrings(t *testing.T) {
+	a := assert.New(t)
+
+	dev, _ := os.Open(targetDevice)
+	defer func() { _ = dev.Close() }()
+
+	// the device which doesn't follow the OCP specification can't return the telemetry strings
+	if tested, err := GetTelemetryStrings(dev); err == nil {
+		a.NotNil(tested)
+		a.Equal(telemetryStringGUID, tested.Header.LPG)
+	}
+}
@@ -0,0 +1,5 @@
+package registry
+
+const (
+	expectedNSId = 1
+)
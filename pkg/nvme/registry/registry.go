@@ -0,0 +1,260 @@
+package registry
+
+import (
+	"errors"
+	"fmt"
+	"github.com/sungup/go-nvmecli/pkg/nvme/feature"
+	"github.com/sungup/go-nvmecli/pkg/nvme/getlog"
+	"github.com/sungup/go-nvmecli/pkg/nvme/identify"
+	"github.com/sungup/go-nvmecli/pkg/nvme/spec"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	// AnyVendor is the wildcard PCI Vendor ID to register the decoder for the log pages or the
+	// features defined by a specification over the vendors like the OCP Datacenter NVMe SSD.
+	AnyVendor = uint16(0x0000)
+
+	// rawLogSz is the size of the vendor specific log page read without the decoder.
+	rawLogSz = 512
+)
+
+// ErrMismatch is returned by a decoder if the data is not the format of the decoder, like a log
+// page GUID mismatch. The next matching decoder is tried, and the raw data is returned at last.
+var ErrMismatch = errors.New("data doesn't match the decoder")
+
+// Key identifies the vendor specific log page or feature of the controllers. The decoder is
+// selected by the PCI Vendor ID and the Model Number prefix of the Identify Controller data
+// structure, and the log page is read with the UUID Index.
+type Key struct {
+	VID       uint16 // PCI Vendor ID, or AnyVendor
+	MNPrefix  string // Model Number prefix, empty string matches all models
+	ID        uint8  // Log Page Identifier or Feature Identifier
+	UUIDIndex uint8  // UUID Index of the log page, 0 if UUID is not used
+}
+
+// matches returns true if the key matches the controller.
+func (k Key) matches(vid uint16, mn string, id, uuidIndex uint8) bool {
+	return (k.VID == vid || k.VID == AnyVendor) && strings.HasPrefix(mn, k.MNPrefix) &&
+		k.ID == id && k.UUIDIndex == uuidIndex
+}
+
+// moreSpecific returns true if the key is more specific than the other key. The key with the
+// exact vendor ID is prior to AnyVendor, and then the longer model number prefix is prior.
+func (k Key) moreSpecific(other Key) bool {
+	if (k.VID == AnyVendor) != (other.VID == AnyVendor) {
+		return k.VID != AnyVendor
+	}
+
+	return len(k.MNPrefix) > len(other.MNPrefix)
+}
+
+// LogDecoder decodes the vendor specific log page. Size is the number of bytes to read, and Decode
+// should return a JSON marshallable value.
+type LogDecoder struct {
+	Size   int64
+	Decode func(raw []byte) (interface{}, error)
+}
+
+// FeatureDecoder decodes the vendor specific feature. Size is the size of the feature data
+// structure, and 0 if the feature has no data structure. Decode receives the dword 0 of the
+// completion queue entry and the data structure, and should return a JSON marshallable value.
+type FeatureDecoder struct {
+	Size   int
+	Decode func(result uint32, raw []byte) (interface{}, error)
+}
+
+type registry struct {
+	sync.RWMutex
+
+	logs     map[Key]LogDecoder
+	features map[Key]FeatureDecoder
+}
+
+var decoders = registry{
+	logs:     make(map[Key]LogDecoder),
+	features: make(map[Key]FeatureDecoder),
+}
+
+// RegisterLog registers the decoder of the vendor specific log page. RegisterLog panics if the key
+// is already registered or the decoder is invalid, so the vendor packages should call it in init.
+func RegisterLog(key Key, decoder LogDecoder) {
+	if decoder.Size <= 0 || decoder.Decode == nil {
+		panic(fmt.Sprintf("invalid log decoder of %+v", key))
+	}
+
+	decoders.Lock()
+	defer decoders.Unlock()
+
+	if _, ok := decoders.logs[key]; ok {
+		panic(fmt.Sprintf("log decoder of %+v is already registered", key))
+	}
+
+	decoders.logs[key] = decoder
+}
+
+// RegisterFeature registers the decoder of the vendor specific feature. RegisterFeature panics if
+// the key is already registered or the decoder is invalid. The UUID Index is not supported for the
+// features.
+func RegisterFeature(key Key, decoder FeatureDecoder) {
+	if decoder.Size < 0 || decoder.Decode == nil || key.UUIDIndex != 0 {
+		panic(fmt.Sprintf("invalid feature decoder of %+v", key))
+	}
+
+	decoders.Lock()
+	defer decoders.Unlock()
+
+	if _, ok := decoders.features[key]; ok {
+		panic(fmt.Sprintf("feature decoder of %+v is already registered", key))
+	}
+
+	decoders.features[key] = decoder
+}
+
+// matchedKeys returns the keys which match the controller ordered by the priority.
+func matchedKeys(keys []Key, vid uint16, mn string, id, uuidIndex uint8) []Key {
+	matched := make([]Key, 0)
+	for _, key := range keys {
+		if key.matches(vid, mn, id, uuidIndex) {
+			matched = append(matched, key)
+		}
+	}
+
+	sort.SliceStable(matched, func(i, j int) bool { return matched[i].moreSpecific(matched[j]) })
+
+	return matched
+}
+
+// LookupLog returns the log page decoders of the controller ordered by the priority.
+func LookupLog(vid uint16, mn string, lid spec.LogID, uuidIndex uint8) []LogDecoder {
+	decoders.RLock()
+	defer decoders.RUnlock()
+
+	keys := make([]Key, 0, len(decoders.logs))
+	for key := range decoders.logs {
+		keys = append(keys, key)
+	}
+
+	found := make([]LogDecoder, 0)
+	for _, key := range matchedKeys(keys, vid, mn, uint8(lid), uuidIndex) {
+		found = append(found, decoders.logs[key])
+	}
+
+	return found
+}
+
+// LookupFeature returns the feature decoders of the controller ordered by the priority.
+func LookupFeature(vid uint16, mn string, fid spec.FeatureID) []FeatureDecoder {
+	decoders.RLock()
+	defer decoders.RUnlock()
+
+	keys := make([]Key, 0, len(decoders.features))
+	for key := range decoders.features {
+		keys = append(keys, key)
+	}
+
+	found := make([]FeatureDecoder, 0)
+	for _, key := range matchedKeys(keys, vid, mn, uint8(fid), 0) {
+		found = append(found, decoders.features[key])
+	}
+
+	return found
+}
+
+// controller returns the PCI Vendor ID and the Model Number of the controller.
+func controller(file *os.File) (uint16, string, error) {
+	idCtrl := identify.CtrlIdentify{}
+	if err := identify.GetCtrlIdentify(file, &idCtrl); err != nil {
+		return 0, "", err
+	}
+
+	return uint16(idCtrl.VID), idCtrl.MN.String(), nil
+}
+
+// readLog reads the log page of the size with the UUID Index.
+func readLog(file *os.File, lid spec.LogID, uuidIndex uint8, size int64) ([]byte, error) {
+	reader, err := getlog.ReadLog(file, lid, size, &getlog.LogOptions{UUIDIndex: uuidIndex})
+	if err != nil {
+		return nil, err
+	}
+
+	raw := make([]byte, size)
+	if _, err = io.ReadFull(reader, raw); err != nil {
+		return nil, err
+	}
+
+	return raw, nil
+}
+
+// ReadVendorLog reads the vendor specific log page and decodes it with the registered decoder of
+// the controller. If there is no decoder or all decoders return ErrMismatch, the first 512 bytes
+// of the log page are returned as a byte slice.
+func ReadVendorLog(file *os.File, lid spec.LogID) (interface{}, error) {
+	return ReadVendorLogUUID(file, lid, 0)
+}
+
+// ReadVendorLogUUID is the ReadVendorLog of the log page which is identified with the UUID Index.
+func ReadVendorLogUUID(file *os.File, lid spec.LogID, uuidIndex uint8) (interface{}, error) {
+	vid, mn, err := controller(file)
+	if err != nil {
+		return nil, err
+	}
+
+	return DecodeVendorLog(vid, mn, lid, uuidIndex, func(size int64) ([]byte, error) {
+		return readLog(file, lid, uuidIndex, size)
+	})
+}
+
+// DecodeVendorLog decodes the vendor specific log page of the controller identified by the PCI
+// Vendor ID and the Model Number. The log page is read by read with the size of each decoder, so
+// the captured log page can be decoded without the device. If there is no decoder or all decoders
+// return ErrMismatch, the first 512 bytes of the log page are returned as a byte slice.
+func DecodeVendorLog(vid uint16, mn string, lid spec.LogID, uuidIndex uint8, read func(size int64) ([]byte, error)) (interface{}, error) {
+	for _, decoder := range LookupLog(vid, mn, lid, uuidIndex) {
+		raw, err := read(decoder.Size)
+		if err != nil {
+			return nil, err
+		}
+
+		if decoded, err := decoder.Decode(raw); !errors.Is(err, ErrMismatch) {
+			return decoded, err
+		}
+	}
+
+	return read(rawLogSz)
+}
+
+// ReadVendorFeature reads the current value of the vendor specific feature and decodes it with the
+// registered decoder of the controller. If there is no decoder or all decoders return ErrMismatch,
+// the dword 0 of the completion queue entry is returned as an uint32.
+func ReadVendorFeature(file *os.File, fid spec.FeatureID) (interface{}, error) {
+	vid, mn, err := controller(file)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, decoder := range LookupFeature(vid, mn, fid) {
+		var raw []byte
+		var v interface{}
+
+		if decoder.Size > 0 {
+			raw = make([]byte, decoder.Size)
+			v = raw
+		}
+
+		result, err := feature.GetFeature(file, 0, fid, 0, feature.SELCurrent, v)
+		if err != nil {
+			return nil, err
+		}
+
+		if decoded, err := decoder.Decode(result, raw); !errors.Is(err, ErrMismatch) {
+			return decoded, err
+		}
+	}
+
+	return feature.GetFeature(file, 0, fid, 0, feature.SELCurrent, nil)
+}
@@ -0,0 +1,135 @@
+package registry
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// decodeAs returns a log decoder which returns the name.
+func decodeAs(name string) LogDecoder {
+	return LogDecoder{Size: 512, Decode: func(raw []byte) (interface{}, error) { return name, nil }}
+}
+
+func TestKey_matches(t *testing.T) {
+	a := assert.New(t)
+
+	key := Key{VID: 0x8086, MNPrefix: "INTEL SSDPE", ID: 0xCA}
+	a.True(key.matches(0x8086, "INTEL SSDPE2KX010T8", 0xCA, 0))
+	a.False(key.matches(0x144D, "INTEL SSDPE2KX010T8", 0xCA, 0))
+	a.False(key.matches(0x8086, "INTEL SSDSC2KB", 0xCA, 0))
+	a.False(key.matches(0x8086, "INTEL SSDPE2KX010T8", 0xC0, 0))
+	a.False(key.matches(0x8086, "INTEL SSDPE2KX010T8", 0xCA, 1))
+
+	key = Key{VID: AnyVendor, ID: 0xC0}
+	a.True(key.matches(0x8086, "INTEL SSDPE2KX010T8", 0xC0, 0))
+	a.True(key.matches(0x144D, "", 0xC0, 0))
+}
+
+func TestKey_moreSpecific(t *testing.T) {
+	a := assert.New(t)
+
+	anyVendor := Key{VID: AnyVendor, MNPrefix: "LONG MODEL PREFIX"}
+	vendor := Key{VID: 0x8086}
+	model := Key{VID: 0x8086, MNPrefix: "INTEL"}
+
+	a.True(vendor.moreSpecific(anyVendor))
+	a.False(anyVendor.moreSpecific(vendor))
+	a.True(model.moreSpecific(vendor))
+	a.False(vendor.moreSpecific(model))
+	a.False(vendor.moreSpecific(vendor))
+}
+
+func TestRegisterLog(t *testing.T) {
+	a := assert.New(t)
+
+	const lid = 0xF0
+
+	RegisterLog(Key{VID: AnyVendor, ID: lid}, decodeAs("any"))
+	RegisterLog(Key{VID: 0x8086, ID: lid}, decodeAs("vendor"))
+	RegisterLog(Key{VID: 0x8086, MNPrefix: "INTEL SSDPE", ID: lid}, decodeAs("model"))
+	RegisterLog(Key{VID: 0x8086, ID: lid, UUIDIndex: 1}, decodeAs("uuid"))
+
+	names := func(decoders []LogDecoder) []string {
+		found := make([]string, len(decoders))
+		for i, decoder := range decoders {
+			v, _ := decoder.Decode(nil)
+			found[i] = fmt.Sprint(v)
+		}
+		return found
+	}
+
+	a.Equal([]string{"model", "vendor", "any"}, names(LookupLog(0x8086, "INTEL SSDPE2KX010T8", lid, 0)))
+	a.Equal([]string{"vendor", "any"}, names(LookupLog(0x8086, "INTEL SSDSC2KB", lid, 0)))
+	a.Equal([]string{"any"}, names(LookupLog(0x144D, "SAMSUNG", lid, 0)))
+	a.Equal([]string{"uuid"}, names(LookupLog(0x8086, "INTEL SSDPE2KX010T8", lid, 1)))
+	a.Empty(LookupLog(0x8086, "INTEL SSDPE2KX010T8", lid+1, 0))
+
+	// duplicated or invalid decoders
+	a.Panics(func() { RegisterLog(Key{VID: 0x8086, ID: lid}, decodeAs("duplicated")) })
+	a.Panics(func() { RegisterLog(Key{VID: 0x8086, ID: lid + 1}, LogDecoder{Size: 512}) })
+	a.Panics(func() { RegisterLog(Key{VID: 0x8086, ID: lid + 1}, LogDecoder{Decode: decodeAs("").Decode}) })
+}
+
+func TestDecodeVendorLog(t *testing.T) {
+	a := assert.New(t)
+
+	const lid = 0xF1
+
+	mismatch := LogDecoder{Size: 16, Decode: func(raw []byte) (interface{}, error) { return nil, ErrMismatch }}
+	failed := LogDecoder{Size: 16, Decode: func(raw []byte) (interface{}, error) { return nil, fmt.Errorf("failed") }}
+
+	RegisterLog(Key{VID: 0x8086, MNPrefix: "INTEL", ID: lid}, mismatch)
+	RegisterLog(Key{VID: 0x8086, ID: lid}, decodeAs("vendor"))
+	RegisterLog(Key{VID: 0x144D, ID: lid}, failed)
+
+	sizes := make([]int64, 0)
+	read := func(size int64) ([]byte, error) {
+		sizes = append(sizes, size)
+		return make([]byte, size), nil
+	}
+
+	// the mismatched decoder lets the next decoder decode the log page
+	decoded, err := DecodeVendorLog(0x8086, "INTEL SSDPE", lid, 0, read)
+	a.NoError(err)
+	a.Equal("vendor", decoded)
+	a.Equal([]int64{16, 512}, sizes)
+
+	// the decoder error is returned
+	_, err = DecodeVendorLog(0x144D, "SAMSUNG", lid, 0, read)
+	a.EqualError(err, "failed")
+
+	// the raw log page is returned without the decoder
+	sizes = sizes[:0]
+	decoded, err = DecodeVendorLog(0x1234, "", lid, 0, read)
+	a.NoError(err)
+	a.Equal(make([]byte, rawLogSz), decoded)
+	a.Equal([]int64{rawLogSz}, sizes)
+}
+
+func TestRegisterFeature(t *testing.T) {
+	a := assert.New(t)
+
+	const fid = 0xF0
+
+	decoder := FeatureDecoder{Decode: func(result uint32, _ []byte) (interface{}, error) { return result, nil }}
+
+	RegisterFeature(Key{VID: 0x144D, ID: fid}, decoder)
+
+	found := LookupFeature(0x144D, "SAMSUNG", fid)
+	a.Len(found, 1)
+
+	v, err := found[0].Decode(0x10, nil)
+	a.NoError(err)
+	a.Equal(uint32(0x10), v)
+
+	a.Empty(LookupFeature(0x8086, "INTEL", fid))
+
+	// duplicated or invalid decoders
+	a.Panics(func() { RegisterFeature(Key{VID: 0x144D, ID: fid}, decoder) })
+	a.Panics(func() { RegisterFeature(Key{VID: 0x144D, ID: fid + 1}, FeatureDecoder{}) })
+	a.Panics(func() { RegisterFeature(Key{VID: 0x144D, ID: fid + 1, UUIDIndex: 1}, decoder) })
+	a.Panics(func() {
+		RegisterFeature(Key{VID: 0x144D, ID: fid + 1}, FeatureDecoder{Size: -1, Decode: decoder.Decode})
+	})
+}
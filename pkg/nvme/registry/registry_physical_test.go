@@ -0,0 +1,33 @@
+// +build with_phys_device
+
+package registry
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"testing"
+)
+
+func TestReadVendorLog(t *testing.T) {
+	a := assert.New(t)
+
+	dev, _ := os.Open(targetDevice)
+	defer func() { _ = dev.Close() }()
+
+	// without the registered decoder, the raw log page is returned if the device supports it
+	if tested, err := ReadVendorLog(dev, 0xC0); err == nil {
+		a.IsType([]byte{}, tested)
+		a.Len(tested, rawLogSz)
+	}
+}
+
+func TestReadVendorFeature(t *testing.T) {
+	a := assert.New(t)
+
+	dev, _ := os.Open(targetDevice)
+	defer func() { _ = dev.Close() }()
+
+	if tested, err := ReadVendorFeature(dev, 0xC0); err == nil {
+		a.IsType(uint32(0), tested)
+	}
+}
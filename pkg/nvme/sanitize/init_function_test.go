@@ -0,0 +1,5 @@
+package sanitize
+
+const (
+	expectedNSId = 1
+)
@@ -0,0 +1,7 @@
+// +build with_phys_device
+
+package sanitize
+
+const (
+	targetDevice = "/dev/nvme0"
+)
@@ -0,0 +1,184 @@
+package sanitize
+
+import (
+	"context"
+	"fmt"
+	"github.com/sungup/go-nvmecli/pkg/nvme"
+	"github.com/sungup/go-nvmecli/pkg/nvme/getlog"
+	"github.com/sungup/go-nvmecli/pkg/nvme/identify"
+	"os"
+	"time"
+)
+
+// action is the Sanitize Action (SANACT) field of the Sanitize command.
+type action uint8
+
+const (
+	ExitFailure = action(0x1) // exit the failure mode after the failed sanitize operation
+	BlockErase  = action(0x2)
+	Overwrite   = action(0x3)
+	CryptoErase = action(0x4)
+
+	// SANICAP bits which indicate the sanitize operations supported by the controller.
+	sanicapCES = uint32(1 << 0)  // Crypto Erase Support
+	sanicapBES = uint32(1 << 1)  // Block Erase Support
+	sanicapOWS = uint32(1 << 2)  // Overwrite Support
+	sanicapNDI = uint32(1 << 29) // No-Deallocate Inhibited
+
+	// maxOverwritePasses is the maximum Overwrite Pass Count, and it is encoded as 0h.
+	maxOverwritePasses = 16
+
+	// pollInterval is the interval to retrieve the Sanitize Status log while waiting.
+	pollInterval = time.Second
+)
+
+// String returns the name of the sanitize action.
+func (a action) String() string {
+	switch a {
+	case ExitFailure:
+		return "Exit Failure Mode"
+	case BlockErase:
+		return "Block Erase"
+	case Overwrite:
+		return "Overwrite"
+	case CryptoErase:
+		return "Crypto Erase"
+	default:
+		return fmt.Sprintf("Reserved (%Xh)", uint8(a))
+	}
+}
+
+// Options is the optional fields of the Sanitize command.
+type Options struct {
+	AUSE    bool   // Allow Unrestricted Sanitize Exit
+	OWPASS  uint8  // Overwrite Pass Count in [1, 16], only for the overwrite action
+	OIPBP   bool   // Overwrite Invert Pattern Between Passes, only for the overwrite action
+	NDAS    bool   // No-Deallocate After Sanitize
+	Pattern uint32 // Overwrite Pattern, only for the overwrite action
+}
+
+// newSanitizeCmd generates an AdminCmd structure to start the sanitize operation. The options
+// should be validated before calling this function.
+func newSanitizeCmd(act action, opts Options) *nvme.AdminCmd {
+	cdw10 := uint32(act) & 0x07
+
+	if opts.AUSE {
+		cdw10 |= 1 << 3
+	}
+
+	if act == Overwrite {
+		// Overwrite Pass Count is a 4bit field which 0h means 16 passes
+		cdw10 |= uint32(opts.OWPASS%maxOverwritePasses) << 4
+
+		if opts.OIPBP {
+			cdw10 |= 1 << 8
+		}
+	}
+
+	if opts.NDAS {
+		cdw10 |= 1 << 9
+	}
+
+	cmd := nvme.AdminCmd{
+		PassthruCmd: nvme.PassthruCmd{
+			OpCode: nvme.AdminSanitizeNVM,
+			CDW10:  cdw10,
+		},
+		TimeoutMSec: 0,
+		Result:      0,
+	}
+
+	if act == Overwrite {
+		cmd.CDW11 = opts.Pattern
+	}
+
+	return &cmd
+}
+
+// validate checks the action and the options with the SANICAP of the controller.
+func validate(sanicap uint32, act action, opts Options) error {
+	switch act {
+	case ExitFailure:
+		// exit failure mode is always allowed
+	case BlockErase:
+		if sanicap&sanicapBES == 0 {
+			return fmt.Errorf("block erase sanitize is not supported by the controller")
+		}
+	case CryptoErase:
+		if sanicap&sanicapCES == 0 {
+			return fmt.Errorf("crypto erase sanitize is not supported by the controller")
+		}
+	case Overwrite:
+		if sanicap&sanicapOWS == 0 {
+			return fmt.Errorf("overwrite sanitize is not supported by the controller")
+		} else if opts.OWPASS == 0 || opts.OWPASS > maxOverwritePasses {
+			return fmt.Errorf("invalid overwrite pass count: %d", opts.OWPASS)
+		}
+	default:
+		return fmt.Errorf("invalid sanitize action: %v", act)
+	}
+
+	if act != Overwrite && (opts.OIPBP || opts.Pattern != 0) {
+		return fmt.Errorf("overwrite options are not allowed for %v", act)
+	}
+
+	if opts.NDAS && sanicap&sanicapNDI != 0 {
+		return fmt.Errorf("no-deallocate after sanitize is inhibited by the controller")
+	}
+
+	return nil
+}
+
+// Sanitize starts the sanitize operation of the NVM subsystem. The action and the options are
+// validated with the SANICAP of the controller before issuing the command. The command is completed
+// right after the sanitize operation has been started, so host software should poll the Sanitize
+// Status log or call WaitSanitize to get the result.
+func Sanitize(file *os.File, act action, opts Options) error {
+	idCtrl := identify.CtrlIdentify{}
+	if err := identify.GetCtrlIdentify(file, &idCtrl); err != nil {
+		return err
+	} else if idCtrl.SNICAP&(sanicapCES|sanicapBES|sanicapOWS) == 0 {
+		return fmt.Errorf("sanitize is not supported by the controller")
+	}
+
+	if err := validate(idCtrl.SNICAP, act, opts); err != nil {
+		return err
+	}
+
+	return nvme.IOCtlAdminCmd(file, newSanitizeCmd(act, opts))
+}
+
+// WaitSanitize polls the Sanitize Status log until the sanitize operation in progress has been
+// completed and returns the last retrieved log. The progress callback is called with every
+// retrieved log if it is not nil. If the context is done before the completion, WaitSanitize
+// returns the context error, and the sanitize operation continues in the controller.
+func WaitSanitize(ctx context.Context, file *os.File, progress func(log *getlog.SanitizeStatus)) (*getlog.SanitizeStatus, error) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		// the sanitize completed event is cleared only by the last read
+		log := getlog.SanitizeStatus{}
+		if err := getlog.GetSanitizeStatus(file, true, &log); err != nil {
+			return nil, err
+		}
+
+		if progress != nil {
+			progress(&log)
+		}
+
+		if log.State() != getlog.SanitizeInProgress {
+			if err := getlog.GetSanitizeStatus(file, false, &log); err != nil {
+				return nil, err
+			}
+
+			return &log, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
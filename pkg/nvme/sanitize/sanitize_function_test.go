@@ -0,0 +1,70 @@
+package sanitize
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/sungup/go-nvmecli/pkg/nvme"
+	"testing"
+)
+
+func TestAction_String(t *testing.T) {
+	a := assert.New(t)
+
+	a.Equal("Exit Failure Mode", ExitFailure.String())
+	a.Equal("Block Erase", BlockErase.String())
+	a.Equal("Overwrite", Overwrite.String())
+	a.Equal("Crypto Erase", CryptoErase.String())
+	a.Equal("Reserved (0h)", action(0).String())
+}
+
+func TestNewSanitizeCmd(t *testing.T) {
+	a := assert.New(t)
+
+	tested := newSanitizeCmd(BlockErase, Options{AUSE: true, NDAS: true})
+	a.Equal(nvme.AdminSanitizeNVM, tested.OpCode)
+	a.Zero(tested.NSId)
+	a.Equal(uint32(1<<9|1<<3|0x2), tested.CDW10)
+	a.Zero(tested.CDW11)
+	a.Zero(tested.DataLength)
+
+	// overwrite options are applied only for the overwrite action
+	opts := Options{OWPASS: 3, OIPBP: true, Pattern: 0xA5A5A5A5}
+
+	tested = newSanitizeCmd(Overwrite, opts)
+	a.Equal(uint32(1<<8|3<<4|0x3), tested.CDW10)
+	a.Equal(uint32(0xA5A5A5A5), tested.CDW11)
+
+	tested = newSanitizeCmd(CryptoErase, opts)
+	a.Equal(uint32(0x4), tested.CDW10)
+	a.Zero(tested.CDW11)
+
+	// 16 passes are encoded as 0h
+	tested = newSanitizeCmd(Overwrite, Options{OWPASS: 16})
+	a.Equal(uint32(0x3), tested.CDW10)
+}
+
+func TestValidate(t *testing.T) {
+	a := assert.New(t)
+
+	all := sanicapCES | sanicapBES | sanicapOWS
+
+	a.NoError(validate(all, BlockErase, Options{AUSE: true, NDAS: true}))
+	a.NoError(validate(all, CryptoErase, Options{}))
+	a.NoError(validate(all, Overwrite, Options{OWPASS: 1, OIPBP: true, Pattern: 0xFF}))
+	a.NoError(validate(all, Overwrite, Options{OWPASS: 16}))
+	a.NoError(validate(0, ExitFailure, Options{}))
+
+	// unsupported actions
+	a.Error(validate(all&^sanicapBES, BlockErase, Options{}))
+	a.Error(validate(all&^sanicapCES, CryptoErase, Options{}))
+	a.Error(validate(all&^sanicapOWS, Overwrite, Options{OWPASS: 1}))
+	a.Error(validate(all, action(0), Options{}))
+	a.Error(validate(all, action(5), Options{}))
+
+	// invalid options
+	a.Error(validate(all, Overwrite, Options{}))
+	a.Error(validate(all, Overwrite, Options{OWPASS: 17}))
+	a.Error(validate(all, BlockErase, Options{OIPBP: true}))
+	a.Error(validate(all, CryptoErase, Options{Pattern: 0xFF}))
+	a.Error(validate(all|sanicapNDI, BlockErase, Options{NDAS: true}))
+	a.NoError(validate(all|sanicapNDI, BlockErase, Options{}))
+}
@@ -0,0 +1,45 @@
+// +build with_phys_device
+
+package sanitize
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/sungup/go-nvmecli/pkg/nvme/getlog"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestSanitize(t *testing.T) {
+	// TODO re-verify this test code using the sanitize support NVMe device
+	// The sanitize operation erases all user data in the NVM subsystem, so this test should run
+	// only on the device prepared for it.
+	/*
+		a := assert.New(t)
+
+		dev, _ := os.Open(targetDevice)
+
+		a.NoError(Sanitize(dev, BlockErase, Options{}))
+
+		tested, err := WaitSanitize(context.Background(), dev, nil)
+		a.NoError(err)
+		a.Equal(getlog.SanitizeCompleted, tested.State())
+	*/
+}
+
+func TestWaitSanitize(t *testing.T) {
+	a := assert.New(t)
+
+	dev, _ := os.Open(targetDevice)
+
+	// without a sanitize operation in progress, WaitSanitize returns the status at once
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	progressed := 0
+	if tested, err := WaitSanitize(ctx, dev, func(_ *getlog.SanitizeStatus) { progressed++ }); err == nil {
+		a.Equal(1, progressed)
+		a.NotNil(tested)
+	}
+}
@@ -0,0 +1,5 @@
+package selftest
+
+const (
+	expectedNSId = 1
+)
@@ -0,0 +1,109 @@
+package selftest
+
+import (
+	"context"
+	"fmt"
+	"github.com/sungup/go-nvmecli/pkg/nvme"
+	"github.com/sungup/go-nvmecli/pkg/nvme/getlog"
+	"github.com/sungup/go-nvmecli/pkg/nvme/identify"
+	"os"
+	"time"
+)
+
+// stCode is the Self-test Code (STC) field of the Device Self-test command.
+type stCode uint8
+
+const (
+	Short          = stCode(0x1)
+	Extended       = stCode(0x2)
+	VendorSpecific = stCode(0xE)
+	abort          = stCode(0xF)
+
+	// oacsSelfTest is the OACS bit which indicates the Device Self-test command is supported.
+	oacsSelfTest = uint16(1 << 4)
+
+	// pollInterval is the interval to retrieve the Device Self-test log while waiting.
+	pollInterval = time.Second
+)
+
+// newSelfTestCmd generates an AdminCmd structure to start or abort the device self-test. If nsid is
+// 0h, the self-test doesn't include any namespace. If nsid is FFFFFFFFh, all active namespaces are
+// included in the self-test.
+func newSelfTestCmd(nsid uint32, code stCode) *nvme.AdminCmd {
+	return &nvme.AdminCmd{
+		PassthruCmd: nvme.PassthruCmd{
+			OpCode: nvme.AdminDevSelfTest,
+			NSId:   nsid,
+			CDW10:  uint32(code) & 0x0F,
+		},
+		TimeoutMSec: 0,
+		Result:      0,
+	}
+}
+
+// checkSupport checks the controller supports the Device Self-test command.
+func checkSupport(file *os.File) error {
+	idCtrl := identify.CtrlIdentify{}
+
+	if err := identify.GetCtrlIdentify(file, &idCtrl); err != nil {
+		return err
+	} else if idCtrl.OACS&oacsSelfTest == 0 {
+		return fmt.Errorf("device self-test is not supported by the controller")
+	}
+
+	return nil
+}
+
+// StartSelfTest starts the device self-test operation on the namespace. The command is completed
+// right after the self-test has been started, so host software should poll the Device Self-test log
+// or call WaitSelfTest to get the result.
+func StartSelfTest(file *os.File, nsid uint32, code stCode) error {
+	if code != Short && code != Extended && code != VendorSpecific {
+		return fmt.Errorf("invalid self-test code: %Xh", uint8(code))
+	}
+
+	if err := checkSupport(file); err != nil {
+		return err
+	}
+
+	return nvme.IOCtlAdminCmd(file, newSelfTestCmd(nsid, code))
+}
+
+// AbortSelfTest aborts the device self-test operation in progress.
+func AbortSelfTest(file *os.File) error {
+	if err := checkSupport(file); err != nil {
+		return err
+	}
+
+	return nvme.IOCtlAdminCmd(file, newSelfTestCmd(0, abort))
+}
+
+// WaitSelfTest polls the Device Self-test log until the self-test operation in progress has been
+// completed and returns the last retrieved log. The progress callback is called with every
+// retrieved log if it is not nil. If the context is done before the completion, WaitSelfTest
+// returns the context error without aborting the self-test.
+func WaitSelfTest(ctx context.Context, file *os.File, progress func(log *getlog.DevSelfTest)) (*getlog.DevSelfTest, error) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		log := getlog.DevSelfTest{}
+		if err := getlog.GetDevSelfTest(file, &log); err != nil {
+			return nil, err
+		}
+
+		if progress != nil {
+			progress(&log)
+		}
+
+		if log.Operation() == getlog.NoSelfTest {
+			return &log, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package selftest
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/sungup/go-nvmecli/pkg/nvme"
+	"testing"
+)
+
+func TestNewSelfTestCmd(t *testing.T) {
+	a := assert.New(t)
+
+	for _, code := range []stCode{Short, Extended, VendorSpecific, abort} {
+		tested := newSelfTestCmd(expectedNSId, code)
+
+		a.Equal(nvme.AdminDevSelfTest, tested.OpCode)
+		a.Equal(uint32(expectedNSId), tested.NSId)
+		a.Equal(uint32(code), tested.CDW10)
+		a.Zero(tested.DataLength)
+	}
+
+	// STC is a 4bit field
+	a.Equal(uint32(0x1), newSelfTestCmd(expectedNSId, stCode(0xF1)).CDW10)
+}
+
+func TestStartSelfTest_InvalidCode(t *testing.T) {
+	a := assert.New(t)
+
+	// invalid codes are rejected before issuing any command
+	for _, code := range []stCode{0x0, 0x3, abort} {
+		a.Error(StartSelfTest(nil, expectedNSId, code))
+	}
+}
@@ -0,0 +1,44 @@
+// +build with_phys_device
+
+package selftest
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/sungup/go-nvmecli/pkg/nvme/getlog"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestStartSelfTest(t *testing.T) {
+	a := assert.New(t)
+
+	dev, _ := os.Open(targetDevice)
+
+	a.NoError(StartSelfTest(dev, expectedNSId, Short))
+
+	// short self-test should be completed in 2 minutes
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Minute)
+	defer cancel()
+
+	progressed := 0
+	tested, err := WaitSelfTest(ctx, dev, func(log *getlog.DevSelfTest) { progressed++ })
+	a.NoError(err)
+	a.NotZero(progressed)
+	a.Equal(getlog.NoSelfTest, tested.Operation())
+	a.Equal(getlog.ShortSelfTest, tested.Latest().Code())
+}
+
+func TestAbortSelfTest(t *testing.T) {
+	a := assert.New(t)
+
+	dev, _ := os.Open(targetDevice)
+
+	a.NoError(StartSelfTest(dev, expectedNSId, Extended))
+	a.NoError(AbortSelfTest(dev))
+
+	tested, err := WaitSelfTest(context.Background(), dev, nil)
+	a.NoError(err)
+	a.Equal(getlog.SelfTestAbortedByCmd, tested.Latest().Result())
+}
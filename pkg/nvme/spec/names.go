@@ -0,0 +1,192 @@
+package spec
+
+import "fmt"
+
+// ---------------------------------- //
+// Opcode, Log Page and Feature Names //
+// ---------------------------------- //
+
+// adminOpcodes is the names of the admin commands.
+var adminOpcodes = map[uint8]string{
+	0x00: "Delete I/O Submission Queue",
+	0x01: "Create I/O Submission Queue",
+	0x02: "Get Log Page",
+	0x04: "Delete I/O Completion Queue",
+	0x05: "Create I/O Completion Queue",
+	0x06: "Identify",
+	0x08: "Abort",
+	0x09: "Set Features",
+	0x0A: "Get Features",
+	0x0C: "Asynchronous Event Request",
+	0x0D: "Namespace Management",
+	0x10: "Firmware Commit",
+	0x11: "Firmware Image Download",
+	0x14: "Device Self-test",
+	0x15: "Namespace Attachment",
+	0x18: "Keep Alive",
+	0x19: "Directive Send",
+	0x1A: "Directive Receive",
+	0x1C: "Virtualization Management",
+	0x1D: "NVMe-MI Send",
+	0x1E: "NVMe-MI Receive",
+	0x20: "Capacity Management",
+	0x24: "Lockdown",
+	0x7C: "Doorbell Buffer Config",
+	0x7F: "Fabrics Commands",
+	0x80: "Format NVM",
+	0x81: "Security Send",
+	0x82: "Security Receive",
+	0x84: "Sanitize",
+	0x86: "Get LBA Status",
+}
+
+// ioOpcodes is the names of the NVM command set I/O commands.
+var ioOpcodes = map[uint8]string{
+	0x00: "Flush",
+	0x01: "Write",
+	0x02: "Read",
+	0x04: "Write Uncorrectable",
+	0x05: "Compare",
+	0x08: "Write Zeroes",
+	0x09: "Dataset Management",
+	0x0C: "Verify",
+	0x0D: "Reservation Register",
+	0x0E: "Reservation Report",
+	0x11: "Reservation Acquire",
+	0x15: "Reservation Release",
+	0x19: "Copy",
+}
+
+// opcodeName returns the name of the opcode in the table. The opcodes from C0h are vendor specific.
+func opcodeName(table map[uint8]string, op uint8) string {
+	if name, ok := table[op]; ok {
+		return name
+	} else if op >= 0xC0 {
+		return fmt.Sprintf("Vendor Specific (%02Xh)", op)
+	} else {
+		return fmt.Sprintf("Unknown (%02Xh)", op)
+	}
+}
+
+// AdminOpcodeName returns the name of the admin command opcode.
+func AdminOpcodeName(op uint8) string {
+	return opcodeName(adminOpcodes, op)
+}
+
+// IOOpcodeName returns the name of the NVM command set I/O command opcode.
+func IOOpcodeName(op uint8) string {
+	return opcodeName(ioOpcodes, op)
+}
+
+// LogID is a Log Page Identifier.
+type LogID uint8
+
+// logPages is the names of the log pages.
+var logPages = map[LogID]string{
+	0x01: "Error Information",
+	0x02: "SMART / Health Information",
+	0x03: "Firmware Slot Information",
+	0x04: "Changed Namespace List",
+	0x05: "Commands Supported and Effects",
+	0x06: "Device Self-test",
+	0x07: "Telemetry Host-Initiated",
+	0x08: "Telemetry Controller-Initiated",
+	0x09: "Endurance Group Information",
+	0x0A: "Predictable Latency Per NVM Set",
+	0x0B: "Predictable Latency Event Aggregate",
+	0x0C: "Asymmetric Namespace Access",
+	0x0D: "Persistent Event Log",
+	0x0E: "LBA Status Information",
+	0x0F: "Endurance Group Event Aggregate",
+	0x10: "Media Unit Status",
+	0x11: "Supported Capacity Configuration List",
+	0x12: "Feature Identifiers Supported and Effects",
+	0x13: "NVMe-MI Commands Supported and Effects",
+	0x14: "Command and Feature Lockdown",
+	0x15: "Boot Partition",
+	0x16: "Rotational Media Information",
+	0x70: "Discovery",
+	0x80: "Reservation Notification",
+	0x81: "Sanitize Status",
+}
+
+// String returns the name of the log page.
+func (l LogID) String() string {
+	if name, ok := logPages[l]; ok {
+		return name
+	} else if l >= 0xC0 {
+		return fmt.Sprintf("Vendor Specific (%02Xh)", uint8(l))
+	} else {
+		return fmt.Sprintf("Unknown (%02Xh)", uint8(l))
+	}
+}
+
+// FeatureID is a Feature Identifier.
+type FeatureID uint8
+
+// features is the names of the features.
+var features = map[FeatureID]string{
+	0x01: "Arbitration",
+	0x02: "Power Management",
+	0x03: "LBA Range Type",
+	0x04: "Temperature Threshold",
+	0x05: "Error Recovery",
+	0x06: "Volatile Write Cache",
+	0x07: "Number of Queues",
+	0x08: "Interrupt Coalescing",
+	0x09: "Interrupt Vector Configuration",
+	0x0A: "Write Atomicity Normal",
+	0x0B: "Asynchronous Event Configuration",
+	0x0C: "Autonomous Power State Transition",
+	0x0D: "Host Memory Buffer",
+	0x0E: "Timestamp",
+	0x0F: "Keep Alive Timer",
+	0x10: "Host Controlled Thermal Management",
+	0x11: "Non-Operational Power State Config",
+	0x12: "Read Recovery Level Config",
+	0x13: "Predictable Latency Mode Config",
+	0x14: "Predictable Latency Mode Window",
+	0x15: "LBA Status Information Report Interval",
+	0x16: "Host Behavior Support",
+	0x17: "Sanitize Config",
+	0x18: "Endurance Group Event Configuration",
+	0x19: "I/O Command Set Profile",
+	0x1A: "Spinup Control",
+	0x7D: "Enhanced Controller Metadata",
+	0x7E: "Controller Metadata",
+	0x7F: "Namespace Metadata",
+	0x80: "Software Progress Marker",
+	0x81: "Host Identifier",
+	0x82: "Reservation Notification Mask",
+	0x83: "Reservation Persistence",
+	0x84: "Namespace Write Protection Config",
+}
+
+// String returns the name of the feature.
+func (f FeatureID) String() string {
+	if name, ok := features[f]; ok {
+		return name
+	} else if f >= 0xC0 {
+		return fmt.Sprintf("Vendor Specific (%02Xh)", uint8(f))
+	} else {
+		return fmt.Sprintf("Unknown (%02Xh)", uint8(f))
+	}
+}
+
+// transports is the names of the transport types.
+var transports = map[uint8]string{
+	0x00: "Not Specified",
+	0x01: "RDMA",
+	0x02: "Fibre Channel",
+	0x03: "TCP",
+	0xFE: "Intra Host",
+}
+
+// TransportName returns the name of the transport type.
+func TransportName(t uint8) string {
+	if name, ok := transports[t]; ok {
+		return name
+	}
+
+	return fmt.Sprintf("Unknown (%02Xh)", t)
+}
@@ -0,0 +1,48 @@
+package spec
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestAdminOpcodeName(t *testing.T) {
+	a := assert.New(t)
+
+	a.Equal("Get Log Page", AdminOpcodeName(0x02))
+	a.Equal("Firmware Image Download", AdminOpcodeName(0x11))
+	a.Equal("Vendor Specific (C0h)", AdminOpcodeName(0xC0))
+	a.Equal("Unknown (03h)", AdminOpcodeName(0x03))
+}
+
+func TestIOOpcodeName(t *testing.T) {
+	a := assert.New(t)
+
+	a.Equal("Write", IOOpcodeName(0x01))
+	a.Equal("Read", IOOpcodeName(0x02))
+	a.Equal("Vendor Specific (C0h)", IOOpcodeName(0xC0))
+	a.Equal("Unknown (03h)", IOOpcodeName(0x03))
+}
+
+func TestLogID_String(t *testing.T) {
+	a := assert.New(t)
+
+	a.Equal("SMART / Health Information", LogID(0x02).String())
+	a.Equal("Sanitize Status", LogID(0x81).String())
+	a.Equal("Vendor Specific (C0h)", LogID(0xC0).String())
+	a.Equal("Unknown (20h)", LogID(0x20).String())
+}
+
+func TestFeatureID_String(t *testing.T) {
+	a := assert.New(t)
+
+	a.Equal("Host Behavior Support", FeatureID(0x16).String())
+	a.Equal("Vendor Specific (C5h)", FeatureID(0xC5).String())
+	a.Equal("Unknown (00h)", FeatureID(0x00).String())
+}
+
+func TestTransportName(t *testing.T) {
+	a := assert.New(t)
+
+	a.Equal("RDMA", TransportName(0x01))
+	a.Equal("Unknown (04h)", TransportName(0x04))
+}
@@ -0,0 +1,201 @@
+package spec
+
+import "fmt"
+
+// ----------------------------- //
+// Completion Queue Status Codes //
+// ----------------------------- //
+
+// Status Code Type values of the completion queue entry's status field. The values are same with
+// the nvme package's SCT constants, and redefined here to keep this package dependency free.
+const (
+	sctGeneric         = uint8(0x0)
+	sctCommandSpecific = uint8(0x1)
+	sctMediaError      = uint8(0x2)
+	sctPathRelated     = uint8(0x3)
+	sctVendorSpecific  = uint8(0x7)
+)
+
+// Status is a named status code of the completion queue entry.
+type Status struct {
+	SCT         uint8
+	SC          uint8
+	Name        string
+	Description string
+}
+
+// String returns the status name with the status code type and the status code like "Unrecovered
+// Read Error (SCT 2h/SC 81h)".
+func (s Status) String() string {
+	return fmt.Sprintf("%s (SCT %Xh/SC %02Xh)", s.Name, s.SCT, s.SC)
+}
+
+// statusEntry is a name and a description of a status code.
+type statusEntry struct {
+	name string
+	desc string
+}
+
+// genericStatus is the Generic Command Status values (SCT 0h).
+var genericStatus = map[uint8]statusEntry{
+	0x00: {"Successful Completion", "The command completed without error."},
+	0x01: {"Invalid Command Opcode", "A reserved or unsupported opcode was issued."},
+	0x02: {"Invalid Field in Command", "A reserved or unsupported value was set in a command field."},
+	0x03: {"Command ID Conflict", "The command identifier is already in use."},
+	0x04: {"Data Transfer Error", "Transferring the data or metadata failed."},
+	0x05: {"Commands Aborted due to Power Loss Notification", "The command was aborted by a power loss notification."},
+	0x06: {"Internal Error", "The command was not completed by an internal controller error."},
+	0x07: {"Command Abort Requested", "The command was aborted by an Abort command."},
+	0x08: {"Command Aborted due to SQ Deletion", "The command was aborted by the submission queue deletion."},
+	0x09: {"Command Aborted due to Failed Fused Command", "The other command of the fused operation failed."},
+	0x0A: {"Command Aborted due to Missing Fused Command", "The other command of the fused operation was missing."},
+	0x0B: {"Invalid Namespace or Format", "The namespace or the format of the namespace is invalid."},
+	0x0C: {"Command Sequence Error", "The command was a protocol violation in a multi-command sequence."},
+	0x0D: {"Invalid SGL Segment Descriptor", "The SGL segment descriptor or the last SGL segment descriptor is invalid."},
+	0x0E: {"Invalid Number of SGL Descriptors", "The number of SGL descriptors is invalid."},
+	0x0F: {"Data SGL Length Invalid", "The length of the data SGL is too short or too long."},
+	0x10: {"Metadata SGL Length Invalid", "The length of the metadata SGL is too short or too long."},
+	0x11: {"SGL Descriptor Type Invalid", "The SGL descriptor type is not supported."},
+	0x12: {"Invalid Use of Controller Memory Buffer", "The controller memory buffer address is used in an invalid way."},
+	0x13: {"PRP Offset Invalid", "The PRP entry offset is invalid."},
+	0x14: {"Atomic Write Unit Exceeded", "The command exceeds the atomic write unit size."},
+	0x15: {"Operation Denied", "The command is not allowed by the access rights."},
+	0x16: {"SGL Offset Invalid", "The SGL offset is invalid."},
+	0x18: {"Host Identifier Inconsistent Format", "The host identifier format differs from the registered one."},
+	0x19: {"Keep Alive Timer Expired", "The keep alive timer expired."},
+	0x1A: {"Keep Alive Timeout Invalid", "The keep alive timeout value is invalid."},
+	0x1B: {"Command Aborted due to Preempt and Abort", "The command was aborted by a Reservation Acquire with preempt and abort."},
+	0x1C: {"Sanitize Failed", "The latest sanitize operation failed and the media is not recovered."},
+	0x1D: {"Sanitize In Progress", "The command is prohibited while a sanitize operation is in progress."},
+	0x1E: {"SGL Data Block Granularity Invalid", "The SGL data block descriptor is not aligned to the granularity."},
+	0x1F: {"Command Not Supported for Queue in CMB", "The command is not supported for the queue in the controller memory buffer."},
+	0x20: {"Namespace is Write Protected", "The command is prohibited by the namespace write protection."},
+	0x21: {"Command Interrupted", "The command was interrupted and may be retried."},
+	0x22: {"Transient Transport Error", "A transient transport error was detected."},
+	0x23: {"Command Prohibited by Command and Feature Lockdown", "The command is prohibited by the lockdown."},
+	0x24: {"Admin Command Media Not Ready", "The admin command requires the media which is not ready."},
+	0x80: {"LBA Out of Range", "The command references an LBA that exceeds the size of the namespace."},
+	0x81: {"Capacity Exceeded", "The command would exceed the capacity of the namespace."},
+	0x82: {"Namespace Not Ready", "The namespace is not ready to be accessed."},
+	0x83: {"Reservation Conflict", "The command was aborted by a reservation conflict."},
+	0x84: {"Format In Progress", "The command is prohibited while a format operation is in progress."},
+}
+
+// commandSpecificStatus is the Command Specific Status values (SCT 1h). The values from 80h are
+// I/O command set specific.
+var commandSpecificStatus = map[uint8]statusEntry{
+	0x00: {"Completion Queue Invalid", "The completion queue identifier is invalid."},
+	0x01: {"Invalid Queue Identifier", "The queue identifier is invalid."},
+	0x02: {"Invalid Queue Size", "The queue size is invalid."},
+	0x03: {"Abort Command Limit Exceeded", "The number of outstanding Abort commands exceeds the limit."},
+	0x05: {"Asynchronous Event Request Limit Exceeded", "The number of outstanding Asynchronous Event Requests exceeds the limit."},
+	0x06: {"Invalid Firmware Slot", "The firmware slot is invalid or read only."},
+	0x07: {"Invalid Firmware Image", "The firmware image is invalid."},
+	0x08: {"Invalid Interrupt Vector", "The interrupt vector is invalid."},
+	0x09: {"Invalid Log Page", "The log page is invalid."},
+	0x0A: {"Invalid Format", "The LBA format is invalid."},
+	0x0B: {"Firmware Activation Requires Conventional Reset", "The firmware is activated at the next conventional reset."},
+	0x0C: {"Invalid Queue Deletion", "The queue can't be deleted."},
+	0x0D: {"Feature Identifier Not Saveable", "The feature doesn't support the save."},
+	0x0E: {"Feature Not Changeable", "The feature is not changeable."},
+	0x0F: {"Feature Not Namespace Specific", "The feature is not namespace specific."},
+	0x10: {"Firmware Activation Requires NVM Subsystem Reset", "The firmware is activated at the next NVM subsystem reset."},
+	0x11: {"Firmware Activation Requires Controller Level Reset", "The firmware is activated at the next controller level reset."},
+	0x12: {"Firmware Activation Requires Maximum Time Violation", "The firmware activation would exceed the maximum time."},
+	0x13: {"Firmware Activation Prohibited", "The firmware activation is prohibited."},
+	0x14: {"Overlapping Range", "The firmware image or boot partition range overlaps."},
+	0x15: {"Namespace Insufficient Capacity", "The NVM capacity is not enough for the namespace."},
+	0x16: {"Namespace Identifier Unavailable", "The number of namespaces reaches the limit."},
+	0x18: {"Namespace Already Attached", "The namespace is already attached to the controller."},
+	0x19: {"Namespace Is Private", "The namespace is private and can't be attached to the other controllers."},
+	0x1A: {"Namespace Not Attached", "The namespace is not attached to the controller."},
+	0x1B: {"Thin Provisioning Not Supported", "Thin provisioning is not supported."},
+	0x1C: {"Controller List Invalid", "The controller list is invalid."},
+	0x1D: {"Device Self-test In Progress", "A device self-test operation is already in progress."},
+	0x1E: {"Boot Partition Write Prohibited", "Writing the boot partition is prohibited."},
+	0x1F: {"Invalid Controller Identifier", "The controller identifier is invalid."},
+	0x20: {"Invalid Secondary Controller State", "The secondary controller state is invalid."},
+	0x21: {"Invalid Number of Controller Resources", "The number of controller resources is invalid."},
+	0x22: {"Invalid Resource Identifier", "The resource identifier is invalid."},
+	0x23: {"Sanitize Prohibited While Persistent Memory Region is Enabled", "Sanitize is prohibited while the PMR is enabled."},
+	0x24: {"ANA Group Identifier Invalid", "The ANA group identifier is invalid."},
+	0x25: {"ANA Attach Failed", "Attaching the namespace failed by the ANA state."},
+	0x26: {"Insufficient Capacity", "The capacity is not enough for the operation."},
+	0x27: {"Namespace Attachment Limit Exceeded", "The number of namespace attachments exceeds the limit."},
+	0x28: {"Prohibition of Command Execution Not Supported", "The lockdown of the command is not supported."},
+	0x29: {"I/O Command Set Not Supported", "The I/O command set is not supported."},
+	0x2A: {"I/O Command Set Not Enabled", "The I/O command set is not enabled."},
+	0x2B: {"I/O Command Set Combination Rejected", "The I/O command set combination is rejected."},
+	0x2C: {"Invalid I/O Command Set", "The I/O command set is invalid."},
+	0x2D: {"Identifier Unavailable", "The identifier is unavailable."},
+	0x80: {"Conflicting Attributes", "The attributes of the command conflict."},
+	0x81: {"Invalid Protection Information", "The protection information is invalid."},
+	0x82: {"Attempted Write to Read Only Range", "The LBA range is read only."},
+	0x83: {"Command Size Limit Exceeded", "The command size exceeds the limit."},
+	0xB8: {"Zone Boundary Error", "The command crosses the zone boundary."},
+	0xB9: {"Zone Is Full", "The zone is full."},
+	0xBA: {"Zone Is Read Only", "The zone is read only."},
+	0xBB: {"Zone Is Offline", "The zone is offline."},
+	0xBC: {"Zone Invalid Write", "The write doesn't start at the write pointer of the zone."},
+	0xBD: {"Too Many Active Zones", "The number of active zones exceeds the limit."},
+	0xBE: {"Too Many Open Zones", "The number of open zones exceeds the limit."},
+	0xBF: {"Invalid Zone State Transition", "The zone state transition is invalid."},
+}
+
+// mediaErrorStatus is the Media and Data Integrity Errors values (SCT 2h).
+var mediaErrorStatus = map[uint8]statusEntry{
+	0x80: {"Write Fault", "The write data could not be committed to the media."},
+	0x81: {"Unrecovered Read Error", "The read data could not be recovered from the media."},
+	0x82: {"End-to-end Guard Check Error", "The end-to-end guard check failed."},
+	0x83: {"End-to-end Application Tag Check Error", "The end-to-end application tag check failed."},
+	0x84: {"End-to-end Reference Tag Check Error", "The end-to-end reference tag check failed."},
+	0x85: {"Compare Failure", "The Compare command found a miscompare."},
+	0x86: {"Access Denied", "The access to the namespace or the LBA range is denied."},
+	0x87: {"Deallocated or Unwritten Logical Block", "The command read a deallocated or unwritten logical block."},
+	0x88: {"End-to-end Storage Tag Check Error", "The end-to-end storage tag check failed."},
+}
+
+// pathRelatedStatus is the Path Related Status values (SCT 3h).
+var pathRelatedStatus = map[uint8]statusEntry{
+	0x00: {"Internal Path Error", "The command was not completed by an error on the path."},
+	0x01: {"Asymmetric Access Persistent Loss", "The namespace is in the ANA Persistent Loss state on the path."},
+	0x02: {"Asymmetric Access Inaccessible", "The namespace is in the ANA Inaccessible state on the path."},
+	0x03: {"Asymmetric Access Transition", "The namespace is in the ANA Change state on the path."},
+	0x60: {"Controller Pathing Error", "The controller detected a pathing error."},
+	0x70: {"Host Pathing Error", "The host detected a pathing error."},
+	0x71: {"Command Aborted By Host", "The host aborted the command."},
+}
+
+// statusTables is the status code tables of each status code type.
+var statusTables = map[uint8]map[uint8]statusEntry{
+	sctGeneric:         genericStatus,
+	sctCommandSpecific: commandSpecificStatus,
+	sctMediaError:      mediaErrorStatus,
+	sctPathRelated:     pathRelatedStatus,
+}
+
+// LookupStatus returns the named status of the status code type and the status code. If the
+// status code is not defined, LookupStatus returns false with the reserved or vendor specific
+// status.
+func LookupStatus(sct, sc uint8) (Status, bool) {
+	status := Status{SCT: sct, SC: sc}
+
+	if entry, ok := statusTables[sct][sc]; ok {
+		status.Name, status.Description = entry.name, entry.desc
+		return status, true
+	}
+
+	if sct == sctVendorSpecific || sc >= 0xC0 {
+		status.Name = "Vendor Specific"
+	} else {
+		status.Name = "Reserved"
+	}
+
+	return status, false
+}
+
+// StatusString returns the status name with the status code type and the status code.
+func StatusString(sct, sc uint8) string {
+	status, _ := LookupStatus(sct, sc)
+	return status.String()
+}
@@ -0,0 +1,46 @@
+package spec
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestLookupStatus(t *testing.T) {
+	a := assert.New(t)
+
+	tested, ok := LookupStatus(sctMediaError, 0x81)
+	a.True(ok)
+	a.Equal("Unrecovered Read Error", tested.Name)
+	a.NotEmpty(tested.Description)
+	a.Equal("Unrecovered Read Error (SCT 2h/SC 81h)", tested.String())
+
+	for sct, table := range statusTables {
+		for sc, entry := range table {
+			tested, ok = LookupStatus(sct, sc)
+			a.True(ok)
+			a.Equal(entry.name, tested.Name)
+			a.NotEmpty(tested.Description)
+		}
+	}
+
+	tested, ok = LookupStatus(sctGeneric, 0x17)
+	a.False(ok)
+	a.Equal("Reserved", tested.Name)
+
+	tested, ok = LookupStatus(sctGeneric, 0xC0)
+	a.False(ok)
+	a.Equal("Vendor Specific", tested.Name)
+
+	tested, ok = LookupStatus(sctVendorSpecific, 0x01)
+	a.False(ok)
+	a.Equal("Vendor Specific", tested.Name)
+}
+
+func TestStatusString(t *testing.T) {
+	a := assert.New(t)
+
+	a.Equal("Invalid Field in Command (SCT 0h/SC 02h)", StatusString(sctGeneric, 0x02))
+	a.Equal("Namespace Not Attached (SCT 1h/SC 1Ah)", StatusString(sctCommandSpecific, 0x1A))
+	a.Equal("Asymmetric Access Inaccessible (SCT 3h/SC 02h)", StatusString(sctPathRelated, 0x02))
+	a.Equal("Reserved (SCT 4h/SC 00h)", StatusString(0x4, 0x00))
+}
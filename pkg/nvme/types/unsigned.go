@@ -1,5 +1,7 @@
 package types
 
+import "encoding/binary"
+
 // All data type in this package should use byte array because avoiding the byte alignment bugs.
 
 type Hex8 byte
@@ -15,3 +17,8 @@ type Uint64 [8]byte
 type Uint128 [2]Uint64
 
 func (u Uint8) Uint() uint64 { return uint64(u) }
+
+// NVMe data structures are in little endian format.
+func (u Uint16) Uint() uint64 { return uint64(binary.LittleEndian.Uint16(u[:])) }
+func (u Uint32) Uint() uint64 { return uint64(binary.LittleEndian.Uint32(u[:])) }
+func (u Uint64) Uint() uint64 { return binary.LittleEndian.Uint64(u[:]) }
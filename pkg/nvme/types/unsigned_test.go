@@ -1 +1,15 @@
 package types
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestUint_Uint(t *testing.T) {
+	a := assert.New(t)
+
+	a.Equal(uint64(0xAB), Uint8(0xAB).Uint())
+	a.Equal(uint64(0xABCD), Uint16{0xCD, 0xAB}.Uint())
+	a.Equal(uint64(0x89ABCDEF), Uint32{0xEF, 0xCD, 0xAB, 0x89}.Uint())
+	a.Equal(uint64(0x0123456789ABCDEF), Uint64{0xEF, 0xCD, 0xAB, 0x89, 0x67, 0x45, 0x23, 0x01}.Uint())
+}